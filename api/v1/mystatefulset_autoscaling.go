@@ -0,0 +1,100 @@
+package v1
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+const (
+	// DefaultAutoscalingTargetCPUUtilization is used when spec.autoscaling sets no target.
+	DefaultAutoscalingTargetCPUUtilization = 80
+
+	// DefaultScaleDownStabilizationWindowSeconds is used when
+	// spec.autoscaling.scaleDownStabilizationWindowSeconds is unset.
+	DefaultScaleDownStabilizationWindowSeconds = 300
+)
+
+// GetMinReplicas 返回自动扩缩容的副本数下限，未设置时为 1
+func (a *AutoscalingSpec) GetMinReplicas() int32 {
+	if a.MinReplicas == nil {
+		return 1
+	}
+	return *a.MinReplicas
+}
+
+// GetTargets 返回 CPU 和内存的目标利用率，0 表示不按该资源扩缩容；都未设置时 CPU 为 80%
+func (a *AutoscalingSpec) GetTargets() (cpu, memory int32) {
+	if a.TargetCPUUtilizationPercentage != nil {
+		cpu = *a.TargetCPUUtilizationPercentage
+	}
+	if a.TargetMemoryUtilizationPercentage != nil {
+		memory = *a.TargetMemoryUtilizationPercentage
+	}
+	if cpu == 0 && memory == 0 {
+		cpu = DefaultAutoscalingTargetCPUUtilization
+	}
+	return cpu, memory
+}
+
+// GetScaleUpWindow 返回扩容的稳定窗口，未设置时为 0
+func (a *AutoscalingSpec) GetScaleUpWindow() time.Duration {
+	if a.ScaleUpStabilizationWindowSeconds == nil {
+		return 0
+	}
+	return time.Duration(*a.ScaleUpStabilizationWindowSeconds) * time.Second
+}
+
+// GetScaleDownWindow 返回缩容的稳定窗口，未设置时为 300 秒
+func (a *AutoscalingSpec) GetScaleDownWindow() time.Duration {
+	if a.ScaleDownStabilizationWindowSeconds == nil {
+		return DefaultScaleDownStabilizationWindowSeconds * time.Second
+	}
+	return time.Duration(*a.ScaleDownStabilizationWindowSeconds) * time.Second
+}
+
+// validateAutoscaling 校验副本数范围，并拒绝与定时扩缩容同时使用
+func (r *MyStatefulset) validateAutoscaling() field.ErrorList {
+	var allErrs field.ErrorList
+	autoscaling := r.Spec.Autoscaling
+	if autoscaling == nil {
+		return allErrs
+	}
+	autoscalingPath := field.NewPath("spec").Child("autoscaling")
+
+	// 两者都会修改 spec.replicas，同时使用时结果取决于调谐顺序
+	if len(r.Spec.ScalingSchedule) > 0 {
+		allErrs = append(allErrs, field.Forbidden(autoscalingPath,
+			"cannot be used together with spec.scalingSchedule"))
+	}
+
+	if autoscaling.MaxReplicas < 1 {
+		allErrs = append(allErrs, field.Invalid(autoscalingPath.Child("maxReplicas"), autoscaling.MaxReplicas,
+			"must be greater than or equal to 1"))
+	}
+	if minReplicas := autoscaling.GetMinReplicas(); minReplicas < 0 {
+		allErrs = append(allErrs, field.Invalid(autoscalingPath.Child("minReplicas"), minReplicas,
+			"must be greater than or equal to 0"))
+	} else if minReplicas > autoscaling.MaxReplicas {
+		allErrs = append(allErrs, field.Invalid(autoscalingPath.Child("minReplicas"), minReplicas,
+			"must be less than or equal to maxReplicas"))
+	}
+
+	for _, target := range []struct {
+		name  string
+		value *int32
+		min   int32
+	}{
+		{"targetCPUUtilizationPercentage", autoscaling.TargetCPUUtilizationPercentage, 1},
+		{"targetMemoryUtilizationPercentage", autoscaling.TargetMemoryUtilizationPercentage, 1},
+		{"scaleUpStabilizationWindowSeconds", autoscaling.ScaleUpStabilizationWindowSeconds, 0},
+		{"scaleDownStabilizationWindowSeconds", autoscaling.ScaleDownStabilizationWindowSeconds, 0},
+	} {
+		if target.value != nil && *target.value < target.min {
+			allErrs = append(allErrs, field.Invalid(autoscalingPath.Child(target.name), *target.value,
+				fmt.Sprintf("must be greater than or equal to %d", target.min)))
+		}
+	}
+	return allErrs
+}
@@ -0,0 +1,86 @@
+package v1
+
+import (
+	"testing"
+
+	"k8s.io/utils/pointer"
+)
+
+func TestAutoscalingSpec_GetTargets(t *testing.T) {
+	tests := []struct {
+		name       string
+		spec       AutoscalingSpec
+		wantCPU    int32
+		wantMemory int32
+	}{
+		{name: "defaults to cpu", wantCPU: DefaultAutoscalingTargetCPUUtilization},
+		{name: "memory only", spec: AutoscalingSpec{TargetMemoryUtilizationPercentage: pointer.Int32(70)}, wantMemory: 70},
+		{
+			name:    "both",
+			spec:    AutoscalingSpec{TargetCPUUtilizationPercentage: pointer.Int32(60), TargetMemoryUtilizationPercentage: pointer.Int32(70)},
+			wantCPU: 60, wantMemory: 70,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cpu, memory := tt.spec.GetTargets()
+			if cpu != tt.wantCPU || memory != tt.wantMemory {
+				t.Errorf("GetTargets() = %d, %d, want %d, %d", cpu, memory, tt.wantCPU, tt.wantMemory)
+			}
+		})
+	}
+}
+
+func TestMyStatefulset_validateAutoscaling(t *testing.T) {
+	tests := []struct {
+		name        string
+		autoscaling *AutoscalingSpec
+		schedule    []ScheduledScaling
+		wantPaths   []string
+	}{
+		{name: "unset"},
+		{name: "valid", autoscaling: &AutoscalingSpec{MinReplicas: pointer.Int32(2), MaxReplicas: 5}},
+		{
+			name:        "min greater than max",
+			autoscaling: &AutoscalingSpec{MinReplicas: pointer.Int32(6), MaxReplicas: 5},
+			wantPaths:   []string{"spec.autoscaling.minReplicas"},
+		},
+		{
+			name: "invalid targets and windows",
+			autoscaling: &AutoscalingSpec{
+				MaxReplicas:                       0,
+				TargetCPUUtilizationPercentage:    pointer.Int32(0),
+				ScaleUpStabilizationWindowSeconds: pointer.Int32(-1),
+			},
+			wantPaths: []string{
+				"spec.autoscaling.maxReplicas",
+				"spec.autoscaling.minReplicas",
+				"spec.autoscaling.targetCPUUtilizationPercentage",
+				"spec.autoscaling.scaleUpStabilizationWindowSeconds",
+			},
+		},
+		{
+			name:        "combined with scaling schedule",
+			autoscaling: &AutoscalingSpec{MaxReplicas: 5},
+			schedule:    []ScheduledScaling{{Schedule: "@daily", Replicas: 1}},
+			wantPaths:   []string{"spec.autoscaling"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms := newPolicyTestMyStatefulset("default", 3)
+			ms.Spec.Autoscaling = tt.autoscaling
+			ms.Spec.ScalingSchedule = tt.schedule
+			errs := ms.validateAutoscaling()
+			if len(errs) != len(tt.wantPaths) {
+				t.Fatalf("validateAutoscaling() = %v, want %d errors", errs, len(tt.wantPaths))
+			}
+			for i, err := range errs {
+				if err.Field != tt.wantPaths[i] {
+					t.Errorf("validateAutoscaling() error path = %s, want %s", err.Field, tt.wantPaths[i])
+				}
+			}
+		})
+	}
+}
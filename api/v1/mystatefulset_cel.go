@@ -0,0 +1,195 @@
+package v1
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/checker/decls"
+	"k8s.io/apiextensions-apiserver/pkg/apiserver/schema/cel/library"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+const (
+	// celCostLimit 单个表达式每次求值的成本上限，与 CRD 校验规则的 PerCallLimit 保持一致
+	celCostLimit = 1000000
+
+	celSelfVar    = "self"
+	celOldSelfVar = "oldSelf"
+)
+
+var (
+	celEnvOnce sync.Once
+	celEnv     *cel.Env
+	celEnvErr  error
+
+	// celPrograms 按表达式缓存编译结果，策略热更新时新表达式会重新编译
+	celPrograms sync.Map
+)
+
+// celProgram 是编译后的 CEL 规则
+type celProgram struct {
+	program     cel.Program
+	usesOldSelf bool
+}
+
+// getCELEnv 返回包含 Kubernetes 扩展库的 CEL 环境
+func getCELEnv() (*cel.Env, error) {
+	celEnvOnce.Do(func() {
+		opts := []cel.EnvOption{
+			cel.Declarations(
+				decls.NewVar(celSelfVar, decls.Dyn),
+				decls.NewVar(celOldSelfVar, decls.Dyn),
+			),
+		}
+		opts = append(opts, library.ExtensionLibs...)
+		celEnv, celEnvErr = cel.NewEnv(opts...)
+	})
+	return celEnv, celEnvErr
+}
+
+// compileCELRule 编译表达式并缓存结果
+func compileCELRule(expression string) (*celProgram, error) {
+	if cached, ok := celPrograms.Load(expression); ok {
+		return cached.(*celProgram), nil
+	}
+
+	env, err := getCELEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	ast, issues := env.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, issues.Err()
+	}
+	// self 为 dyn 类型，大多数表达式的结果类型也是 dyn，只能在求值时检查；能确定类型时必须为 bool
+	if resultType := ast.ResultType(); resultType.GetDyn() == nil &&
+		resultType.GetPrimitive() != decls.Bool.GetPrimitive() {
+		return nil, fmt.Errorf("rule must evaluate to a bool")
+	}
+
+	// 通过引用表判断表达式是否使用了 oldSelf
+	checked, err := cel.AstToCheckedExpr(ast)
+	if err != nil {
+		return nil, err
+	}
+	usesOldSelf := false
+	for _, ref := range checked.ReferenceMap {
+		if ref.GetName() == celOldSelfVar {
+			usesOldSelf = true
+			break
+		}
+	}
+
+	program, err := env.Program(ast,
+		cel.EvalOptions(cel.OptOptimize),
+		cel.OptimizeRegex(library.ExtensionLibRegexOptimizations...),
+		cel.CostLimit(celCostLimit))
+	if err != nil {
+		return nil, err
+	}
+
+	compiled := &celProgram{program: program, usesOldSelf: usesOldSelf}
+	celPrograms.Store(expression, compiled)
+	return compiled, nil
+}
+
+// validateCELRules 对 MyStatefulset 执行策略中的 CEL 规则，old 为 nil 表示创建
+func (r *MyStatefulset) validateCELRules(rules admissionRules, old *MyStatefulset) field.ErrorList {
+	var allErrs field.ErrorList
+	if len(rules.Validations) == 0 {
+		return allErrs
+	}
+
+	self, err := runtime.DefaultUnstructuredConverter.ToUnstructured(r)
+	if err != nil {
+		return append(allErrs, field.InternalError(nil, err))
+	}
+	var oldSelf map[string]interface{}
+	if old != nil {
+		if oldSelf, err = runtime.DefaultUnstructuredConverter.ToUnstructured(old); err != nil {
+			return append(allErrs, field.InternalError(nil, err))
+		}
+	}
+
+	for _, validation := range rules.Validations {
+		rule := validation.Rule
+		path := parseFieldPath(rule.FieldPath)
+
+		compiled, err := compileCELRule(rule.Expression)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(path, rule.Expression,
+				fmt.Sprintf("policy %s: invalid validation rule: %v", validation.Policy, err)))
+			continue
+		}
+		// 引用 oldSelf 的规则只在更新时执行
+		if compiled.usesOldSelf && old == nil {
+			continue
+		}
+
+		activation := map[string]interface{}{
+			celSelfVar:    self,
+			celOldSelfVar: oldSelf,
+		}
+		out, _, err := compiled.program.Eval(activation)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(path, rule.Expression,
+				fmt.Sprintf("policy %s: rule evaluation error: %v", validation.Policy, err)))
+			continue
+		}
+
+		passed, ok := out.Value().(bool)
+		if !ok {
+			allErrs = append(allErrs, field.Invalid(path, rule.Expression,
+				fmt.Sprintf("policy %s: rule must evaluate to a bool, got %v", validation.Policy, out.Type())))
+			continue
+		}
+		if !passed {
+			message := rule.Message
+			if message == "" {
+				message = fmt.Sprintf("failed rule: %s", rule.Expression)
+			}
+			allErrs = append(allErrs, field.Forbidden(path, message))
+		}
+	}
+
+	return allErrs
+}
+
+// parseFieldPath 将 spec.template.spec.containers[0].image 形式的路径转换为 field.Path
+func parseFieldPath(path string) *field.Path {
+	path = strings.TrimPrefix(strings.TrimSpace(path), ".")
+	if path == "" {
+		return field.NewPath("spec")
+	}
+
+	var result *field.Path
+	for _, segment := range strings.Split(path, ".") {
+		name := segment
+		var subscripts []string
+		if i := strings.Index(segment, "["); i >= 0 {
+			name = segment[:i]
+			for _, s := range strings.Split(segment[i:], "[")[1:] {
+				subscripts = append(subscripts, strings.TrimSuffix(s, "]"))
+			}
+		}
+
+		if result == nil {
+			result = field.NewPath(name)
+		} else {
+			result = result.Child(name)
+		}
+		for _, s := range subscripts {
+			if index, err := strconv.Atoi(s); err == nil {
+				result = result.Index(index)
+			} else {
+				result = result.Key(strings.Trim(s, `'"`))
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,163 @@
+package v1
+
+import (
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/pointer"
+)
+
+func TestMyStatefulset_validateCELRules(t *testing.T) {
+	rulesWith := func(validations ...ValidationRule) admissionRules {
+		rules := defaultAdmissionRules()
+		for _, v := range validations {
+			rules.Validations = append(rules.Validations, policyValidation{Policy: "test", Rule: v})
+		}
+		return rules
+	}
+
+	tests := []struct {
+		name      string
+		rules     admissionRules
+		newMs     *MyStatefulset
+		oldMs     *MyStatefulset
+		wantPaths []string
+	}{
+		{
+			name: "image registry rule passes",
+			rules: rulesWith(ValidationRule{
+				Expression: "self.spec.template.spec.containers.all(c, c.image.startsWith('nginx'))",
+			}),
+			newMs: newPolicyTestMyStatefulset("default", 3),
+		},
+		{
+			name: "image registry rule fails with user-defined path",
+			rules: rulesWith(ValidationRule{
+				Expression: "self.spec.template.spec.containers.all(c, c.image.startsWith('registry.corp/'))",
+				Message:    "images must come from registry.corp",
+				FieldPath:  "spec.template.spec.containers[0].image",
+			}),
+			newMs:     newPolicyTestMyStatefulset("default", 3),
+			wantPaths: []string{"spec.template.spec.containers[0].image"},
+		},
+		{
+			name: "oldSelf rule is skipped on create",
+			rules: rulesWith(ValidationRule{
+				Expression: "oldSelf.spec.replicas - self.spec.replicas <= 3",
+			}),
+			newMs: newPolicyTestMyStatefulset("default", 1),
+		},
+		{
+			name: "oldSelf rule rejects large scale down",
+			rules: rulesWith(ValidationRule{
+				Expression: "oldSelf.spec.replicas - self.spec.replicas <= 3",
+				FieldPath:  "spec.replicas",
+			}),
+			newMs:     newPolicyTestMyStatefulset("default", 1),
+			oldMs:     newPolicyTestMyStatefulset("default", 10),
+			wantPaths: []string{"spec.replicas"},
+		},
+		{
+			name: "invalid expression is reported",
+			rules: rulesWith(ValidationRule{
+				Expression: "self.spec.replicas >",
+			}),
+			newMs:     newPolicyTestMyStatefulset("default", 1),
+			wantPaths: []string{"spec"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := tt.newMs.validateCELRules(tt.rules, tt.oldMs)
+			if len(errs) != len(tt.wantPaths) {
+				t.Fatalf("validateCELRules() = %v, want %d errors", errs, len(tt.wantPaths))
+			}
+			for i, err := range errs {
+				if err.Field != tt.wantPaths[i] {
+					t.Errorf("validateCELRules() error path = %s, want %s", err.Field, tt.wantPaths[i])
+				}
+			}
+		})
+	}
+}
+
+func TestMyStatefulset_ValidateUpdateWithCELPolicy(t *testing.T) {
+	policy := &MyStatefulsetPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "scale-down-guard"},
+		Spec: MyStatefulsetPolicySpec{
+			Validations: []ValidationRule{
+				{
+					Expression: "oldSelf.spec.replicas - self.spec.replicas <= 3",
+					Message:    "cannot scale down by more than 3 replicas at once",
+					FieldPath:  "spec.replicas",
+				},
+			},
+		},
+	}
+	withPolicyReader(t, policy)
+
+	oldMs := newPolicyTestMyStatefulset("default", 10)
+	newMs := oldMs.DeepCopy()
+	newMs.Spec.Replicas = pointer.Int32(2)
+	if err := newMs.ValidateUpdate(oldMs); err == nil {
+		t.Errorf("ValidateUpdate() expected CEL rule to reject the update")
+	}
+
+	newMs.Spec.Replicas = pointer.Int32(8)
+	if err := newMs.ValidateUpdate(oldMs); err != nil {
+		t.Errorf("ValidateUpdate() unexpected error = %v", err)
+	}
+}
+
+func TestParseFieldPath(t *testing.T) {
+	tests := []struct {
+		in   string
+		want *field.Path
+	}{
+		{in: "", want: field.NewPath("spec")},
+		{in: "spec.replicas", want: field.NewPath("spec", "replicas")},
+		{in: ".spec.template.spec.containers[0].image", want: field.NewPath("spec", "template", "spec", "containers").Index(0).Child("image")},
+		{in: "metadata.labels['app']", want: field.NewPath("metadata", "labels").Key("app")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if got := parseFieldPath(tt.in); got.String() != tt.want.String() {
+				t.Errorf("parseFieldPath(%q) = %s, want %s", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMyStatefulset_ValidateCreateReportsAllErrors(t *testing.T) {
+	policy := &MyStatefulsetPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "registry"},
+		Spec: MyStatefulsetPolicySpec{
+			Validations: []ValidationRule{{
+				Expression: "self.spec.replicas <= 2",
+				FieldPath:  "spec.replicas",
+			}},
+		},
+	}
+	withPolicyReader(t, policy)
+
+	// 基本校验和 CEL 规则同时失败时，两类错误都应返回
+	ms := newPolicyTestMyStatefulset("default", 3)
+	ms.Spec.Template.Spec.Containers[0].Image = ""
+	err := ms.ValidateCreate()
+	statusErr, ok := err.(*apierrors.StatusError)
+	if !ok {
+		t.Fatalf("ValidateCreate() error = %v, want an Invalid error", err)
+	}
+	var fields []string
+	for _, cause := range statusErr.ErrStatus.Details.Causes {
+		fields = append(fields, cause.Field)
+	}
+	want := []string{"spec.template.spec.containers[0].image", "spec.replicas"}
+	if len(fields) != len(want) || fields[0] != want[0] || fields[1] != want[1] {
+		t.Errorf("ValidateCreate() cause fields = %v, want %v", fields, want)
+	}
+}
@@ -0,0 +1,357 @@
+package v1
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	v2 "github.com/bryant-rh/my-statefulset/api/v2"
+)
+
+// ConvertTo 将 v1 转换为 v2（hub）
+func (src *MyStatefulset) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v2.MyStatefulset)
+
+	dst.ObjectMeta = src.ObjectMeta
+
+	// Spec
+	dst.Spec.Replicas = src.Spec.Replicas
+	dst.Spec.ServiceName = src.Spec.ServiceName
+	dst.Spec.Selector = src.Spec.Selector
+	dst.Spec.Template = v2.PodTemplateSpec(src.Spec.Template)
+	dst.Spec.VolumeClaimTemplates = src.Spec.VolumeClaimTemplates
+	dst.Spec.VolumeClaimDataSources = convertDataSourcesToV2(src.Spec.VolumeClaimDataSources)
+	dst.Spec.OrdinalOverrides = convertOrdinalOverridesToV2(src.Spec.OrdinalOverrides)
+	dst.Spec.Placement = (*v2.PlacementSpec)(src.Spec.Placement)
+	dst.Spec.MinReadySeconds = src.Spec.MinReadySeconds
+	dst.Spec.FastDelete = src.Spec.FastDelete
+	dst.Spec.Suspend = src.Spec.Suspend
+	dst.Spec.NodeFailurePolicy = (*v2.NodeFailurePolicy)(src.Spec.NodeFailurePolicy)
+	dst.Spec.PodPendingDeadlineSeconds = src.Spec.PodPendingDeadlineSeconds
+	dst.Spec.ScaleDownDrainSeconds = src.Spec.ScaleDownDrainSeconds
+	dst.Spec.SnapshotPolicy = (*v2.SnapshotPolicy)(src.Spec.SnapshotPolicy)
+	dst.Spec.ScalingSchedule = convertScalingScheduleToV2(src.Spec.ScalingSchedule)
+	dst.Spec.Autoscaling = (*v2.AutoscalingSpec)(src.Spec.Autoscaling)
+	dst.Spec.DependsOn = convertDependsOnToV2(src.Spec.DependsOn)
+	dst.Spec.Rollout = v2.RolloutSpec{
+		Strategy: v2.RolloutStrategyType(src.Spec.UpdateStrategy.Type),
+		Paused:   src.Spec.UpdateStrategy.Paused,
+	}
+	if src.Spec.UpdateStrategy.RollingUpdate != nil {
+		dst.Spec.Rollout.RollingUpdate = &v2.RollingUpdateRollout{
+			Partition:      src.Spec.UpdateStrategy.RollingUpdate.Partition,
+			MaxUnavailable: src.Spec.UpdateStrategy.RollingUpdate.MaxUnavailable,
+		}
+	}
+
+	// Status
+	dst.Status = v2.MyStatefulsetStatus{
+		CurrentGeneration:  src.Status.CurrentGeneration,
+		Replicas:           src.Status.Replicas,
+		ReadyReplicas:      src.Status.ReadyReplicas,
+		CurrentReplicas:    src.Status.CurrentReplicas,
+		UpdatedReplicas:    src.Status.UpdatedReplicas,
+		AvailableReplicas:  src.Status.AvailableReplicas,
+		Selector:           src.Status.Selector,
+		ZoneDistribution:   convertZoneDistributionToV2(src.Status.ZoneDistribution),
+		PodFailures:        convertPodFailuresToV2(src.Status.PodFailures),
+		PodStatuses:        convertPodStatusesToV2(src.Status.PodStatuses),
+		NotReadyOrdinals:   src.Status.NotReadyOrdinals,
+		Snapshots:          convertSnapshotsToV2(src.Status.Snapshots),
+		ScalingSchedule:    (*v2.ScalingScheduleStatus)(src.Status.ScalingSchedule),
+		Autoscaling:        convertAutoscalingStatusToV2(src.Status.Autoscaling),
+		SuspendedReplicas:  src.Status.SuspendedReplicas,
+		ScaleDown:          (*v2.ScaleDownStatus)(src.Status.ScaleDown),
+		ObservedGeneration: src.Status.ObservedGeneration,
+		Conditions:         src.Status.Conditions,
+	}
+
+	return nil
+}
+
+// ConvertFrom 将 v2（hub）转换为 v1
+func (dst *MyStatefulset) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v2.MyStatefulset)
+
+	dst.ObjectMeta = src.ObjectMeta
+
+	// Spec
+	dst.Spec.Replicas = src.Spec.Replicas
+	dst.Spec.ServiceName = src.Spec.ServiceName
+	dst.Spec.Selector = src.Spec.Selector
+	dst.Spec.Template = PodTemplateSpec(src.Spec.Template)
+	dst.Spec.VolumeClaimTemplates = src.Spec.VolumeClaimTemplates
+	dst.Spec.VolumeClaimDataSources = convertDataSourcesFromV2(src.Spec.VolumeClaimDataSources)
+	dst.Spec.OrdinalOverrides = convertOrdinalOverridesFromV2(src.Spec.OrdinalOverrides)
+	dst.Spec.Placement = (*PlacementSpec)(src.Spec.Placement)
+	dst.Spec.MinReadySeconds = src.Spec.MinReadySeconds
+	dst.Spec.FastDelete = src.Spec.FastDelete
+	dst.Spec.Suspend = src.Spec.Suspend
+	dst.Spec.NodeFailurePolicy = (*NodeFailurePolicy)(src.Spec.NodeFailurePolicy)
+	dst.Spec.PodPendingDeadlineSeconds = src.Spec.PodPendingDeadlineSeconds
+	dst.Spec.ScaleDownDrainSeconds = src.Spec.ScaleDownDrainSeconds
+	dst.Spec.SnapshotPolicy = (*SnapshotPolicy)(src.Spec.SnapshotPolicy)
+	dst.Spec.ScalingSchedule = convertScalingScheduleFromV2(src.Spec.ScalingSchedule)
+	dst.Spec.Autoscaling = (*AutoscalingSpec)(src.Spec.Autoscaling)
+	dst.Spec.DependsOn = convertDependsOnFromV2(src.Spec.DependsOn)
+	dst.Spec.UpdateStrategy = UpdateStrategy{
+		Type:   StatefulSetUpdateStrategyType(src.Spec.Rollout.Strategy),
+		Paused: src.Spec.Rollout.Paused,
+	}
+	if src.Spec.Rollout.RollingUpdate != nil {
+		dst.Spec.UpdateStrategy.RollingUpdate = &RollingUpdateStatefulSetStrategy{
+			Partition:      src.Spec.Rollout.RollingUpdate.Partition,
+			MaxUnavailable: src.Spec.Rollout.RollingUpdate.MaxUnavailable,
+		}
+	}
+
+	// Status
+	dst.Status = MyStatefulsetStatus{
+		CurrentGeneration:  src.Status.CurrentGeneration,
+		Replicas:           src.Status.Replicas,
+		ReadyReplicas:      src.Status.ReadyReplicas,
+		CurrentReplicas:    src.Status.CurrentReplicas,
+		UpdatedReplicas:    src.Status.UpdatedReplicas,
+		AvailableReplicas:  src.Status.AvailableReplicas,
+		Selector:           src.Status.Selector,
+		ZoneDistribution:   convertZoneDistributionFromV2(src.Status.ZoneDistribution),
+		PodFailures:        convertPodFailuresFromV2(src.Status.PodFailures),
+		PodStatuses:        convertPodStatusesFromV2(src.Status.PodStatuses),
+		NotReadyOrdinals:   src.Status.NotReadyOrdinals,
+		Snapshots:          convertSnapshotsFromV2(src.Status.Snapshots),
+		ScalingSchedule:    (*ScalingScheduleStatus)(src.Status.ScalingSchedule),
+		Autoscaling:        convertAutoscalingStatusFromV2(src.Status.Autoscaling),
+		SuspendedReplicas:  src.Status.SuspendedReplicas,
+		ScaleDown:          (*ScaleDownStatus)(src.Status.ScaleDown),
+		Conditions:         src.Status.Conditions,
+		ObservedGeneration: src.Status.ObservedGeneration,
+	}
+
+	return nil
+}
+
+func convertDataSourcesToV2(in []VolumeClaimDataSource) []v2.VolumeClaimDataSource {
+	if in == nil {
+		return nil
+	}
+	out := make([]v2.VolumeClaimDataSource, len(in))
+	for i, d := range in {
+		out[i] = v2.VolumeClaimDataSource(d)
+	}
+	return out
+}
+
+func convertDataSourcesFromV2(in []v2.VolumeClaimDataSource) []VolumeClaimDataSource {
+	if in == nil {
+		return nil
+	}
+	out := make([]VolumeClaimDataSource, len(in))
+	for i, d := range in {
+		out[i] = VolumeClaimDataSource(d)
+	}
+	return out
+}
+
+func convertScalingScheduleToV2(in []ScheduledScaling) []v2.ScheduledScaling {
+	if in == nil {
+		return nil
+	}
+	out := make([]v2.ScheduledScaling, len(in))
+	for i, s := range in {
+		out[i] = v2.ScheduledScaling(s)
+	}
+	return out
+}
+
+func convertScalingScheduleFromV2(in []v2.ScheduledScaling) []ScheduledScaling {
+	if in == nil {
+		return nil
+	}
+	out := make([]ScheduledScaling, len(in))
+	for i, s := range in {
+		out[i] = ScheduledScaling(s)
+	}
+	return out
+}
+
+func convertDependsOnToV2(in []Dependency) []v2.Dependency {
+	if in == nil {
+		return nil
+	}
+	out := make([]v2.Dependency, len(in))
+	for i, d := range in {
+		out[i] = v2.Dependency(d)
+	}
+	return out
+}
+
+func convertDependsOnFromV2(in []v2.Dependency) []Dependency {
+	if in == nil {
+		return nil
+	}
+	out := make([]Dependency, len(in))
+	for i, d := range in {
+		out[i] = Dependency(d)
+	}
+	return out
+}
+
+func convertAutoscalingStatusToV2(in *AutoscalingStatus) *v2.AutoscalingStatus {
+	if in == nil {
+		return nil
+	}
+	out := &v2.AutoscalingStatus{
+		CurrentCPUUtilizationPercentage:    in.CurrentCPUUtilizationPercentage,
+		CurrentMemoryUtilizationPercentage: in.CurrentMemoryUtilizationPercentage,
+		DesiredReplicas:                    in.DesiredReplicas,
+		LastScaleTime:                      in.LastScaleTime,
+		LastRejection:                      (*v2.AutoscalingRejection)(in.LastRejection),
+	}
+	if in.Recommendations != nil {
+		out.Recommendations = make([]v2.ReplicaRecommendation, len(in.Recommendations))
+		for i, r := range in.Recommendations {
+			out.Recommendations[i] = v2.ReplicaRecommendation(r)
+		}
+	}
+	return out
+}
+
+func convertAutoscalingStatusFromV2(in *v2.AutoscalingStatus) *AutoscalingStatus {
+	if in == nil {
+		return nil
+	}
+	out := &AutoscalingStatus{
+		CurrentCPUUtilizationPercentage:    in.CurrentCPUUtilizationPercentage,
+		CurrentMemoryUtilizationPercentage: in.CurrentMemoryUtilizationPercentage,
+		DesiredReplicas:                    in.DesiredReplicas,
+		LastScaleTime:                      in.LastScaleTime,
+		LastRejection:                      (*AutoscalingRejection)(in.LastRejection),
+	}
+	if in.Recommendations != nil {
+		out.Recommendations = make([]ReplicaRecommendation, len(in.Recommendations))
+		for i, r := range in.Recommendations {
+			out.Recommendations[i] = ReplicaRecommendation(r)
+		}
+	}
+	return out
+}
+
+func convertOrdinalOverridesToV2(in []OrdinalOverride) []v2.OrdinalOverride {
+	if in == nil {
+		return nil
+	}
+	out := make([]v2.OrdinalOverride, len(in))
+	for i, o := range in {
+		out[i] = v2.OrdinalOverride{Ordinals: o.Ordinals, Patch: o.Patch}
+		if o.Ranges != nil {
+			out[i].Ranges = make([]v2.OrdinalRange, len(o.Ranges))
+			for j, r := range o.Ranges {
+				out[i].Ranges[j] = v2.OrdinalRange(r)
+			}
+		}
+	}
+	return out
+}
+
+func convertOrdinalOverridesFromV2(in []v2.OrdinalOverride) []OrdinalOverride {
+	if in == nil {
+		return nil
+	}
+	out := make([]OrdinalOverride, len(in))
+	for i, o := range in {
+		out[i] = OrdinalOverride{Ordinals: o.Ordinals, Patch: o.Patch}
+		if o.Ranges != nil {
+			out[i].Ranges = make([]OrdinalRange, len(o.Ranges))
+			for j, r := range o.Ranges {
+				out[i].Ranges[j] = OrdinalRange(r)
+			}
+		}
+	}
+	return out
+}
+
+func convertZoneDistributionToV2(in []ZoneReplicas) []v2.ZoneReplicas {
+	if in == nil {
+		return nil
+	}
+	out := make([]v2.ZoneReplicas, len(in))
+	for i, z := range in {
+		out[i] = v2.ZoneReplicas(z)
+	}
+	return out
+}
+
+func convertZoneDistributionFromV2(in []v2.ZoneReplicas) []ZoneReplicas {
+	if in == nil {
+		return nil
+	}
+	out := make([]ZoneReplicas, len(in))
+	for i, z := range in {
+		out[i] = ZoneReplicas(z)
+	}
+	return out
+}
+
+func convertPodFailuresToV2(in []PodFailure) []v2.PodFailure {
+	if in == nil {
+		return nil
+	}
+	out := make([]v2.PodFailure, len(in))
+	for i, f := range in {
+		out[i] = v2.PodFailure(f)
+	}
+	return out
+}
+
+func convertPodFailuresFromV2(in []v2.PodFailure) []PodFailure {
+	if in == nil {
+		return nil
+	}
+	out := make([]PodFailure, len(in))
+	for i, f := range in {
+		out[i] = PodFailure(f)
+	}
+	return out
+}
+
+func convertPodStatusesToV2(in []PodStatusDetail) []v2.PodStatusDetail {
+	if in == nil {
+		return nil
+	}
+	out := make([]v2.PodStatusDetail, len(in))
+	for i, p := range in {
+		out[i] = v2.PodStatusDetail(p)
+	}
+	return out
+}
+
+func convertPodStatusesFromV2(in []v2.PodStatusDetail) []PodStatusDetail {
+	if in == nil {
+		return nil
+	}
+	out := make([]PodStatusDetail, len(in))
+	for i, p := range in {
+		out[i] = PodStatusDetail(p)
+	}
+	return out
+}
+
+func convertSnapshotsToV2(in []VolumeSnapshotReference) []v2.VolumeSnapshotReference {
+	if in == nil {
+		return nil
+	}
+	out := make([]v2.VolumeSnapshotReference, len(in))
+	for i, ref := range in {
+		out[i] = v2.VolumeSnapshotReference(ref)
+	}
+	return out
+}
+
+func convertSnapshotsFromV2(in []v2.VolumeSnapshotReference) []VolumeSnapshotReference {
+	if in == nil {
+		return nil
+	}
+	out := make([]VolumeSnapshotReference, len(in))
+	for i, ref := range in {
+		out[i] = VolumeSnapshotReference(ref)
+	}
+	return out
+}
@@ -0,0 +1,115 @@
+package v1
+
+import (
+	"math/rand"
+	"testing"
+
+	fuzz "github.com/google/gofuzz"
+	"k8s.io/apimachinery/pkg/api/apitesting/fuzzer"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metafuzzer "k8s.io/apimachinery/pkg/apis/meta/fuzzer"
+	"k8s.io/apimachinery/pkg/runtime"
+	runtimeserializer "k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/util/diff"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
+
+	v2 "github.com/bryant-rh/my-statefulset/api/v2"
+)
+
+const conversionFuzzIterations = 1000
+
+func newConversionFuzzer(t *testing.T) *fuzz.Fuzzer {
+	t.Helper()
+
+	s := runtime.NewScheme()
+	_ = AddToScheme(s)
+	_ = v2.AddToScheme(s)
+
+	seed := rand.Int63()
+	t.Logf("fuzz seed: %d", seed)
+	return fuzzer.FuzzerFor(
+		metafuzzer.Funcs,
+		rand.NewSource(seed),
+		runtimeserializer.NewCodecFactory(s))
+}
+
+func TestMyStatefulset_ConversionRoundTrip_SpokeHubSpoke(t *testing.T) {
+	f := newConversionFuzzer(t)
+
+	for i := 0; i < conversionFuzzIterations; i++ {
+		spoke := &MyStatefulset{}
+		f.Fuzz(spoke)
+
+		hub := &v2.MyStatefulset{}
+		if err := spoke.DeepCopy().ConvertTo(hub); err != nil {
+			t.Fatalf("ConvertTo() error = %v", err)
+		}
+		got := &MyStatefulset{}
+		if err := got.ConvertFrom(hub); err != nil {
+			t.Fatalf("ConvertFrom() error = %v", err)
+		}
+
+		if !apiequality.Semantic.DeepEqual(spoke, got) {
+			t.Fatalf("v1 -> v2 -> v1 lost data:\n%s", diff.ObjectReflectDiff(spoke, got))
+		}
+	}
+}
+
+func TestMyStatefulset_ConversionRoundTrip_HubSpokeHub(t *testing.T) {
+	f := newConversionFuzzer(t)
+
+	for i := 0; i < conversionFuzzIterations; i++ {
+		hub := &v2.MyStatefulset{}
+		f.Fuzz(hub)
+
+		spoke := &MyStatefulset{}
+		if err := spoke.ConvertFrom(hub.DeepCopy()); err != nil {
+			t.Fatalf("ConvertFrom() error = %v", err)
+		}
+		got := &v2.MyStatefulset{}
+		if err := spoke.ConvertTo(got); err != nil {
+			t.Fatalf("ConvertTo() error = %v", err)
+		}
+
+		if !apiequality.Semantic.DeepEqual(hub, got) {
+			t.Fatalf("v2 -> v1 -> v2 lost data:\n%s", diff.ObjectReflectDiff(hub, got))
+		}
+	}
+}
+
+func TestMyStatefulset_ConvertFrom(t *testing.T) {
+	maxUnavailable := intstr.FromString("25%")
+	hub := &v2.MyStatefulset{
+		Spec: v2.MyStatefulsetSpec{
+			Replicas:    pointer.Int32(3),
+			ServiceName: "test-service",
+			Rollout: v2.RolloutSpec{
+				Strategy: v2.RollingUpdateRolloutStrategyType,
+				RollingUpdate: &v2.RollingUpdateRollout{
+					Partition:      pointer.Int32(1),
+					MaxUnavailable: &maxUnavailable,
+				},
+				Paused: true,
+			},
+		},
+	}
+
+	spoke := &MyStatefulset{}
+	if err := spoke.ConvertFrom(hub); err != nil {
+		t.Fatalf("ConvertFrom() error = %v", err)
+	}
+
+	if spoke.Spec.UpdateStrategy.Type != RollingUpdateStatefulSetStrategyType {
+		t.Errorf("UpdateStrategy.Type = %s, want %s", spoke.Spec.UpdateStrategy.Type, RollingUpdateStatefulSetStrategyType)
+	}
+	if got := spoke.Spec.UpdateStrategy.RollingUpdate.Partition; got == nil || *got != 1 {
+		t.Errorf("UpdateStrategy.RollingUpdate.Partition = %v, want 1", got)
+	}
+	if got := spoke.Spec.UpdateStrategy.RollingUpdate.MaxUnavailable; got == nil || got.String() != "25%" {
+		t.Errorf("UpdateStrategy.RollingUpdate.MaxUnavailable = %v, want 25%%", got)
+	}
+	if !spoke.Spec.UpdateStrategy.Paused {
+		t.Errorf("UpdateStrategy.Paused = false, want true")
+	}
+}
@@ -0,0 +1,85 @@
+package v1
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// OrdinalPlaceholder is replaced by the ordinal in VolumeClaimDataSource.VolumeSnapshotName.
+const OrdinalPlaceholder = "{{ordinal}}"
+
+// VolumeSnapshotAPIGroup is the API group of CSI VolumeSnapshots.
+const VolumeSnapshotAPIGroup = "snapshot.storage.k8s.io"
+
+// DataSourceForClaim 返回 claim 模板在指定序号上的数据源，没有配置时返回 nil
+func (m *MyStatefulset) DataSourceForClaim(claimTemplate string, ordinal int) *corev1.TypedLocalObjectReference {
+	for _, source := range m.Spec.VolumeClaimDataSources {
+		if source.ClaimTemplate != claimTemplate {
+			continue
+		}
+		if source.VolumeSnapshotName != "" {
+			apiGroup := VolumeSnapshotAPIGroup
+			return &corev1.TypedLocalObjectReference{
+				APIGroup: &apiGroup,
+				Kind:     "VolumeSnapshot",
+				Name:     strings.ReplaceAll(source.VolumeSnapshotName, OrdinalPlaceholder, strconv.Itoa(ordinal)),
+			}
+		}
+		if source.SourceSet != "" {
+			return &corev1.TypedLocalObjectReference{
+				Kind: "PersistentVolumeClaim",
+				Name: fmt.Sprintf("%s-%s-%d", claimTemplate, source.SourceSet, ordinal),
+			}
+		}
+	}
+	return nil
+}
+
+// validateDataSources 验证每个数据源引用了存在的 claim 模板，并且只设置了快照或源副本集中的一个
+func (r *MyStatefulset) validateDataSources() field.ErrorList {
+	var allErrs field.ErrorList
+	sourcesPath := field.NewPath("spec").Child("volumeClaimDataSources")
+
+	claimNames := make(map[string]bool, len(r.Spec.VolumeClaimTemplates))
+	for _, pvcTemplate := range r.Spec.VolumeClaimTemplates {
+		claimNames[ClaimVolumeName(pvcTemplate)] = true
+	}
+
+	seen := make(map[string]bool, len(r.Spec.VolumeClaimDataSources))
+	for i, source := range r.Spec.VolumeClaimDataSources {
+		path := sourcesPath.Index(i)
+
+		if !claimNames[source.ClaimTemplate] {
+			allErrs = append(allErrs, field.NotFound(path.Child("claimTemplate"), source.ClaimTemplate))
+		} else if seen[source.ClaimTemplate] {
+			allErrs = append(allErrs, field.Duplicate(path.Child("claimTemplate"), source.ClaimTemplate))
+		}
+		seen[source.ClaimTemplate] = true
+
+		switch {
+		case source.VolumeSnapshotName == "" && source.SourceSet == "":
+			allErrs = append(allErrs, field.Required(path, "one of volumeSnapshotName or sourceSet must be set"))
+		case source.VolumeSnapshotName != "" && source.SourceSet != "":
+			allErrs = append(allErrs, field.Invalid(path, source.SourceSet, "only one of volumeSnapshotName or sourceSet may be set"))
+		case source.VolumeSnapshotName != "":
+			// 用序号 0 替换占位符后校验名称格式
+			name := strings.ReplaceAll(source.VolumeSnapshotName, OrdinalPlaceholder, "0")
+			for _, msg := range validation.IsDNS1123Subdomain(name) {
+				allErrs = append(allErrs, field.Invalid(path.Child("volumeSnapshotName"), source.VolumeSnapshotName, msg))
+			}
+		default:
+			if source.SourceSet == r.Name {
+				allErrs = append(allErrs, field.Invalid(path.Child("sourceSet"), source.SourceSet, "cannot clone the PVCs of the set itself"))
+			}
+			for _, msg := range validation.IsDNS1123Subdomain(source.SourceSet) {
+				allErrs = append(allErrs, field.Invalid(path.Child("sourceSet"), source.SourceSet, msg))
+			}
+		}
+	}
+	return allErrs
+}
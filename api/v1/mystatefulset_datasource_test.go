@@ -0,0 +1,90 @@
+package v1
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMyStatefulset_DataSourceForClaim(t *testing.T) {
+	ms := newPolicyTestMyStatefulset("default", 3)
+	ms.Spec.VolumeClaimDataSources = []VolumeClaimDataSource{
+		{ClaimTemplate: "data", VolumeSnapshotName: "db-snap-{{ordinal}}"},
+		{ClaimTemplate: "logs", SourceSet: "db-old"},
+	}
+
+	data := ms.DataSourceForClaim("data", 2)
+	if data == nil || data.Kind != "VolumeSnapshot" || data.Name != "db-snap-2" ||
+		data.APIGroup == nil || *data.APIGroup != VolumeSnapshotAPIGroup {
+		t.Errorf("DataSourceForClaim(data, 2) = %+v, want VolumeSnapshot db-snap-2", data)
+	}
+	logs := ms.DataSourceForClaim("logs", 1)
+	if logs == nil || logs.Kind != "PersistentVolumeClaim" || logs.Name != "logs-db-old-1" || logs.APIGroup != nil {
+		t.Errorf("DataSourceForClaim(logs, 1) = %+v, want PersistentVolumeClaim logs-db-old-1", logs)
+	}
+	if got := ms.DataSourceForClaim("www", 0); got != nil {
+		t.Errorf("DataSourceForClaim(www, 0) = %+v, want nil", got)
+	}
+}
+
+func TestMyStatefulset_validateDataSources(t *testing.T) {
+	tests := []struct {
+		name      string
+		sources   []VolumeClaimDataSource
+		wantPaths []string
+	}{
+		{
+			name: "valid sources",
+			sources: []VolumeClaimDataSource{
+				{ClaimTemplate: "data", VolumeSnapshotName: "db-snap-{{ordinal}}"},
+				{ClaimTemplate: "www", SourceSet: "db-old"},
+			},
+		},
+		{
+			name:      "unknown and duplicate claim templates",
+			sources:   []VolumeClaimDataSource{{ClaimTemplate: "data", SourceSet: "a"}, {ClaimTemplate: "data", SourceSet: "b"}, {ClaimTemplate: "cache", SourceSet: "a"}},
+			wantPaths: []string{"spec.volumeClaimDataSources[1].claimTemplate", "spec.volumeClaimDataSources[2].claimTemplate"},
+		},
+		{
+			name:      "no source",
+			sources:   []VolumeClaimDataSource{{ClaimTemplate: "data"}},
+			wantPaths: []string{"spec.volumeClaimDataSources[0]"},
+		},
+		{
+			name:      "both sources",
+			sources:   []VolumeClaimDataSource{{ClaimTemplate: "data", VolumeSnapshotName: "snap", SourceSet: "db-old"}},
+			wantPaths: []string{"spec.volumeClaimDataSources[0]"},
+		},
+		{
+			name:      "invalid snapshot name pattern",
+			sources:   []VolumeClaimDataSource{{ClaimTemplate: "data", VolumeSnapshotName: "Snap_{{ordinal}}"}},
+			wantPaths: []string{"spec.volumeClaimDataSources[0].volumeSnapshotName"},
+		},
+		{
+			name:      "clone from itself",
+			sources:   []VolumeClaimDataSource{{ClaimTemplate: "data", SourceSet: "test-mystatefulset"}},
+			wantPaths: []string{"spec.volumeClaimDataSources[0].sourceSet"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms := newPolicyTestMyStatefulset("default", 3)
+			ms.Spec.VolumeClaimTemplates = []corev1.PersistentVolumeClaim{
+				{ObjectMeta: metav1.ObjectMeta{Name: "data"}},
+				{},
+			}
+			ms.Spec.VolumeClaimDataSources = tt.sources
+			errs := ms.validateDataSources()
+			if len(errs) != len(tt.wantPaths) {
+				t.Fatalf("validateDataSources() = %v, want %d errors", errs, len(tt.wantPaths))
+			}
+			for i, err := range errs {
+				if err.Field != tt.wantPaths[i] {
+					t.Errorf("validateDataSources() error path = %s, want %s", err.Field, tt.wantPaths[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package v1
+
+import (
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+const (
+	// ConditionAvailable is True when spec.replicas pods are available.
+	ConditionAvailable = "Available"
+
+	// ConditionWaitingForDependencies is True while an object in
+	// spec.dependsOn is not available and pods are held back.
+	ConditionWaitingForDependencies = "WaitingForDependencies"
+)
+
+// IsMyStatefulset 判断依赖是否指向另一个 MyStatefulset
+func (d *Dependency) IsMyStatefulset() bool {
+	return d.APIVersion == "" && d.Kind == ""
+}
+
+// GroupVersionKind 返回依赖对象的 GVK，未设置 apiVersion 和 kind 时为 MyStatefulset
+func (d *Dependency) GroupVersionKind() schema.GroupVersionKind {
+	if d.IsMyStatefulset() {
+		return GroupVersion.WithKind("MyStatefulset")
+	}
+	return schema.FromAPIVersionAndKind(d.APIVersion, d.Kind)
+}
+
+// GetNamespace 返回依赖对象的命名空间，未设置时与 MyStatefulset 相同
+func (d *Dependency) GetNamespace(defaultNamespace string) string {
+	if d.Namespace == "" {
+		return defaultNamespace
+	}
+	return d.Namespace
+}
+
+// GetConditionType 返回需要为 True 的状态条件，未设置时为 Available
+func (d *Dependency) GetConditionType() string {
+	if d.ConditionType == "" {
+		return ConditionAvailable
+	}
+	return d.ConditionType
+}
+
+// validateDependencies 校验 dependsOn 的引用合法，且不依赖自身、不重复
+func (r *MyStatefulset) validateDependencies() field.ErrorList {
+	var allErrs field.ErrorList
+	dependsOnPath := field.NewPath("spec").Child("dependsOn")
+
+	seen := make(map[string]bool, len(r.Spec.DependsOn))
+	for i := range r.Spec.DependsOn {
+		dep := &r.Spec.DependsOn[i]
+		depPath := dependsOnPath.Index(i)
+
+		if dep.Name == "" {
+			allErrs = append(allErrs, field.Required(depPath.Child("name"), "dependency name is required"))
+			continue
+		}
+		if (dep.APIVersion == "") != (dep.Kind == "") {
+			allErrs = append(allErrs, field.Required(depPath.Child("kind"),
+				"apiVersion and kind must be set together"))
+			continue
+		}
+		if !dep.IsMyStatefulset() {
+			if _, err := schema.ParseGroupVersion(dep.APIVersion); err != nil {
+				allErrs = append(allErrs, field.Invalid(depPath.Child("apiVersion"), dep.APIVersion, err.Error()))
+				continue
+			}
+		}
+
+		gvk := dep.GroupVersionKind()
+		namespace := dep.GetNamespace(r.Namespace)
+		if gvk.GroupKind() == GroupVersion.WithKind("MyStatefulset").GroupKind() &&
+			namespace == r.Namespace && dep.Name == r.Name {
+			allErrs = append(allErrs, field.Invalid(depPath.Child("name"), dep.Name,
+				"a MyStatefulset cannot depend on itself"))
+			continue
+		}
+
+		key := gvk.GroupKind().String() + "/" + namespace + "/" + dep.Name
+		if seen[key] {
+			allErrs = append(allErrs, field.Duplicate(depPath, dep.Name))
+			continue
+		}
+		seen[key] = true
+	}
+	return allErrs
+}
@@ -0,0 +1,55 @@
+package v1
+
+import "testing"
+
+func TestMyStatefulset_validateDependencies(t *testing.T) {
+	tests := []struct {
+		name      string
+		deps      []Dependency
+		wantPaths []string
+	}{
+		{name: "unset"},
+		{
+			name: "valid",
+			deps: []Dependency{
+				{Name: "db"},
+				{APIVersion: "apps/v1", Kind: "Deployment", Name: "api", ConditionType: "Available"},
+				{Namespace: "other", Name: "test-mystatefulset"},
+			},
+		},
+		{name: "missing name", deps: []Dependency{{Kind: "Deployment"}}, wantPaths: []string{"spec.dependsOn[0].name"}},
+		{name: "kind without apiVersion", deps: []Dependency{{Kind: "Deployment", Name: "api"}}, wantPaths: []string{"spec.dependsOn[0].kind"}},
+		{
+			name:      "invalid apiVersion",
+			deps:      []Dependency{{APIVersion: "apps/v1/beta", Kind: "Deployment", Name: "api"}},
+			wantPaths: []string{"spec.dependsOn[0].apiVersion"},
+		},
+		{name: "self", deps: []Dependency{{Name: "test-mystatefulset"}}, wantPaths: []string{"spec.dependsOn[0].name"}},
+		{
+			name:      "self with explicit apiVersion",
+			deps:      []Dependency{{APIVersion: "apps.mystatefulset.com/v2", Kind: "MyStatefulset", Namespace: "default", Name: "test-mystatefulset"}},
+			wantPaths: []string{"spec.dependsOn[0].name"},
+		},
+		{
+			name:      "duplicate",
+			deps:      []Dependency{{Name: "db"}, {Namespace: "default", Name: "db"}},
+			wantPaths: []string{"spec.dependsOn[1]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms := newPolicyTestMyStatefulset("default", 3)
+			ms.Spec.DependsOn = tt.deps
+			errs := ms.validateDependencies()
+			if len(errs) != len(tt.wantPaths) {
+				t.Fatalf("validateDependencies() = %v, want %d errors", errs, len(tt.wantPaths))
+			}
+			for i, err := range errs {
+				if err.Field != tt.wantPaths[i] {
+					t.Errorf("validateDependencies() error path = %s, want %s", err.Field, tt.wantPaths[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+)
+
+// Matches 判断覆盖配置是否作用于指定序号
+func (o *OrdinalOverride) Matches(ordinal int32) bool {
+	for _, n := range o.Ordinals {
+		if n == ordinal {
+			return true
+		}
+	}
+	for _, r := range o.Ranges {
+		if ordinal >= r.Start && (r.End == nil || ordinal <= *r.End) {
+			return true
+		}
+	}
+	return false
+}
+
+// PodTemplateForOrdinal 返回指定序号的 Pod 模板：在 spec.template 上注入 spec.placement，
+// 再依次应用匹配的 ordinalOverrides，因此覆盖配置可以修改某个序号的调度约束
+func (m *MyStatefulset) PodTemplateForOrdinal(ordinal int32) (*PodTemplateSpec, error) {
+	template := m.Spec.Template.DeepCopy()
+	m.applyPlacement(template, ordinal)
+	for i := range m.Spec.OrdinalOverrides {
+		override := &m.Spec.OrdinalOverrides[i]
+		if !override.Matches(ordinal) {
+			continue
+		}
+		patched, err := applyTemplatePatch(template, override.Patch.Raw)
+		if err != nil {
+			return nil, fmt.Errorf("ordinalOverrides[%d]: %w", i, err)
+		}
+		template = patched
+	}
+	return template, nil
+}
+
+// applyTemplatePatch 以 corev1.PodTemplateSpec 的 patch 元数据对模板执行 strategic-merge patch
+func applyTemplatePatch(template *PodTemplateSpec, patch []byte) (*PodTemplateSpec, error) {
+	original, err := json.Marshal(template)
+	if err != nil {
+		return nil, err
+	}
+	merged, err := strategicpatch.StrategicMergePatch(original, patch, corev1.PodTemplateSpec{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply patch: %w", err)
+	}
+	result := &PodTemplateSpec{}
+	if err := json.Unmarshal(merged, result); err != nil {
+		return nil, fmt.Errorf("failed to decode patched template: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,67 @@
+package v1
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+)
+
+func TestMyStatefulset_PodTemplateForOrdinal(t *testing.T) {
+	ms := newPolicyTestMyStatefulset("default", 5)
+	ms.Spec.OrdinalOverrides = []OrdinalOverride{
+		{
+			Ordinals: []int32{0},
+			Patch: runtime.RawExtension{Raw: []byte(
+				`{"spec":{"containers":[{"name":"nginx","resources":{"limits":{"memory":"4Gi"}}}]}}`)},
+		},
+		{
+			Ranges: []OrdinalRange{{Start: 3}},
+			Patch:  runtime.RawExtension{Raw: []byte(`{"metadata":{"labels":{"role":"replica"}}}`)},
+		},
+		{
+			Ranges: []OrdinalRange{{Start: 0, End: pointer.Int32(0)}},
+			Patch: runtime.RawExtension{Raw: []byte(
+				`{"spec":{"containers":[{"name":"nginx","resources":{"limits":{"memory":"8Gi"}}}]}}`)},
+		},
+	}
+
+	tests := []struct {
+		ordinal    int32
+		wantMemory string
+		wantRole   string
+	}{
+		{ordinal: 0, wantMemory: "8Gi"},
+		{ordinal: 1},
+		{ordinal: 3, wantRole: "replica"},
+		{ordinal: 4, wantRole: "replica"},
+	}
+
+	for _, tt := range tests {
+		template, err := ms.PodTemplateForOrdinal(tt.ordinal)
+		if err != nil {
+			t.Fatalf("PodTemplateForOrdinal(%d) error = %v", tt.ordinal, err)
+		}
+		if len(template.Spec.Containers) != 1 || template.Spec.Containers[0].Image != "nginx:prod" {
+			t.Errorf("PodTemplateForOrdinal(%d) containers = %v, want the template container", tt.ordinal, template.Spec.Containers)
+		}
+		memory := template.Spec.Containers[0].Resources.Limits.Memory()
+		if tt.wantMemory == "" && !memory.IsZero() {
+			t.Errorf("PodTemplateForOrdinal(%d) memory limit = %s, want none", tt.ordinal, memory)
+		}
+		if tt.wantMemory != "" && memory.Cmp(resource.MustParse(tt.wantMemory)) != 0 {
+			t.Errorf("PodTemplateForOrdinal(%d) memory limit = %s, want %s", tt.ordinal, memory, tt.wantMemory)
+		}
+		if got := template.Labels["role"]; got != tt.wantRole {
+			t.Errorf("PodTemplateForOrdinal(%d) role label = %q, want %q", tt.ordinal, got, tt.wantRole)
+		}
+		if template.Labels["app"] != "test" {
+			t.Errorf("PodTemplateForOrdinal(%d) lost template label app", tt.ordinal)
+		}
+	}
+
+	if ms.Spec.Template.Spec.Containers[0].Resources.Limits != nil {
+		t.Errorf("PodTemplateForOrdinal() must not modify spec.template")
+	}
+}
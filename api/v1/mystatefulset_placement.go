@@ -0,0 +1,111 @@
+package v1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// DefaultPlacementTopologyKey is used when spec.placement.topologyKey is unset.
+const DefaultPlacementTopologyKey = corev1.LabelTopologyZone
+
+// GetTopologyKey 返回拓扑键，未设置时返回默认的可用区标签
+func (p *PlacementSpec) GetTopologyKey() string {
+	if p.TopologyKey == "" {
+		return DefaultPlacementTopologyKey
+	}
+	return p.TopologyKey
+}
+
+// ZoneForOrdinal 返回序号被固定到的拓扑域，未指定 zones 时返回 false
+func (p *PlacementSpec) ZoneForOrdinal(ordinal int32) (string, bool) {
+	if len(p.Zones) == 0 {
+		return "", false
+	}
+	return p.Zones[int(ordinal)%len(p.Zones)], true
+}
+
+// applyPlacement 按 spec.placement 为模板注入 nodeAffinity 或 topologySpreadConstraints
+func (m *MyStatefulset) applyPlacement(template *PodTemplateSpec, ordinal int32) {
+	placement := m.Spec.Placement
+	if placement == nil {
+		return
+	}
+	key := placement.GetTopologyKey()
+
+	// 指定了 zones：序号 i 固定到 zones[i mod len(zones)]
+	if zone, ok := placement.ZoneForOrdinal(ordinal); ok {
+		requirement := corev1.NodeSelectorRequirement{
+			Key:      key,
+			Operator: corev1.NodeSelectorOpIn,
+			Values:   []string{zone},
+		}
+		spec := &template.Spec
+		if spec.Affinity == nil {
+			spec.Affinity = &corev1.Affinity{}
+		}
+		if spec.Affinity.NodeAffinity == nil {
+			spec.Affinity.NodeAffinity = &corev1.NodeAffinity{}
+		}
+		nodeAffinity := spec.Affinity.NodeAffinity
+		if nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+			nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution = &corev1.NodeSelector{}
+		}
+		required := nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+		if len(required.NodeSelectorTerms) == 0 {
+			required.NodeSelectorTerms = []corev1.NodeSelectorTerm{{}}
+		}
+		// nodeSelectorTerms 之间是或的关系，需要把约束加到每一个 term 中
+		for i := range required.NodeSelectorTerms {
+			term := &required.NodeSelectorTerms[i]
+			term.MatchExpressions = append(term.MatchExpressions, requirement)
+		}
+		return
+	}
+
+	// 未指定 zones：按拓扑键均匀打散，模板中已有同一拓扑键的约束时保留用户配置
+	for _, constraint := range template.Spec.TopologySpreadConstraints {
+		if constraint.TopologyKey == key {
+			return
+		}
+	}
+	template.Spec.TopologySpreadConstraints = append(template.Spec.TopologySpreadConstraints,
+		corev1.TopologySpreadConstraint{
+			MaxSkew:           1,
+			TopologyKey:       key,
+			WhenUnsatisfiable: corev1.DoNotSchedule,
+			LabelSelector:     m.Spec.Selector.DeepCopy(),
+		})
+}
+
+// validatePlacement 验证拓扑键和 zones 是合法且不重复的标签值
+func (r *MyStatefulset) validatePlacement() field.ErrorList {
+	var allErrs field.ErrorList
+	if r.Spec.Placement == nil {
+		return allErrs
+	}
+	placementPath := field.NewPath("spec").Child("placement")
+
+	if r.Spec.Placement.TopologyKey != "" {
+		for _, msg := range validation.IsQualifiedName(r.Spec.Placement.TopologyKey) {
+			allErrs = append(allErrs, field.Invalid(placementPath.Child("topologyKey"), r.Spec.Placement.TopologyKey, msg))
+		}
+	}
+
+	seen := make(map[string]bool, len(r.Spec.Placement.Zones))
+	for i, zone := range r.Spec.Placement.Zones {
+		zonePath := placementPath.Child("zones").Index(i)
+		if zone == "" {
+			allErrs = append(allErrs, field.Required(zonePath, ""))
+			continue
+		}
+		for _, msg := range validation.IsValidLabelValue(zone) {
+			allErrs = append(allErrs, field.Invalid(zonePath, zone, msg))
+		}
+		if seen[zone] {
+			allErrs = append(allErrs, field.Duplicate(zonePath, zone))
+		}
+		seen[zone] = true
+	}
+	return allErrs
+}
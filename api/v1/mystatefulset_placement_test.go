@@ -0,0 +1,98 @@
+package v1
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestMyStatefulset_PodTemplateForOrdinal_Placement(t *testing.T) {
+	t.Run("zones pin ordinals round robin", func(t *testing.T) {
+		ms := newPolicyTestMyStatefulset("default", 4)
+		ms.Spec.Placement = &PlacementSpec{Zones: []string{"zone-a", "zone-b", "zone-c"}}
+		// 用户已有的 nodeSelectorTerms 每个都要加上可用区约束
+		ms.Spec.Template.Spec.Affinity = &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{
+					{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "disk", Operator: corev1.NodeSelectorOpIn, Values: []string{"ssd"}}}},
+					{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "disk", Operator: corev1.NodeSelectorOpIn, Values: []string{"nvme"}}}},
+				},
+			},
+		}}
+
+		for ordinal, wantZone := range []string{"zone-a", "zone-b", "zone-c", "zone-a"} {
+			template, err := ms.PodTemplateForOrdinal(int32(ordinal))
+			if err != nil {
+				t.Fatalf("PodTemplateForOrdinal(%d) error = %v", ordinal, err)
+			}
+			terms := template.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+			if len(terms) != 2 {
+				t.Fatalf("PodTemplateForOrdinal(%d) nodeSelectorTerms = %v, want 2 terms", ordinal, terms)
+			}
+			for _, term := range terms {
+				last := term.MatchExpressions[len(term.MatchExpressions)-1]
+				if len(term.MatchExpressions) != 2 || last.Key != corev1.LabelTopologyZone || last.Values[0] != wantZone {
+					t.Errorf("PodTemplateForOrdinal(%d) term = %v, want zone %s", ordinal, term, wantZone)
+				}
+			}
+		}
+		if len(ms.Spec.Template.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[0].MatchExpressions) != 1 {
+			t.Errorf("PodTemplateForOrdinal() must not modify spec.template")
+		}
+	})
+
+	t.Run("no zones adds a spread constraint", func(t *testing.T) {
+		ms := newPolicyTestMyStatefulset("default", 3)
+		ms.Spec.Placement = &PlacementSpec{TopologyKey: "rack"}
+
+		template, err := ms.PodTemplateForOrdinal(1)
+		if err != nil {
+			t.Fatalf("PodTemplateForOrdinal() error = %v", err)
+		}
+		constraints := template.Spec.TopologySpreadConstraints
+		if len(constraints) != 1 || constraints[0].TopologyKey != "rack" || constraints[0].MaxSkew != 1 ||
+			constraints[0].LabelSelector.MatchLabels["app"] != "test" {
+			t.Errorf("PodTemplateForOrdinal() topologySpreadConstraints = %v", constraints)
+		}
+		if template.Spec.Affinity != nil {
+			t.Errorf("PodTemplateForOrdinal() affinity = %v, want none", template.Spec.Affinity)
+		}
+	})
+}
+
+func TestMyStatefulset_validatePlacement(t *testing.T) {
+	tests := []struct {
+		name      string
+		placement *PlacementSpec
+		wantPaths []string
+	}{
+		{name: "unset"},
+		{name: "default topology key", placement: &PlacementSpec{Zones: []string{"zone-a", "zone-b"}}},
+		{
+			name:      "invalid topology key",
+			placement: &PlacementSpec{TopologyKey: "not a key"},
+			wantPaths: []string{"spec.placement.topologyKey"},
+		},
+		{
+			name:      "empty and duplicate zones",
+			placement: &PlacementSpec{Zones: []string{"zone-a", "", "zone-a"}},
+			wantPaths: []string{"spec.placement.zones[1]", "spec.placement.zones[2]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms := newPolicyTestMyStatefulset("default", 3)
+			ms.Spec.Placement = tt.placement
+			errs := ms.validatePlacement()
+			if len(errs) != len(tt.wantPaths) {
+				t.Fatalf("validatePlacement() = %v, want %d errors", errs, len(tt.wantPaths))
+			}
+			for i, err := range errs {
+				if err.Field != tt.wantPaths[i] {
+					t.Errorf("validatePlacement() error path = %s, want %s", err.Field, tt.wantPaths[i])
+				}
+			}
+		})
+	}
+}
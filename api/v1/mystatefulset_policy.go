@@ -0,0 +1,166 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// 默认规则，与引入 MyStatefulsetPolicy 之前硬编码的行为保持一致
+const (
+	defaultMaxReplicas               = int32(100)
+	defaultMaxReplicaIncreasePercent = int32(100)
+	defaultImageChangeFromSubstring  = "prod"
+	defaultImageChangeToSubstring    = "test"
+	policyLoadTimeout                = 5 * time.Second
+)
+
+// policyReader 用于读取 MyStatefulsetPolicy、Namespace 和 PVC，由 SetupWebhookWithManager 注入。
+// 使用 manager 的缓存客户端，策略的增删改无需重启 webhook 即可生效。
+// 为 nil 时（例如单元测试）只使用默认规则。
+var policyReader client.Reader
+
+// admissionRules 是某个命名空间最终生效的准入规则
+type admissionRules struct {
+	MaxReplicas           MaxReplicasRule
+	ReplicaIncrease       ReplicaIncreaseRule
+	ResourceLimitDecrease ResourceLimitDecreaseRule
+	ImageChange           ImageChangeRule
+	DeletionProtection    DeletionProtectionRule
+	Validations           []policyValidation
+}
+
+// policyValidation 记录 CEL 规则及其来源策略，便于在错误信息中定位
+type policyValidation struct {
+	Policy string
+	Rule   ValidationRule
+}
+
+// defaultAdmissionRules 返回没有任何策略时使用的规则
+func defaultAdmissionRules() admissionRules {
+	return admissionRules{
+		MaxReplicas: MaxReplicasRule{
+			Enabled: true,
+			Limit:   defaultMaxReplicas,
+		},
+		ReplicaIncrease: ReplicaIncreaseRule{
+			Enabled:    true,
+			MaxPercent: defaultMaxReplicaIncreasePercent,
+		},
+		ResourceLimitDecrease: ResourceLimitDecreaseRule{
+			Enabled: true,
+		},
+		ImageChange: ImageChangeRule{
+			Enabled:       true,
+			FromSubstring: defaultImageChangeFromSubstring,
+			ToSubstring:   defaultImageChangeToSubstring,
+		},
+	}
+}
+
+// apply 用策略中设置的规则覆盖当前规则，未设置的规则保持不变；CEL 规则会累加
+func (a *admissionRules) apply(policy *MyStatefulsetPolicy) {
+	spec := &policy.Spec
+	if spec.MaxReplicas != nil {
+		a.MaxReplicas = *spec.MaxReplicas
+	}
+	if spec.ReplicaIncrease != nil {
+		a.ReplicaIncrease = *spec.ReplicaIncrease
+	}
+	if spec.ResourceLimitDecrease != nil {
+		a.ResourceLimitDecrease = *spec.ResourceLimitDecrease
+	}
+	if spec.ImageChange != nil {
+		a.ImageChange = *spec.ImageChange
+	}
+	if spec.DeletionProtection != nil {
+		a.DeletionProtection = *spec.DeletionProtection
+	}
+	for _, rule := range spec.Validations {
+		a.Validations = append(a.Validations, policyValidation{Policy: policy.Name, Rule: rule})
+	}
+}
+
+// loadAdmissionRules 读取适用于指定命名空间的策略并合并为最终生效的规则。
+// 合并顺序：默认规则 -> 集群级策略（按名称） -> 命名空间级策略（按名称），后者覆盖前者。
+func loadAdmissionRules(namespace string) (admissionRules, error) {
+	rules := defaultAdmissionRules()
+	if policyReader == nil {
+		return rules, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), policyLoadTimeout)
+	defer cancel()
+
+	var policies MyStatefulsetPolicyList
+	if err := policyReader.List(ctx, &policies); err != nil {
+		return rules, fmt.Errorf("failed to list MyStatefulsetPolicies: %w", err)
+	}
+	if len(policies.Items) == 0 {
+		return rules, nil
+	}
+
+	// 获取命名空间标签，用于匹配 namespaceSelector
+	var namespaceLabels labels.Set
+	ns := &corev1.Namespace{}
+	if err := policyReader.Get(ctx, types.NamespacedName{Name: namespace}, ns); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return rules, fmt.Errorf("failed to get namespace %s: %w", namespace, err)
+		}
+	} else {
+		namespaceLabels = ns.Labels
+	}
+
+	var clusterPolicies, namespacePolicies []MyStatefulsetPolicy
+	for _, policy := range policies.Items {
+		if policy.Spec.NamespaceSelector == nil {
+			clusterPolicies = append(clusterPolicies, policy)
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(policy.Spec.NamespaceSelector)
+		if err != nil {
+			mystatefulsetlog.Error(err, "ignoring policy with invalid namespaceSelector", "policy", policy.Name)
+			continue
+		}
+		if selector.Matches(namespaceLabels) {
+			namespacePolicies = append(namespacePolicies, policy)
+		}
+	}
+
+	for _, group := range [][]MyStatefulsetPolicy{clusterPolicies, namespacePolicies} {
+		sort.Slice(group, func(i, j int) bool {
+			return group[i].Name < group[j].Name
+		})
+		for i := range group {
+			rules.apply(&group[i])
+		}
+	}
+
+	return rules, nil
+}
+
+// exceedsIncrease 判断副本数的增长是否超过允许的比例，从 0 扩容不受限制
+func (r ReplicaIncreaseRule) exceedsIncrease(oldReplicas, newReplicas int32) bool {
+	if !r.Enabled || oldReplicas <= 0 || newReplicas <= oldReplicas {
+		return false
+	}
+	allowed := int64(oldReplicas) + int64(oldReplicas)*int64(r.MaxPercent)/100
+	return int64(newReplicas) > allowed
+}
+
+// forbids 判断镜像更新是否被规则禁止
+func (r ImageChangeRule) forbids(oldImage, newImage string) bool {
+	if !r.Enabled || r.FromSubstring == "" || r.ToSubstring == "" {
+		return false
+	}
+	return strings.Contains(oldImage, r.FromSubstring) && strings.Contains(newImage, r.ToSubstring)
+}
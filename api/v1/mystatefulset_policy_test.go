@@ -0,0 +1,264 @@
+package v1
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// withPolicyReader 在测试期间替换 policyReader
+func withPolicyReader(t *testing.T, objs ...client.Object) {
+	t.Helper()
+
+	s := runtime.NewScheme()
+	_ = AddToScheme(s)
+	_ = corev1.AddToScheme(s)
+
+	old := policyReader
+	policyReader = fake.NewClientBuilder().WithScheme(s).WithObjects(objs...).Build()
+	t.Cleanup(func() {
+		policyReader = old
+	})
+}
+
+func newPolicyTestMyStatefulset(namespace string, replicas int32) *MyStatefulset {
+	return &MyStatefulset{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-mystatefulset",
+			Namespace: namespace,
+		},
+		Spec: MyStatefulsetSpec{
+			Replicas:    pointer.Int32(replicas),
+			ServiceName: "test-service",
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"app": "test",
+				},
+			},
+			Template: PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						"app": "test",
+					},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:  "nginx",
+							Image: "nginx:prod",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestLoadAdmissionRules(t *testing.T) {
+	devNamespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "dev",
+			Labels: map[string]string{"env": "dev"},
+		},
+	}
+	prodNamespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "prod",
+			Labels: map[string]string{"env": "prod"},
+		},
+	}
+	clusterPolicy := &MyStatefulsetPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Spec: MyStatefulsetPolicySpec{
+			MaxReplicas: &MaxReplicasRule{Enabled: true, Limit: 20},
+		},
+	}
+	devPolicy := &MyStatefulsetPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "dev"},
+		Spec: MyStatefulsetPolicySpec{
+			NamespaceSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"env": "dev"},
+			},
+			MaxReplicas:     &MaxReplicasRule{Enabled: false},
+			ReplicaIncrease: &ReplicaIncreaseRule{Enabled: true, MaxPercent: 50},
+		},
+	}
+
+	withPolicyReader(t, devNamespace, prodNamespace, clusterPolicy, devPolicy)
+
+	tests := []struct {
+		name      string
+		namespace string
+		want      admissionRules
+	}{
+		{
+			name:      "cluster-wide policy only",
+			namespace: "prod",
+			want: func() admissionRules {
+				rules := defaultAdmissionRules()
+				rules.MaxReplicas = MaxReplicasRule{Enabled: true, Limit: 20}
+				return rules
+			}(),
+		},
+		{
+			name:      "namespace policy overrides cluster policy",
+			namespace: "dev",
+			want: func() admissionRules {
+				rules := defaultAdmissionRules()
+				rules.MaxReplicas = MaxReplicasRule{Enabled: false}
+				rules.ReplicaIncrease = ReplicaIncreaseRule{Enabled: true, MaxPercent: 50}
+				return rules
+			}(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := loadAdmissionRules(tt.namespace)
+			if err != nil {
+				t.Fatalf("loadAdmissionRules() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("loadAdmissionRules() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMyStatefulset_ValidateWithPolicy(t *testing.T) {
+	devNamespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "dev",
+			Labels: map[string]string{"env": "dev"},
+		},
+	}
+	devPolicy := &MyStatefulsetPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "dev"},
+		Spec: MyStatefulsetPolicySpec{
+			NamespaceSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"env": "dev"},
+			},
+			MaxReplicas:     &MaxReplicasRule{Enabled: true, Limit: 5},
+			ReplicaIncrease: &ReplicaIncreaseRule{Enabled: false},
+			ImageChange:     &ImageChangeRule{Enabled: false},
+		},
+	}
+
+	withPolicyReader(t, devNamespace, devPolicy)
+
+	// 命名空间策略将最大副本数限制为 5
+	if err := newPolicyTestMyStatefulset("dev", 6).ValidateCreate(); err == nil {
+		t.Errorf("ValidateCreate() expected error for replicas above policy limit")
+	}
+	// 未匹配策略的命名空间仍使用默认上限 100
+	if err := newPolicyTestMyStatefulset("default", 6).ValidateCreate(); err != nil {
+		t.Errorf("ValidateCreate() unexpected error = %v", err)
+	}
+
+	// 命名空间策略关闭了副本增长和镜像规则
+	oldMs := newPolicyTestMyStatefulset("dev", 1)
+	newMs := newPolicyTestMyStatefulset("dev", 4)
+	newMs.Spec.Template.Spec.Containers[0].Image = "nginx:test"
+	if err := newMs.ValidateUpdate(oldMs); err != nil {
+		t.Errorf("ValidateUpdate() unexpected error = %v", err)
+	}
+
+	// 默认规则下同样的更新会被拒绝
+	oldMs = newPolicyTestMyStatefulset("default", 1)
+	newMs = newPolicyTestMyStatefulset("default", 4)
+	newMs.Spec.Template.Spec.Containers[0].Image = "nginx:test"
+	if err := newMs.ValidateUpdate(oldMs); err == nil {
+		t.Errorf("ValidateUpdate() expected error under default rules")
+	}
+}
+
+func TestMyStatefulset_ValidateUpdateSkipsPolicy(t *testing.T) {
+	// 策略将最大副本数收紧到已有对象之下，并包含一条写错的 CEL 规则
+	policy := &MyStatefulsetPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "tightened"},
+		Spec: MyStatefulsetPolicySpec{
+			MaxReplicas: &MaxReplicasRule{Enabled: true, Limit: 2},
+			Validations: []ValidationRule{{Expression: "self.spec.replicas <"}},
+		},
+	}
+	withPolicyReader(t, policy)
+
+	oldMs := newPolicyTestMyStatefulset("default", 5)
+
+	tests := []struct {
+		name    string
+		mutate  func(ms *MyStatefulset)
+		wantErr bool
+	}{
+		{
+			name: "finalizer added",
+			mutate: func(ms *MyStatefulset) {
+				ms.Finalizers = []string{"apps.mystatefulset.com/finalizer"}
+			},
+		},
+		{
+			name: "deleting object",
+			mutate: func(ms *MyStatefulset) {
+				now := metav1.Now()
+				ms.DeletionTimestamp = &now
+				ms.Finalizers = nil
+			},
+		},
+		{
+			name: "deleting object with spec changed",
+			mutate: func(ms *MyStatefulset) {
+				now := metav1.Now()
+				ms.DeletionTimestamp = &now
+				ms.Spec.Replicas = pointer.Int32(4)
+			},
+		},
+		{
+			name: "deleting object with selector changed",
+			mutate: func(ms *MyStatefulset) {
+				now := metav1.Now()
+				ms.DeletionTimestamp = &now
+				ms.Spec.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{"app": "other"}}
+			},
+			wantErr: true,
+		},
+		{
+			name: "deleting object with app label changed",
+			mutate: func(ms *MyStatefulset) {
+				now := metav1.Now()
+				ms.DeletionTimestamp = &now
+				ms.Labels = map[string]string{"app": "other"}
+			},
+			wantErr: true,
+		},
+		{
+			name: "app label changed",
+			mutate: func(ms *MyStatefulset) {
+				ms.Labels = map[string]string{"app": "other"}
+			},
+			wantErr: true,
+		},
+		{
+			name: "spec changed",
+			mutate: func(ms *MyStatefulset) {
+				ms.Spec.Replicas = pointer.Int32(4)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newMs := oldMs.DeepCopy()
+			tt.mutate(newMs)
+			if err := newMs.ValidateUpdate(oldMs); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateUpdate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package v1
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	// 控制器镜像中可能没有时区数据库，内嵌一份以支持 scalingSchedule 的 timeZone
+	_ "time/tzdata"
+
+	"github.com/robfig/cron/v3"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// cronSearchYears 查找上一次触发时间的最大范围，与 cron 库查找下一次触发时间的范围一致
+const cronSearchYears = 5
+
+// scheduleStepLimit 校验副本数增幅时最多模拟的边界数
+const scheduleStepLimit = 1000
+
+// parseSchedule 解析标准的 5 段 cron 表达式（分 时 日 月 周），支持 @daily 等宏
+func parseSchedule(expr string) (cron.Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	// 时区由 timeZone 指定
+	if strings.HasPrefix(expr, "TZ=") || strings.HasPrefix(expr, "CRON_TZ=") {
+		return nil, fmt.Errorf("time zone prefix is not supported, use timeZone instead")
+	}
+	schedule, err := cron.ParseStandard(expr)
+	if err != nil {
+		return nil, err
+	}
+	// @every 的触发时间取决于起点，无法推出上一次边界
+	if _, ok := schedule.(cron.ConstantDelaySchedule); ok {
+		return nil, fmt.Errorf("@every is not supported")
+	}
+	return schedule, nil
+}
+
+// prevBoundary 返回不晚于 t 的最后一个触发时间。cron 库只提供 Next，
+// 这里从 t 往前成倍扩大窗口，找到窗口内的第一个触发时间后再用 Next 逐个前进到 t
+func prevBoundary(schedule cron.Schedule, t time.Time) (time.Time, bool) {
+	limit := t.AddDate(-cronSearchYears, 0, 0)
+	for lookback := time.Minute; ; lookback *= 2 {
+		from := t.Add(-lookback)
+		if from.Before(limit) {
+			from = limit
+		}
+		// Next 返回严格晚于参数的时间，退一秒使 from 本身也能命中
+		prev := schedule.Next(from.Add(-time.Second))
+		if !prev.IsZero() && !prev.After(t) {
+			for next := schedule.Next(prev); !next.IsZero() && !next.After(t); next = schedule.Next(next) {
+				prev = next
+			}
+			return prev, true
+		}
+		if !from.After(limit) {
+			return time.Time{}, false
+		}
+	}
+}
+
+// GetTimeZone 返回 timeZone 对应的时区，未设置时为 UTC
+func (s *ScheduledScaling) GetTimeZone() (*time.Location, error) {
+	if s.TimeZone == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(s.TimeZone)
+}
+
+// DisplayName 返回条目在状态和事件中显示的名称，未设置 name 时使用 cron 表达式
+func (s *ScheduledScaling) DisplayName() string {
+	if s.Name != "" {
+		return s.Name
+	}
+	return s.Schedule
+}
+
+// ScalingBoundary is a point in time at which a scalingSchedule entry sets
+// the replicas.
+// +kubebuilder:object:generate=false
+type ScalingBoundary struct {
+	Entry    string
+	Replicas int32
+	Time     time.Time
+}
+
+// ScalingScheduleBoundaries 返回不晚于 now 的最后一个边界和 now 之后的下一个边界。
+// 多个条目在同一时刻触发时，列表中靠后的条目生效
+func (m *MyStatefulset) ScalingScheduleBoundaries(now time.Time) (last, next *ScalingBoundary, err error) {
+	for i := range m.Spec.ScalingSchedule {
+		entry := &m.Spec.ScalingSchedule[i]
+		schedule, err := parseSchedule(entry.Schedule)
+		if err != nil {
+			return nil, nil, fmt.Errorf("spec.scalingSchedule[%d].schedule: %w", i, err)
+		}
+		loc, err := entry.GetTimeZone()
+		if err != nil {
+			return nil, nil, fmt.Errorf("spec.scalingSchedule[%d].timeZone: %w", i, err)
+		}
+
+		local := now.In(loc)
+		if t, ok := prevBoundary(schedule, local); ok && (last == nil || !t.Before(last.Time)) {
+			last = &ScalingBoundary{Entry: entry.DisplayName(), Replicas: entry.Replicas, Time: t}
+		}
+		if t := schedule.Next(local); !t.IsZero() && (next == nil || !t.After(next.Time)) {
+			next = &ScalingBoundary{Entry: entry.DisplayName(), Replicas: entry.Replicas, Time: t}
+		}
+	}
+	return last, next, nil
+}
+
+// validateScalingSchedule 校验 cron 表达式、时区和副本数
+func (r *MyStatefulset) validateScalingSchedule() field.ErrorList {
+	var allErrs field.ErrorList
+	schedulePath := field.NewPath("spec").Child("scalingSchedule")
+
+	names := make(map[string]bool, len(r.Spec.ScalingSchedule))
+	for i, entry := range r.Spec.ScalingSchedule {
+		entryPath := schedulePath.Index(i)
+
+		if entry.Name != "" {
+			if names[entry.Name] {
+				allErrs = append(allErrs, field.Duplicate(entryPath.Child("name"), entry.Name))
+			}
+			names[entry.Name] = true
+		}
+
+		if entry.Replicas < minReplicas {
+			allErrs = append(allErrs, field.Invalid(entryPath.Child("replicas"), entry.Replicas,
+				fmt.Sprintf("must be greater than or equal to %d", minReplicas)))
+		}
+
+		loc, err := entry.GetTimeZone()
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(entryPath.Child("timeZone"), entry.TimeZone, err.Error()))
+			loc = time.UTC
+		}
+
+		if entry.Schedule == "" {
+			allErrs = append(allErrs, field.Required(entryPath.Child("schedule"), ""))
+			continue
+		}
+		schedule, err := parseSchedule(entry.Schedule)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(entryPath.Child("schedule"), entry.Schedule, err.Error()))
+			continue
+		}
+		// 例如 2 月 30 日
+		if schedule.Next(time.Now().In(loc)).IsZero() {
+			allErrs = append(allErrs, field.Invalid(entryPath.Child("schedule"), entry.Schedule,
+				fmt.Sprintf("never fires within %d years", cronSearchYears)))
+		}
+	}
+	return allErrs
+}
+
+// scheduledReplicaSteps 模拟 now 之后一年内（最多 scheduleStepLimit 个边界）scalingSchedule 对副本数的修改，
+// 返回去重后的 [修改前, 修改后]。第一步从当前的 spec.replicas 开始，与新增计划时立即应用当前窗口一致
+func (m *MyStatefulset) scheduledReplicaSteps(now time.Time) ([][2]int32, error) {
+	last, _, err := m.ScalingScheduleBoundaries(now)
+	if err != nil {
+		return nil, err
+	}
+
+	var steps [][2]int32
+	seen := make(map[[2]int32]bool)
+	add := func(from, to int32) {
+		step := [2]int32{from, to}
+		if from != to && !seen[step] {
+			seen[step] = true
+			steps = append(steps, step)
+		}
+	}
+
+	from := m.GetReplicas()
+	if last != nil {
+		add(from, last.Replicas)
+		from = last.Replicas
+	}
+	limit := now.AddDate(1, 0, 0)
+	for i, t := 0, now; i < scheduleStepLimit; i++ {
+		_, next, err := m.ScalingScheduleBoundaries(t)
+		if err != nil {
+			return nil, err
+		}
+		if next == nil || next.Time.After(limit) {
+			break
+		}
+		add(from, next.Replicas)
+		from, t = next.Replicas, next.Time
+	}
+	return steps, nil
+}
+
+// validateScalingScheduleSteps 校验计划对副本数的每次修改都不超过策略允许的增幅，
+// 否则控制器写入 spec.replicas 时会被准入 webhook 拒绝
+func (r *MyStatefulset) validateScalingScheduleSteps(rule ReplicaIncreaseRule) field.ErrorList {
+	if !rule.Enabled || len(r.Spec.ScalingSchedule) == 0 {
+		return nil
+	}
+	// 非法的表达式和时区已由 validateScalingSchedule 报告
+	steps, err := r.scheduledReplicaSteps(time.Now())
+	if err != nil {
+		return nil
+	}
+
+	var allErrs field.ErrorList
+	for _, step := range steps {
+		if rule.exceedsIncrease(step[0], step[1]) {
+			allErrs = append(allErrs, field.Forbidden(field.NewPath("spec").Child("scalingSchedule"),
+				fmt.Sprintf("scales from %d to %d replicas, more than the %d%% increase allowed in a single update; add intermediate entries",
+					step[0], step[1], rule.MaxPercent)))
+		}
+	}
+	return allErrs
+}
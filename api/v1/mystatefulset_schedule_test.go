@@ -0,0 +1,236 @@
+package v1
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSchedule_NextPrev(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatalf("LoadLocation() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		expr     string
+		from     time.Time
+		wantNext time.Time
+		wantPrev time.Time
+	}{
+		{
+			name:     "weekday mornings",
+			expr:     "0 8 * * MON-FRI",
+			from:     time.Date(2024, 3, 8, 12, 0, 0, 0, time.UTC), // 周五
+			wantNext: time.Date(2024, 3, 11, 8, 0, 0, 0, time.UTC),
+			wantPrev: time.Date(2024, 3, 8, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "step and list",
+			expr:     "*/15 9,17 * * *",
+			from:     time.Date(2024, 3, 8, 9, 50, 30, 0, time.UTC),
+			wantNext: time.Date(2024, 3, 8, 17, 0, 0, 0, time.UTC),
+			wantPrev: time.Date(2024, 3, 8, 9, 45, 0, 0, time.UTC),
+		},
+		{
+			name:     "boundary is inclusive for prev",
+			expr:     "@daily",
+			from:     time.Date(2024, 3, 8, 0, 0, 0, 0, time.UTC),
+			wantNext: time.Date(2024, 3, 9, 0, 0, 0, 0, time.UTC),
+			wantPrev: time.Date(2024, 3, 8, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "leap day",
+			expr:     "0 0 29 2 *",
+			from:     time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			wantNext: time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC),
+			wantPrev: time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "day of month or day of week",
+			expr:     "0 0 1 * SUN",
+			from:     time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC), // 周一
+			wantNext: time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC),
+			wantPrev: time.Date(2024, 3, 3, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "time zone",
+			expr:     "0 20 * * *",
+			from:     time.Date(2024, 3, 8, 20, 30, 0, 0, time.UTC).In(berlin),
+			wantNext: time.Date(2024, 3, 9, 20, 0, 0, 0, berlin),
+			wantPrev: time.Date(2024, 3, 8, 20, 0, 0, 0, berlin),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := parseSchedule(tt.expr)
+			if err != nil {
+				t.Fatalf("parseSchedule(%q) error = %v", tt.expr, err)
+			}
+			if next := schedule.Next(tt.from); !next.Equal(tt.wantNext) {
+				t.Errorf("Next(%v) = %v, want %v", tt.from, next, tt.wantNext)
+			}
+			if prev, ok := prevBoundary(schedule, tt.from); !ok || !prev.Equal(tt.wantPrev) {
+				t.Errorf("prevBoundary(%v) = %v, %v, want %v", tt.from, prev, ok, tt.wantPrev)
+			}
+		})
+	}
+
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "0 8 * * MON-XYZ", "*/0 * * * *", "0 10-8 * * *", "@every 1h", "CRON_TZ=UTC 0 8 * * *"} {
+		if _, err := parseSchedule(expr); err == nil {
+			t.Errorf("parseSchedule(%q) error = nil, want error", expr)
+		}
+	}
+}
+
+func TestMyStatefulset_ScalingScheduleBoundaries(t *testing.T) {
+	ms := newPolicyTestMyStatefulset("default", 3)
+	ms.Spec.ScalingSchedule = []ScheduledScaling{
+		{Name: "work-hours", Schedule: "0 8 * * 1-5", TimeZone: "Europe/Berlin", Replicas: 3},
+		{Name: "night", Schedule: "0 20 * * *", TimeZone: "Europe/Berlin", Replicas: 0},
+	}
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+
+	// 周六中午：最后一个边界是周五 20:00，下一个是周六 20:00
+	last, next, err := ms.ScalingScheduleBoundaries(time.Date(2024, 3, 9, 12, 0, 0, 0, berlin))
+	if err != nil {
+		t.Fatalf("ScalingScheduleBoundaries() error = %v", err)
+	}
+	if last == nil || last.Entry != "night" || last.Replicas != 0 || !last.Time.Equal(time.Date(2024, 3, 8, 20, 0, 0, 0, berlin)) {
+		t.Errorf("ScalingScheduleBoundaries() last = %+v", last)
+	}
+	if next == nil || next.Entry != "night" || next.Replicas != 0 || !next.Time.Equal(time.Date(2024, 3, 9, 20, 0, 0, 0, berlin)) {
+		t.Errorf("ScalingScheduleBoundaries() next = %+v", next)
+	}
+
+	// 周日 20:00 之后，下一个边界是周一 08:00
+	_, next, err = ms.ScalingScheduleBoundaries(time.Date(2024, 3, 10, 21, 0, 0, 0, berlin))
+	if err != nil {
+		t.Fatalf("ScalingScheduleBoundaries() error = %v", err)
+	}
+	if next == nil || next.Entry != "work-hours" || next.Replicas != 3 || !next.Time.Equal(time.Date(2024, 3, 11, 8, 0, 0, 0, berlin)) {
+		t.Errorf("ScalingScheduleBoundaries() next = %+v", next)
+	}
+
+	// 同一时刻触发时靠后的条目生效
+	ms.Spec.ScalingSchedule = append(ms.Spec.ScalingSchedule, ScheduledScaling{Schedule: "0 20 * * 5", TimeZone: "Europe/Berlin", Replicas: 1})
+	last, _, err = ms.ScalingScheduleBoundaries(time.Date(2024, 3, 9, 12, 0, 0, 0, berlin))
+	if err != nil {
+		t.Fatalf("ScalingScheduleBoundaries() error = %v", err)
+	}
+	if last.Entry != "0 20 * * 5" || last.Replicas != 1 {
+		t.Errorf("ScalingScheduleBoundaries() last = %+v, want the later entry", last)
+	}
+}
+
+func TestMyStatefulset_validateScalingSchedule(t *testing.T) {
+	tests := []struct {
+		name      string
+		schedule  []ScheduledScaling
+		wantPaths []string
+	}{
+		{name: "unset"},
+		{
+			name: "valid",
+			schedule: []ScheduledScaling{
+				{Name: "day", Schedule: "0 8 * * MON-FRI", TimeZone: "Asia/Shanghai", Replicas: 3},
+				{Name: "night", Schedule: "@daily", Replicas: 0},
+			},
+		},
+		{
+			name:      "invalid schedule and time zone",
+			schedule:  []ScheduledScaling{{Schedule: "0 25 * * *", TimeZone: "Mars/Base", Replicas: 1}},
+			wantPaths: []string{"spec.scalingSchedule[0].timeZone", "spec.scalingSchedule[0].schedule"},
+		},
+		{
+			name:      "never fires",
+			schedule:  []ScheduledScaling{{Schedule: "0 0 30 2 *", Replicas: 1}},
+			wantPaths: []string{"spec.scalingSchedule[0].schedule"},
+		},
+		{
+			name: "missing schedule, negative replicas and duplicate name",
+			schedule: []ScheduledScaling{
+				{Name: "day", Schedule: "@daily", Replicas: 1},
+				{Name: "day", Replicas: -1},
+			},
+			wantPaths: []string{"spec.scalingSchedule[1].name", "spec.scalingSchedule[1].replicas", "spec.scalingSchedule[1].schedule"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms := newPolicyTestMyStatefulset("default", 3)
+			ms.Spec.ScalingSchedule = tt.schedule
+			errs := ms.validateScalingSchedule()
+			if len(errs) != len(tt.wantPaths) {
+				t.Fatalf("validateScalingSchedule() = %v, want %d errors", errs, len(tt.wantPaths))
+			}
+			for i, err := range errs {
+				if err.Field != tt.wantPaths[i] {
+					t.Errorf("validateScalingSchedule() error path = %s, want %s", err.Field, tt.wantPaths[i])
+				}
+			}
+		})
+	}
+}
+
+func TestMyStatefulset_validateScalingScheduleSteps(t *testing.T) {
+	tests := []struct {
+		name     string
+		replicas int32
+		schedule []ScheduledScaling
+		rule     ReplicaIncreaseRule
+		wantErrs int
+	}{
+		{
+			name:     "steps within the limit",
+			replicas: 4,
+			schedule: []ScheduledScaling{
+				{Name: "morning", Schedule: "0 7 * * *", Replicas: 4},
+				{Name: "day", Schedule: "0 9 * * *", Replicas: 8},
+				{Name: "night", Schedule: "0 22 * * *", Replicas: 2},
+			},
+			rule: ReplicaIncreaseRule{Enabled: true, MaxPercent: 100},
+		},
+		{
+			name:     "jump exceeds the limit",
+			replicas: 2,
+			schedule: []ScheduledScaling{
+				{Name: "day", Schedule: "0 8 * * *", Replicas: 10},
+				{Name: "night", Schedule: "0 20 * * *", Replicas: 2},
+			},
+			rule:     ReplicaIncreaseRule{Enabled: true, MaxPercent: 100},
+			wantErrs: 1,
+		},
+		{
+			name:     "scaling up from zero is not limited",
+			replicas: 0,
+			schedule: []ScheduledScaling{
+				{Name: "day", Schedule: "0 8 * * *", Replicas: 10},
+				{Name: "night", Schedule: "0 20 * * *", Replicas: 0},
+			},
+			rule: ReplicaIncreaseRule{Enabled: true, MaxPercent: 100},
+		},
+		{
+			name:     "rule disabled",
+			replicas: 2,
+			schedule: []ScheduledScaling{
+				{Name: "day", Schedule: "0 8 * * *", Replicas: 10},
+				{Name: "night", Schedule: "0 20 * * *", Replicas: 2},
+			},
+			rule: ReplicaIncreaseRule{Enabled: false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms := newPolicyTestMyStatefulset("default", tt.replicas)
+			ms.Spec.ScalingSchedule = tt.schedule
+			errs := ms.validateScalingScheduleSteps(tt.rule)
+			if len(errs) != tt.wantErrs {
+				t.Errorf("validateScalingScheduleSteps() = %v, want %d errors", errs, tt.wantErrs)
+			}
+		})
+	}
+}
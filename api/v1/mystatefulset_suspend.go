@@ -0,0 +1,59 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// ConditionSuspended is True while spec.suspend is set and False while the
+// pods are being recreated after a resume.
+const ConditionSuspended = "Suspended"
+
+const scaleWebhookPath = "/validate-apps-mystatefulset-com-v1-mystatefulset-scale"
+
+//+kubebuilder:webhook:path=/validate-apps-mystatefulset-com-v1-mystatefulset-scale,mutating=false,failurePolicy=fail,sideEffects=None,groups=apps.mystatefulset.com,resources=mystatefulsets/scale,verbs=update,versions=v1,name=vmystatefulsetscale.kb.io,admissionReviewVersions=v1
+
+// scaleHandler 拒绝暂停期间通过 scale 子资源修改副本数（kubectl scale、HPA 等），
+// 否则恢复后的副本数会与暂停前不一致
+type scaleHandler struct{}
+
+// Handle 在副本数变化且 MyStatefulset 处于暂停状态时拒绝请求
+func (h *scaleHandler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Operation != admissionv1.Update {
+		return admission.Allowed("")
+	}
+
+	scale, oldScale := &autoscalingv1.Scale{}, &autoscalingv1.Scale{}
+	if err := json.Unmarshal(req.Object.Raw, scale); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if err := json.Unmarshal(req.OldObject.Raw, oldScale); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if scale.Spec.Replicas == oldScale.Spec.Replicas || policyReader == nil {
+		return admission.Allowed("")
+	}
+
+	// scale 对象中没有 spec.suspend，需要读取 MyStatefulset 本身
+	ms := &MyStatefulset{}
+	if err := policyReader.Get(ctx, types.NamespacedName{Name: req.Name, Namespace: req.Namespace}, ms); err != nil {
+		if apierrors.IsNotFound(err) {
+			return admission.Allowed("")
+		}
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	if ms.Spec.Suspend {
+		return admission.Denied(fmt.Sprintf(
+			"cannot scale MyStatefulset %s from %d to %d replicas while spec.suspend is true; resume it first",
+			req.Name, oldScale.Spec.Replicas, scale.Spec.Replicas))
+	}
+	return admission.Allowed("")
+}
@@ -0,0 +1,53 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func TestScaleHandler_Handle(t *testing.T) {
+	suspended := newPolicyTestMyStatefulset("default", 3)
+	suspended.Name = "suspended"
+	suspended.Spec.Suspend = true
+	running := newPolicyTestMyStatefulset("default", 3)
+	running.Name = "running"
+	withPolicyReader(t, suspended, running)
+
+	request := func(name string, oldReplicas, replicas int32) admission.Request {
+		req := admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Update, Name: name, Namespace: "default",
+		}}
+		req.Object.Raw, _ = json.Marshal(&autoscalingv1.Scale{
+			ObjectMeta: metav1.ObjectMeta{Name: name}, Spec: autoscalingv1.ScaleSpec{Replicas: replicas}})
+		req.OldObject.Raw, _ = json.Marshal(&autoscalingv1.Scale{
+			ObjectMeta: metav1.ObjectMeta{Name: name}, Spec: autoscalingv1.ScaleSpec{Replicas: oldReplicas}})
+		return req
+	}
+
+	tests := []struct {
+		name        string
+		req         admission.Request
+		wantAllowed bool
+	}{
+		{name: "scale suspended", req: request("suspended", 3, 5)},
+		{name: "unchanged replicas while suspended", req: request("suspended", 3, 3), wantAllowed: true},
+		{name: "scale running", req: request("running", 3, 5), wantAllowed: true},
+		{name: "not found", req: request("missing", 3, 5), wantAllowed: true},
+	}
+
+	h := &scaleHandler{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := h.Handle(context.Background(), tt.req)
+			if resp.Allowed != tt.wantAllowed {
+				t.Errorf("Handle() allowed = %v, want %v (%v)", resp.Allowed, tt.wantAllowed, resp.Result)
+			}
+		})
+	}
+}
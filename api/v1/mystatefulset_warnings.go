@@ -0,0 +1,147 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const validatingWebhookPath = "/validate-apps-mystatefulset-com-v1-mystatefulset"
+
+// validatingHandler 处理 MyStatefulset 的校验请求。
+// controller-runtime v0.12 的 Validator 和 CustomValidator 只能返回 error，
+// 这里自行实现 admission.Handler，在允许的同时返回警告，kubectl 会将其打印出来。
+type validatingHandler struct {
+	decoder *admission.Decoder
+}
+
+var _ admission.DecoderInjector = &validatingHandler{}
+
+// InjectDecoder 由 webhook server 注入解码器
+func (h *validatingHandler) InjectDecoder(d *admission.Decoder) error {
+	h.decoder = d
+	return nil
+}
+
+// Handle 调用 ValidateCreate/ValidateUpdate/ValidateDelete，并附加警告
+func (h *validatingHandler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	obj := &MyStatefulset{}
+	var err error
+	var warnings []string
+
+	switch req.Operation {
+	case admissionv1.Create:
+		if err := h.decoder.Decode(req, obj); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		err = obj.ValidateCreate()
+		warnings = obj.warnings(nil)
+	case admissionv1.Update:
+		oldObj := &MyStatefulset{}
+		if err := h.decoder.DecodeRaw(req.Object, obj); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		if err := h.decoder.DecodeRaw(req.OldObject, oldObj); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		err = obj.ValidateUpdate(oldObj)
+		warnings = obj.warnings(oldObj)
+	case admissionv1.Delete:
+		// 删除请求中被删除的对象在 OldObject 中
+		if err := h.decoder.DecodeRaw(req.OldObject, obj); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		err = obj.ValidateDelete()
+	}
+
+	if err != nil {
+		var apiStatus apierrors.APIStatus
+		if errors.As(err, &apiStatus) {
+			status := apiStatus.Status()
+			return admission.Response{
+				AdmissionResponse: admissionv1.AdmissionResponse{
+					Allowed: false,
+					Result:  &status,
+				},
+			}.WithWarnings(warnings...)
+		}
+		return admission.Denied(err.Error()).WithWarnings(warnings...)
+	}
+	return admission.Allowed("").WithWarnings(warnings...)
+}
+
+// warnings 返回允许但有风险的配置，old 为 nil 表示创建
+func (r *MyStatefulset) warnings(old *MyStatefulset) []string {
+	var warnings []string
+	specPath := field.NewPath("spec")
+	replicas := r.GetReplicas()
+
+	// 缩容时 PVC 会被保留
+	if old != nil && len(r.Spec.VolumeClaimTemplates) > 0 && replicas < old.GetReplicas() {
+		warnings = append(warnings, fmt.Sprintf(
+			"%s: scaling down from %d to %d replicas retains the PersistentVolumeClaims of the removed pods; delete them manually if the data is no longer needed",
+			specPath.Child("replicas"), old.GetReplicas(), replicas))
+	}
+
+	// 使用 latest 标签的镜像
+	podSpecPath := specPath.Child("template").Child("spec")
+	for i, container := range r.Spec.Template.Spec.InitContainers {
+		if usesLatestTag(container.Image) {
+			warnings = append(warnings, latestTagWarning(podSpecPath.Child("initContainers").Index(i).Child("image"), container.Image))
+		}
+	}
+	for i, container := range r.Spec.Template.Spec.Containers {
+		if usesLatestTag(container.Image) {
+			warnings = append(warnings, latestTagWarning(podSpecPath.Child("containers").Index(i).Child("image"), container.Image))
+		}
+	}
+
+	// 设置了 minReadySeconds 但没有 readinessProbe
+	if r.Spec.MinReadySeconds > 0 {
+		for i, container := range r.Spec.Template.Spec.Containers {
+			if container.ReadinessProbe == nil {
+				warnings = append(warnings, fmt.Sprintf(
+					"%s: container %q has no readinessProbe, so spec.minReadySeconds is counted from container start rather than from readiness",
+					podSpecPath.Child("containers").Index(i).Child("readinessProbe"), container.Name))
+			}
+		}
+	}
+
+	// partition 大于副本数时不会更新任何 Pod
+	if rollingUpdate := r.Spec.UpdateStrategy.RollingUpdate; rollingUpdate != nil &&
+		rollingUpdate.Partition != nil && *rollingUpdate.Partition > replicas {
+		warnings = append(warnings, fmt.Sprintf(
+			"%s: partition %d is greater than spec.replicas %d; no pods will be updated",
+			specPath.Child("updateStrategy").Child("rollingUpdate").Child("partition"), *rollingUpdate.Partition, replicas))
+	}
+
+	// 开启了快照但没有 PVC 可以备份
+	if policy := r.Spec.SnapshotPolicy; policy != nil && policy.Enabled && len(r.Spec.VolumeClaimTemplates) == 0 {
+		warnings = append(warnings, fmt.Sprintf(
+			"%s: snapshots are enabled but spec.volumeClaimTemplates is empty; nothing will be snapshotted",
+			specPath.Child("snapshotPolicy").Child("enabled")))
+	}
+
+	return warnings
+}
+
+func latestTagWarning(path *field.Path, image string) string {
+	return fmt.Sprintf("%s: image %q uses the latest tag; pin a specific tag or digest so that pods are reproducible", path, image)
+}
+
+// usesLatestTag 判断镜像是否使用 latest 标签，未指定标签和 digest 时等同于 latest
+func usesLatestTag(image string) bool {
+	if image == "" || strings.Contains(image, "@") {
+		return false
+	}
+	name := image[strings.LastIndex(image, "/")+1:]
+	i := strings.LastIndex(name, ":")
+	return i < 0 || name[i+1:] == "latest"
+}
@@ -0,0 +1,172 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func TestMyStatefulset_warnings(t *testing.T) {
+	withClaims := func(ms *MyStatefulset) *MyStatefulset {
+		ms.Spec.VolumeClaimTemplates = []corev1.PersistentVolumeClaim{
+			{ObjectMeta: metav1.ObjectMeta{Name: "www"}},
+		}
+		return ms
+	}
+
+	tests := []struct {
+		name         string
+		newMs        func() *MyStatefulset
+		oldMs        *MyStatefulset
+		wantContains []string
+	}{
+		{
+			name:  "pinned image has no warnings",
+			newMs: func() *MyStatefulset { return newPolicyTestMyStatefulset("default", 3) },
+		},
+		{
+			name: "latest and untagged images",
+			newMs: func() *MyStatefulset {
+				ms := newPolicyTestMyStatefulset("default", 3)
+				ms.Spec.Template.Spec.InitContainers = []corev1.Container{{Name: "init", Image: "busybox"}}
+				ms.Spec.Template.Spec.Containers[0].Image = "registry:5000/nginx:latest"
+				return ms
+			},
+			wantContains: []string{
+				"spec.template.spec.initContainers[0].image",
+				"spec.template.spec.containers[0].image",
+			},
+		},
+		{
+			name: "minReadySeconds without readinessProbe",
+			newMs: func() *MyStatefulset {
+				ms := newPolicyTestMyStatefulset("default", 3)
+				ms.Spec.MinReadySeconds = 10
+				return ms
+			},
+			wantContains: []string{"spec.template.spec.containers[0].readinessProbe"},
+		},
+		{
+			name: "partition greater than replicas",
+			newMs: func() *MyStatefulset {
+				ms := newPolicyTestMyStatefulset("default", 3)
+				ms.Spec.UpdateStrategy.RollingUpdate = &RollingUpdateStatefulSetStrategy{Partition: pointer.Int32(5)}
+				return ms
+			},
+			wantContains: []string{"spec.updateStrategy.rollingUpdate.partition"},
+		},
+		{
+			name: "snapshots without PVCs",
+			newMs: func() *MyStatefulset {
+				ms := newPolicyTestMyStatefulset("default", 3)
+				ms.Spec.SnapshotPolicy = &SnapshotPolicy{Enabled: true}
+				return ms
+			},
+			wantContains: []string{"spec.snapshotPolicy.enabled"},
+		},
+		{
+			name: "snapshots with PVCs",
+			newMs: func() *MyStatefulset {
+				ms := withClaims(newPolicyTestMyStatefulset("default", 3))
+				ms.Spec.SnapshotPolicy = &SnapshotPolicy{Enabled: true}
+				return ms
+			},
+		},
+		{
+			name:         "scale down retains PVCs",
+			newMs:        func() *MyStatefulset { return withClaims(newPolicyTestMyStatefulset("default", 1)) },
+			oldMs:        withClaims(newPolicyTestMyStatefulset("default", 3)),
+			wantContains: []string{"spec.replicas: scaling down from 3 to 1"},
+		},
+		{
+			name:  "scale down without PVCs",
+			newMs: func() *MyStatefulset { return newPolicyTestMyStatefulset("default", 1) },
+			oldMs: newPolicyTestMyStatefulset("default", 3),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warnings := tt.newMs().warnings(tt.oldMs)
+			if len(warnings) != len(tt.wantContains) {
+				t.Fatalf("warnings() = %v, want %d warnings", warnings, len(tt.wantContains))
+			}
+			for i, want := range tt.wantContains {
+				if !strings.Contains(warnings[i], want) {
+					t.Errorf("warnings()[%d] = %q, want it to contain %q", i, warnings[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestUsesLatestTag(t *testing.T) {
+	tests := map[string]bool{
+		"nginx":                      true,
+		"nginx:latest":               true,
+		"localhost:5000/nginx":       true,
+		"nginx:1.25":                 false,
+		"localhost:5000/nginx:1.25":  false,
+		"nginx@sha256:0123456789abc": false,
+	}
+	for image, want := range tests {
+		if got := usesLatestTag(image); got != want {
+			t.Errorf("usesLatestTag(%q) = %v, want %v", image, got, want)
+		}
+	}
+}
+
+func TestValidatingHandler_Handle(t *testing.T) {
+	s := runtime.NewScheme()
+	_ = AddToScheme(s)
+	decoder, err := admission.NewDecoder(s)
+	if err != nil {
+		t.Fatalf("NewDecoder() error = %v", err)
+	}
+	h := &validatingHandler{}
+	_ = h.InjectDecoder(decoder)
+
+	request := func(operation admissionv1.Operation, obj, old *MyStatefulset) admission.Request {
+		req := admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{Operation: operation}}
+		req.Object.Raw, _ = json.Marshal(obj)
+		if old != nil {
+			req.OldObject.Raw, _ = json.Marshal(old)
+		}
+		return req
+	}
+
+	// 允许但返回警告
+	ms := newPolicyTestMyStatefulset("default", 3)
+	ms.Spec.Template.Spec.Containers[0].Image = "nginx:latest"
+	resp := h.Handle(context.Background(), request(admissionv1.Create, ms, nil))
+	if !resp.Allowed {
+		t.Fatalf("Handle() denied = %v", resp.Result)
+	}
+	if len(resp.Warnings) != 1 {
+		t.Errorf("Handle() warnings = %v, want 1 warning", resp.Warnings)
+	}
+
+	// 拒绝时仍带上警告
+	old := newPolicyTestMyStatefulset("default", 3)
+	ms = old.DeepCopy()
+	ms.Spec.ServiceName = "other-service"
+	ms.Spec.Template.Spec.Containers[0].Image = "nginx"
+	resp = h.Handle(context.Background(), request(admissionv1.Update, ms, old))
+	if resp.Allowed {
+		t.Fatalf("Handle() expected update to be denied")
+	}
+	if resp.Result == nil || resp.Result.Reason != metav1.StatusReasonInvalid {
+		t.Errorf("Handle() result = %v, want reason %s", resp.Result, metav1.StatusReasonInvalid)
+	}
+	if len(resp.Warnings) != 1 {
+		t.Errorf("Handle() warnings = %v, want 1 warning", resp.Warnings)
+	}
+}
@@ -1,15 +1,23 @@
 package v1
 
 import (
+	"context"
 	"fmt"
-	"strings"
+	"sort"
 
 	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/diff"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 )
@@ -19,9 +27,7 @@ var mystatefulsetlog = logf.Log.WithName("mystatefulset-resource")
 
 // 添加常量定义
 const (
-	defaultReplicas = int32(1)
-	minReplicas     = int32(0)
-	maxReplicas     = int32(100) // 添加最大副本数限制
+	minReplicas = int32(0)
 )
 
 //+kubebuilder:rbac:groups=apps.mystatefulset.com,resources=mystatefulsets,verbs=get;list;watch;create;update;patch;delete
@@ -31,9 +37,19 @@ const (
 //+kubebuilder:rbac:groups=admissionregistration.k8s.io,resources=mutatingwebhookconfigurations,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch
+//+kubebuilder:rbac:groups=apps.mystatefulset.com,resources=mystatefulsetpolicies,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;watch
 
 // SetupWebhookWithManager 将 webhook 注册到 manager 中
 func (r *MyStatefulset) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	// 通过缓存客户端读取 MyStatefulsetPolicy，实现策略热加载
+	policyReader = mgr.GetClient()
+
+	// 先注册可以返回警告的校验 handler，builder 发现路径已注册后会跳过默认的校验 webhook
+	mgr.GetWebhookServer().Register(validatingWebhookPath, &webhook.Admission{Handler: &validatingHandler{}})
+	mgr.GetWebhookServer().Register(scaleWebhookPath, &webhook.Admission{Handler: &scaleHandler{}})
+
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(r).
 		Complete()
@@ -48,10 +64,11 @@ func (r *MyStatefulset) Default() {
 	mystatefulsetlog.Info("starting default webhook", "name", r.Name)
 	defer mystatefulsetlog.Info("finished default webhook", "name", r.Name)
 
-	// 设置默认副本数
-	if r.Spec.Replicas == 0 {
-		mystatefulsetlog.Info("setting default replicas", "name", r.Name, "replicas", defaultReplicas)
-		r.Spec.Replicas = defaultReplicas
+	// 设置默认副本数，只有未设置时才赋默认值，显式的 0 需要保留以支持缩容到 0
+	if r.Spec.Replicas == nil {
+		mystatefulsetlog.Info("setting default replicas", "name", r.Name, "replicas", DefaultReplicas)
+		replicas := DefaultReplicas
+		r.Spec.Replicas = &replicas
 	}
 
 	// 设置默认标签
@@ -72,7 +89,7 @@ func (r *MyStatefulset) Default() {
 	}
 }
 
-//+kubebuilder:webhook:path=/validate-apps-mystatefulset-com-v1-mystatefulset,mutating=false,failurePolicy=fail,sideEffects=None,groups=apps.mystatefulset.com,resources=mystatefulsets,verbs=create;update,versions=v1,name=vmystatefulset.kb.io,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/validate-apps-mystatefulset-com-v1-mystatefulset,mutating=false,failurePolicy=fail,sideEffects=None,groups=apps.mystatefulset.com,resources=mystatefulsets,verbs=create;update;delete,versions=v1,name=vmystatefulset.kb.io,admissionReviewVersions=v1
 
 var _ webhook.Validator = &MyStatefulset{}
 
@@ -80,7 +97,22 @@ var _ webhook.Validator = &MyStatefulset{}
 func (r *MyStatefulset) ValidateCreate() error {
 	mystatefulsetlog.Info("starting validate create", "name", r.Name)
 	defer mystatefulsetlog.Info("finished validate create", "name", r.Name)
-	return r.validateMyStatefulSet()
+
+	rules, err := loadAdmissionRules(r.Namespace)
+	if err != nil {
+		return apierrors.NewInternalError(err)
+	}
+	allErrs := r.validateMyStatefulSet(rules)
+
+	// 执行策略中的 CEL 规则，与基本校验的错误一起返回
+	allErrs = append(allErrs, r.validateCELRules(rules, nil)...)
+	if len(allErrs) > 0 {
+		return apierrors.NewInvalid(
+			schema.GroupKind{Group: GroupVersion.Group, Kind: "MyStatefulset"},
+			r.Name,
+			allErrs)
+	}
+	return nil
 }
 
 // ValidateUpdate 实现了 webhook.Validator 接口
@@ -95,33 +127,52 @@ func (r *MyStatefulset) ValidateUpdate(old runtime.Object) error {
 
 	var allErrs field.ErrorList
 
-	// 1. 验证基本字段
-	if err := r.validateMyStatefulSet(); err != nil {
-		return err
+	// app 标签不可变
+	if oldMyStatefulset.Labels["app"] != r.Labels["app"] {
+		allErrs = append(allErrs, field.Forbidden(
+			field.NewPath("metadata").Child("labels").Child("app"),
+			"app label is immutable"))
 	}
 
+	// 删除中的对象和 spec 未变化的更新（finalizer、注解等元数据）不执行策略和 CEL 规则，
+	// 否则策略写错或收紧后控制器自身的写入、对象的删除也会被拒绝；
+	// 不可变字段仍然校验，避免有序删除期间修改 selector 等导致 Pod 或 PVC 脱离管理
+	if r.DeletionTimestamp != nil || apiequality.Semantic.DeepEqual(r.Spec, oldMyStatefulset.Spec) {
+		allErrs = append(allErrs, r.validateImmutableFields(oldMyStatefulset)...)
+		if len(allErrs) > 0 {
+			return apierrors.NewInvalid(
+				schema.GroupKind{Group: GroupVersion.Group, Kind: "MyStatefulset"},
+				r.Name,
+				allErrs)
+		}
+		return nil
+	}
+
+	// 加载当前命名空间生效的准入规则
+	rules, err := loadAdmissionRules(r.Namespace)
+	if err != nil {
+		return apierrors.NewInternalError(err)
+	}
+
+	// 1. 验证基本字段
+	allErrs = append(allErrs, r.validateMyStatefulSet(rules)...)
+
 	// 2. 验证更新特定的规则
 	specPath := field.NewPath("spec")
 
-	// 2.1 验证副本数的变化不能太大（可选的业务规则）
-	if r.Spec.Replicas != oldMyStatefulset.Spec.Replicas {
-		oldReplicas := oldMyStatefulset.Spec.Replicas
-		newReplicas := r.Spec.Replicas
-		if newReplicas > oldReplicas*2 {
-			allErrs = append(allErrs, field.Invalid(
-				specPath.Child("replicas"),
-				r.Spec.Replicas,
-				"cannot increase replicas by more than 100% in a single update"))
-		}
+	// 2.1 验证副本数的变化不能太大（可通过策略配置）
+	// 从 0 恢复时不做限制，否则缩容到 0 之后将无法再扩容
+	oldReplicas := oldMyStatefulset.GetReplicas()
+	newReplicas := r.GetReplicas()
+	if rules.ReplicaIncrease.exceedsIncrease(oldReplicas, newReplicas) {
+		allErrs = append(allErrs, field.Invalid(
+			specPath.Child("replicas"),
+			newReplicas,
+			fmt.Sprintf("cannot increase replicas by more than %d%% in a single update", rules.ReplicaIncrease.MaxPercent)))
 	}
 
-	// 2.2 验证不可变字段
-	// 例如：不允许更改某些标签
-	if oldMyStatefulset.Labels["app"] != r.Labels["app"] {
-		allErrs = append(allErrs, field.Forbidden(
-			field.NewPath("metadata").Child("labels").Child("app"),
-			"app label is immutable"))
-	}
+	// 2.2 参考上游 StatefulSet，selector、serviceName 和 volumeClaimTemplates 不可变
+	allErrs = append(allErrs, r.validateImmutableFields(oldMyStatefulset)...)
 
 	// 2.3 验证容器配置的更改
 	for i, newContainer := range r.Spec.Template.Spec.Containers {
@@ -138,16 +189,18 @@ func (r *MyStatefulset) ValidateUpdate(old runtime.Object) error {
 			containerPath := specPath.Child("template").Child("spec").Child("containers").Index(i)
 
 			// 2.3.1 验证镜像更新策略
-			if !isValidImageUpdate(oldContainer.Image, newContainer.Image) {
+			if rules.ImageChange.forbids(oldContainer.Image, newContainer.Image) {
 				allErrs = append(allErrs, field.Invalid(
 					containerPath.Child("image"),
 					newContainer.Image,
-					"invalid image update"))
+					fmt.Sprintf("cannot update image from %q to %q", rules.ImageChange.FromSubstring, rules.ImageChange.ToSubstring)))
 			}
 
 			// 2.3.2 验证资源限制的更改
-			if err := validateResourceUpdate(oldContainer, &newContainer, containerPath); err != nil {
-				allErrs = append(allErrs, err)
+			if rules.ResourceLimitDecrease.Enabled {
+				if err := validateResourceUpdate(oldContainer, &newContainer, containerPath); err != nil {
+					allErrs = append(allErrs, err)
+				}
 			}
 		}
 	}
@@ -155,6 +208,9 @@ func (r *MyStatefulset) ValidateUpdate(old runtime.Object) error {
 	// 2.4 验证存储配置的更改（如果适用）
 	// ... 添加存储相关的验证 ...
 
+	// 2.5 执行策略中的 CEL 规则
+	allErrs = append(allErrs, r.validateCELRules(rules, oldMyStatefulset)...)
+
 	if len(allErrs) > 0 {
 		return apierrors.NewInvalid(
 			schema.GroupKind{Group: GroupVersion.Group, Kind: "MyStatefulset"},
@@ -166,30 +222,257 @@ func (r *MyStatefulset) ValidateUpdate(old runtime.Object) error {
 }
 
 // ValidateDelete 实现了 webhook.Validator 接口
+// 删除 MyStatefulset 时控制器会一并删除 PVC，这里拦截受保护的对象，防止误删数据
 func (r *MyStatefulset) ValidateDelete() error {
 	mystatefulsetlog.Info("validating deletion", "name", r.Name)
-	// 可以添加删除前的验证逻辑，比如检查依赖资源
+
+	gr := schema.GroupResource{Group: GroupVersion.Group, Resource: "mystatefulsets"}
+
+	// 1. 带有删除保护注解的对象不允许删除
+	if r.Annotations[DeletionProtectionAnnotation] == "true" {
+		return apierrors.NewForbidden(gr, r.Name, fmt.Errorf(
+			"deletion protection is enabled; remove the %s annotation before deleting", DeletionProtectionAnnotation))
+	}
+
+	// 2. 策略开启后，仍拥有 Bound PVC 的对象需要确认注解才能删除
+	rules, err := loadAdmissionRules(r.Namespace)
+	if err != nil {
+		return apierrors.NewInternalError(err)
+	}
+	if !rules.DeletionProtection.Enabled || r.Annotations[ConfirmDeleteAnnotation] == string(r.UID) {
+		return nil
+	}
+
+	boundClaims, err := r.boundClaimNames()
+	if err != nil {
+		return apierrors.NewInternalError(err)
+	}
+	if len(boundClaims) > 0 {
+		return apierrors.NewForbidden(gr, r.Name, fmt.Errorf(
+			"it still owns Bound PersistentVolumeClaims %v; set the %s annotation to %q to confirm deletion",
+			boundClaims, ConfirmDeleteAnnotation, r.UID))
+	}
 	return nil
 }
 
+// boundClaimNames 返回由该 MyStatefulset 控制且处于 Bound 状态的 PVC 名称
+func (r *MyStatefulset) boundClaimNames() ([]string, error) {
+	if policyReader == nil {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), policyLoadTimeout)
+	defer cancel()
+
+	var pvcs corev1.PersistentVolumeClaimList
+	if err := policyReader.List(ctx, &pvcs, client.InNamespace(r.Namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list PersistentVolumeClaims: %w", err)
+	}
+
+	var names []string
+	for _, pvc := range pvcs.Items {
+		owner := metav1.GetControllerOf(&pvc)
+		if owner == nil || owner.UID != r.UID || pvc.Status.Phase != corev1.ClaimBound {
+			continue
+		}
+		names = append(names, pvc.Name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// validateSelector 验证 selector 合法、非空，并且能选中 Pod 模板的标签
+func (r *MyStatefulset) validateSelector() field.ErrorList {
+	var allErrs field.ErrorList
+	selectorPath := field.NewPath("spec").Child("selector")
+
+	if r.Spec.Selector == nil {
+		return append(allErrs, field.Required(selectorPath, ""))
+	}
+	allErrs = append(allErrs, metav1validation.ValidateLabelSelector(r.Spec.Selector, selectorPath)...)
+	if len(r.Spec.Selector.MatchLabels)+len(r.Spec.Selector.MatchExpressions) == 0 {
+		allErrs = append(allErrs, field.Invalid(selectorPath, r.Spec.Selector,
+			"empty selector is invalid for MyStatefulset"))
+	}
+	if len(allErrs) > 0 {
+		return allErrs
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(r.Spec.Selector)
+	if err != nil {
+		return append(allErrs, field.Invalid(selectorPath, r.Spec.Selector, err.Error()))
+	}
+	if !selector.Matches(labels.Set(r.Spec.Template.Labels)) {
+		allErrs = append(allErrs, field.Invalid(
+			field.NewPath("spec").Child("template").Child("metadata").Child("labels"),
+			r.Spec.Template.Labels,
+			"`selector` does not match template `labels`"))
+	}
+	return allErrs
+}
+
+// validateVolumes 验证 Pod 模板中的卷与 PVC 模板生成的卷名称不冲突，
+// 并且每个 volumeMount 都引用了模板中的卷或某个 PVC 模板
+func (r *MyStatefulset) validateVolumes() field.ErrorList {
+	var allErrs field.ErrorList
+	podSpecPath := field.NewPath("spec").Child("template").Child("spec")
+
+	// PVC 模板生成的卷名，与控制器创建 Pod 时的命名规则保持一致
+	claimNames := make(map[string]bool, len(r.Spec.VolumeClaimTemplates))
+	for _, pvcTemplate := range r.Spec.VolumeClaimTemplates {
+		claimNames[ClaimVolumeName(pvcTemplate)] = true
+	}
+
+	volumeNames := make(map[string]bool, len(r.Spec.Template.Spec.Volumes))
+	for i, volume := range r.Spec.Template.Spec.Volumes {
+		if claimNames[volume.Name] {
+			allErrs = append(allErrs, field.Duplicate(
+				podSpecPath.Child("volumes").Index(i).Child("name"),
+				volume.Name))
+		}
+		volumeNames[volume.Name] = true
+	}
+
+	validateMounts := func(containers []corev1.Container, path *field.Path) {
+		for i, container := range containers {
+			for j, mount := range container.VolumeMounts {
+				if !volumeNames[mount.Name] && !claimNames[mount.Name] {
+					allErrs = append(allErrs, field.NotFound(
+						path.Index(i).Child("volumeMounts").Index(j).Child("name"),
+						mount.Name))
+				}
+			}
+		}
+	}
+	validateMounts(r.Spec.Template.Spec.InitContainers, podSpecPath.Child("initContainers"))
+	validateMounts(r.Spec.Template.Spec.Containers, podSpecPath.Child("containers"))
+
+	return allErrs
+}
+
+// validateOrdinalOverrides 验证 ordinalOverrides 选中了序号、范围合法，
+// 并且 patch 能够应用到 spec.template 上且不会使 Pod 标签脱离 selector
+func (r *MyStatefulset) validateOrdinalOverrides() field.ErrorList {
+	var allErrs field.ErrorList
+	overridesPath := field.NewPath("spec").Child("ordinalOverrides")
+
+	selector, selectorErr := metav1.LabelSelectorAsSelector(r.Spec.Selector)
+	for i, override := range r.Spec.OrdinalOverrides {
+		path := overridesPath.Index(i)
+
+		if len(override.Ordinals) == 0 && len(override.Ranges) == 0 {
+			allErrs = append(allErrs, field.Required(path, "at least one of ordinals or ranges must be set"))
+		}
+		for j, ordinal := range override.Ordinals {
+			if ordinal < 0 {
+				allErrs = append(allErrs, field.Invalid(path.Child("ordinals").Index(j), ordinal, "must be greater than or equal to 0"))
+			}
+		}
+		for j, rng := range override.Ranges {
+			if rng.End != nil && *rng.End < rng.Start {
+				allErrs = append(allErrs, field.Invalid(path.Child("ranges").Index(j).Child("end"), *rng.End, "must be greater than or equal to start"))
+			}
+		}
+
+		if len(override.Patch.Raw) == 0 {
+			allErrs = append(allErrs, field.Required(path.Child("patch"), ""))
+			continue
+		}
+		patched, err := applyTemplatePatch(&r.Spec.Template, override.Patch.Raw)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(path.Child("patch"), string(override.Patch.Raw), err.Error()))
+			continue
+		}
+		if selectorErr == nil && r.Spec.Selector != nil && !selector.Matches(labels.Set(patched.Labels)) {
+			allErrs = append(allErrs, field.Invalid(path.Child("patch"), string(override.Patch.Raw),
+				"patched template `labels` no longer match `selector`"))
+		}
+		if len(patched.Spec.Containers) == 0 {
+			allErrs = append(allErrs, field.Invalid(path.Child("patch"), string(override.Patch.Raw),
+				"patched template must keep at least one container"))
+		}
+	}
+	return allErrs
+}
+
+// ClaimVolumeName 返回 PVC 模板在 Pod 中对应的卷名，未设置名称时使用 www。
+// PVC 名称为 <卷名>-<副本集名称>-<序号>
+func ClaimVolumeName(pvcTemplate corev1.PersistentVolumeClaim) string {
+	if pvcTemplate.Name != "" {
+		return pvcTemplate.Name
+	}
+	return "www"
+}
+
+// validateUpdateStrategy 校验 maxUnavailable 为正整数或 1%-100% 的百分比
+func (r *MyStatefulset) validateUpdateStrategy() field.ErrorList {
+	var allErrs field.ErrorList
+	rollingUpdate := r.Spec.UpdateStrategy.RollingUpdate
+	if rollingUpdate == nil || rollingUpdate.MaxUnavailable == nil {
+		return nil
+	}
+	path := field.NewPath("spec").Child("updateStrategy").Child("rollingUpdate").Child("maxUnavailable")
+	maxUnavailable := rollingUpdate.MaxUnavailable
+	// 百分比按 100 个副本换算，只校验取值范围
+	n, err := intstr.GetScaledValueFromIntOrPercent(maxUnavailable, 100, true)
+	switch {
+	case err != nil:
+		allErrs = append(allErrs, field.Invalid(path, maxUnavailable.String(), err.Error()))
+	case n < 1:
+		allErrs = append(allErrs, field.Invalid(path, maxUnavailable.String(), "must be greater than 0"))
+	case maxUnavailable.Type == intstr.String && n > 100:
+		allErrs = append(allErrs, field.Invalid(path, maxUnavailable.String(), "must not be greater than 100%"))
+	}
+	return allErrs
+}
+
 // validateMyStatefulSet 验证 MyStatefulSet 的通用逻辑
-func (r *MyStatefulset) validateMyStatefulSet() error {
+func (r *MyStatefulset) validateMyStatefulSet(rules admissionRules) field.ErrorList {
 	var allErrs field.ErrorList
 
 	// 验证副本数范围
-	if r.Spec.Replicas < minReplicas {
+	replicas := r.GetReplicas()
+	if replicas < minReplicas {
 		allErrs = append(allErrs, field.Invalid(
 			field.NewPath("spec").Child("replicas"),
-			r.Spec.Replicas,
+			replicas,
 			fmt.Sprintf("must be greater than or equal to %d", minReplicas)))
 	}
-	if r.Spec.Replicas > maxReplicas {
+	if rules.MaxReplicas.Enabled && replicas > rules.MaxReplicas.Limit {
 		allErrs = append(allErrs, field.Invalid(
 			field.NewPath("spec").Child("replicas"),
-			r.Spec.Replicas,
-			fmt.Sprintf("must be less than or equal to %d", maxReplicas)))
+			replicas,
+			fmt.Sprintf("must be less than or equal to %d", rules.MaxReplicas.Limit)))
 	}
 
+	// 验证 selector 与模板标签一致，按完整的 label selector 语义（包括 matchExpressions）匹配
+	allErrs = append(allErrs, r.validateSelector()...)
+
+	// 验证模板中的 volumes 与 volumeClaimTemplates 不冲突，且 volumeMounts 都能找到对应的卷
+	allErrs = append(allErrs, r.validateVolumes()...)
+
+	// 验证 PVC 数据源
+	allErrs = append(allErrs, r.validateDataSources()...)
+
+	// 验证拓扑分布策略
+	allErrs = append(allErrs, r.validatePlacement()...)
+
+	// 验证定时扩缩容，每次修改副本数都需要满足增幅限制
+	allErrs = append(allErrs, r.validateScalingSchedule()...)
+	allErrs = append(allErrs, r.validateScalingScheduleSteps(rules.ReplicaIncrease)...)
+
+	// 验证自动扩缩容
+	allErrs = append(allErrs, r.validateAutoscaling()...)
+
+	// 验证启动依赖
+	allErrs = append(allErrs, r.validateDependencies()...)
+
+	// 验证按序号的覆盖配置
+	allErrs = append(allErrs, r.validateOrdinalOverrides()...)
+
+	// 验证滚动更新策略
+	allErrs = append(allErrs, r.validateUpdateStrategy()...)
+
 	// 验证容器配置
 	if len(r.Spec.Template.Spec.Containers) == 0 {
 		allErrs = append(allErrs, field.Required(
@@ -230,23 +513,82 @@ func (r *MyStatefulset) validateMyStatefulSet() error {
 		}
 	}
 
-	if len(allErrs) == 0 {
-		return nil
+	return allErrs
+}
+
+// validateImmutableFields 校验创建后不可修改的字段。
+// 控制器按 selector 查找 Pod、按 volumeClaimTemplates 命名 PVC，修改这些字段会导致已有的 Pod 和 PVC 被孤立。
+func (r *MyStatefulset) validateImmutableFields(old *MyStatefulset) field.ErrorList {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	if !apiequality.Semantic.DeepEqual(old.Spec.Selector, r.Spec.Selector) {
+		allErrs = append(allErrs, field.Forbidden(
+			specPath.Child("selector"),
+			fmt.Sprintf("field is immutable: %s", diff.ObjectReflectDiff(old.Spec.Selector, r.Spec.Selector))))
+	}
+
+	if old.Spec.ServiceName != r.Spec.ServiceName {
+		allErrs = append(allErrs, field.Forbidden(
+			specPath.Child("serviceName"),
+			fmt.Sprintf("field is immutable: %q -> %q", old.Spec.ServiceName, r.Spec.ServiceName)))
+	}
+
+	allErrs = append(allErrs, validateVolumeClaimTemplatesUpdate(
+		old.Spec.VolumeClaimTemplates,
+		r.Spec.VolumeClaimTemplates,
+		specPath.Child("volumeClaimTemplates"))...)
+
+	return allErrs
+}
+
+// validateVolumeClaimTemplatesUpdate 校验 volumeClaimTemplates 的修改，只允许扩大存储容量
+func validateVolumeClaimTemplatesUpdate(oldTemplates, newTemplates []corev1.PersistentVolumeClaim, path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if len(oldTemplates) != len(newTemplates) {
+		return append(allErrs, field.Forbidden(path,
+			fmt.Sprintf("volumeClaimTemplates cannot be added or removed: %s",
+				diff.ObjectReflectDiff(claimTemplateNames(oldTemplates), claimTemplateNames(newTemplates)))))
+	}
+
+	for i := range newTemplates {
+		oldTemplate := oldTemplates[i].DeepCopy()
+		newTemplate := newTemplates[i].DeepCopy()
+		storagePath := path.Index(i).Child("spec", "resources", "requests", "storage")
+
+		// 存储容量只能扩大，比较前将其还原为旧值，剩余部分必须完全一致
+		oldSize, oldHasSize := oldTemplate.Spec.Resources.Requests[corev1.ResourceStorage]
+		newSize, newHasSize := newTemplate.Spec.Resources.Requests[corev1.ResourceStorage]
+		if oldHasSize && newHasSize {
+			if newSize.Cmp(oldSize) < 0 {
+				allErrs = append(allErrs, field.Forbidden(storagePath,
+					fmt.Sprintf("storage size can only be increased: %s -> %s", oldSize.String(), newSize.String())))
+			}
+			newTemplate.Spec.Resources.Requests[corev1.ResourceStorage] = oldSize
+		}
+
+		// status 不参与比较
+		oldTemplate.Status = corev1.PersistentVolumeClaimStatus{}
+		newTemplate.Status = corev1.PersistentVolumeClaimStatus{}
+
+		if !apiequality.Semantic.DeepEqual(oldTemplate, newTemplate) {
+			allErrs = append(allErrs, field.Forbidden(path.Index(i),
+				fmt.Sprintf("updates to volumeClaimTemplates are forbidden except for increasing storage size: %s",
+					diff.ObjectReflectDiff(oldTemplate, newTemplate))))
+		}
 	}
 
-	return apierrors.NewInvalid(
-		schema.GroupKind{Group: GroupVersion.Group, Kind: "MyStatefulset"},
-		r.Name,
-		allErrs)
+	return allErrs
 }
 
-// 辅助函数：验证镜像更新是否有效
-func isValidImageUpdate(oldImage, newImage string) bool {
-	// 示例：不允许从正式版本回退到测试版本
-	if strings.Contains(oldImage, "prod") && strings.Contains(newImage, "test") {
-		return false
+// claimTemplateNames 返回 volumeClaimTemplates 的名称列表
+func claimTemplateNames(templates []corev1.PersistentVolumeClaim) []string {
+	names := make([]string, 0, len(templates))
+	for _, t := range templates {
+		names = append(names, t.Name)
 	}
-	return true
+	return names
 }
 
 // 辅助函数：验证资源更新
@@ -4,7 +4,13 @@ import (
 	"testing"
 
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 func TestMyStatefulset_ValidateCreate(t *testing.T) {
@@ -21,7 +27,7 @@ func TestMyStatefulset_ValidateCreate(t *testing.T) {
 					Namespace: "default",
 				},
 				Spec: MyStatefulsetSpec{
-					Replicas:    3,
+					Replicas:    pointer.Int32(3),
 					ServiceName: "test-service",
 					Selector: &metav1.LabelSelector{
 						MatchLabels: map[string]string{
@@ -55,7 +61,7 @@ func TestMyStatefulset_ValidateCreate(t *testing.T) {
 					Namespace: "default",
 				},
 				Spec: MyStatefulsetSpec{
-					Replicas:    -1,
+					Replicas:    pointer.Int32(-1),
 					ServiceName: "test-service",
 					Selector: &metav1.LabelSelector{
 						MatchLabels: map[string]string{
@@ -100,7 +106,7 @@ func TestMyStatefulset_ValidateUpdate(t *testing.T) {
 			Namespace: "default",
 		},
 		Spec: MyStatefulsetSpec{
-			Replicas:    1,
+			Replicas:    pointer.Int32(1),
 			ServiceName: "test-service",
 			Selector: &metav1.LabelSelector{
 				MatchLabels: map[string]string{
@@ -138,7 +144,7 @@ func TestMyStatefulset_ValidateUpdate(t *testing.T) {
 					Namespace: "default",
 				},
 				Spec: MyStatefulsetSpec{
-					Replicas:    2,
+					Replicas:    pointer.Int32(2),
 					ServiceName: "test-service",
 					Selector: &metav1.LabelSelector{
 						MatchLabels: map[string]string{
@@ -175,3 +181,591 @@ func TestMyStatefulset_ValidateUpdate(t *testing.T) {
 		})
 	}
 }
+
+func TestMyStatefulset_Default(t *testing.T) {
+	tests := []struct {
+		name     string
+		replicas *int32
+		want     int32
+	}{
+		{
+			name:     "unset replicas defaults to 1",
+			replicas: nil,
+			want:     1,
+		},
+		{
+			name:     "explicit zero is kept",
+			replicas: pointer.Int32(0),
+			want:     0,
+		},
+		{
+			name:     "explicit value is kept",
+			replicas: pointer.Int32(3),
+			want:     3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms := &MyStatefulset{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-mystatefulset",
+					Namespace: "default",
+				},
+				Spec: MyStatefulsetSpec{
+					Replicas: tt.replicas,
+				},
+			}
+			ms.Default()
+			if ms.Spec.Replicas == nil {
+				t.Fatalf("Default() left replicas unset")
+			}
+			if *ms.Spec.Replicas != tt.want {
+				t.Errorf("Default() replicas = %d, want %d", *ms.Spec.Replicas, tt.want)
+			}
+		})
+	}
+}
+
+func TestMyStatefulset_ValidateUpdate_ScaleFromZero(t *testing.T) {
+	oldMs := &MyStatefulset{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-mystatefulset",
+			Namespace: "default",
+		},
+		Spec: MyStatefulsetSpec{
+			Replicas:    pointer.Int32(0),
+			ServiceName: "test-service",
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"app": "test",
+				},
+			},
+			Template: PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						"app": "test",
+					},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:  "nginx",
+							Image: "nginx:latest",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	newMs := oldMs.DeepCopy()
+	newMs.Spec.Replicas = pointer.Int32(3)
+
+	if err := newMs.ValidateUpdate(oldMs); err != nil {
+		t.Errorf("ValidateUpdate() scaling up from zero should be allowed, got error = %v", err)
+	}
+}
+
+func TestMyStatefulset_ValidateUpdate_ImmutableFields(t *testing.T) {
+	newClaimTemplate := func(size string) corev1.PersistentVolumeClaim {
+		return corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "www",
+			},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceStorage: resource.MustParse(size),
+					},
+				},
+			},
+		}
+	}
+
+	oldMs := &MyStatefulset{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-mystatefulset",
+			Namespace: "default",
+		},
+		Spec: MyStatefulsetSpec{
+			Replicas:    pointer.Int32(1),
+			ServiceName: "test-service",
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"app": "test",
+				},
+			},
+			Template: PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						"app": "test",
+					},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:  "nginx",
+							Image: "nginx:latest",
+						},
+					},
+				},
+			},
+			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{newClaimTemplate("1Gi")},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		mutate    func(ms *MyStatefulset)
+		wantField string
+	}{
+		{
+			name: "storage size growth is allowed",
+			mutate: func(ms *MyStatefulset) {
+				ms.Spec.VolumeClaimTemplates[0] = newClaimTemplate("2Gi")
+			},
+		},
+		{
+			name: "selector change is forbidden",
+			mutate: func(ms *MyStatefulset) {
+				ms.Spec.Selector.MatchLabels["tier"] = "db"
+			},
+			wantField: "spec.selector",
+		},
+		{
+			name: "serviceName change is forbidden",
+			mutate: func(ms *MyStatefulset) {
+				ms.Spec.ServiceName = "other-service"
+			},
+			wantField: "spec.serviceName",
+		},
+		{
+			name: "storage size shrink is forbidden",
+			mutate: func(ms *MyStatefulset) {
+				ms.Spec.VolumeClaimTemplates[0] = newClaimTemplate("512Mi")
+			},
+			wantField: "spec.volumeClaimTemplates[0].spec.resources.requests.storage",
+		},
+		{
+			name: "access mode change is forbidden",
+			mutate: func(ms *MyStatefulset) {
+				ms.Spec.VolumeClaimTemplates[0].Spec.AccessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany}
+			},
+			wantField: "spec.volumeClaimTemplates[0]",
+		},
+		{
+			name: "adding a claim template is forbidden",
+			mutate: func(ms *MyStatefulset) {
+				extra := newClaimTemplate("1Gi")
+				extra.Name = "data"
+				ms.Spec.VolumeClaimTemplates = append(ms.Spec.VolumeClaimTemplates, extra)
+			},
+			wantField: "spec.volumeClaimTemplates",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newMs := oldMs.DeepCopy()
+			tt.mutate(newMs)
+
+			errs := newMs.validateImmutableFields(oldMs)
+			if tt.wantField == "" {
+				if len(errs) != 0 {
+					t.Errorf("validateImmutableFields() unexpected errors = %v", errs)
+				}
+				if err := newMs.ValidateUpdate(oldMs); err != nil {
+					t.Errorf("ValidateUpdate() unexpected error = %v", err)
+				}
+				return
+			}
+
+			if len(errs) != 1 || errs[0].Field != tt.wantField {
+				t.Fatalf("validateImmutableFields() = %v, want one error at %s", errs, tt.wantField)
+			}
+			if err := newMs.ValidateUpdate(oldMs); err == nil {
+				t.Errorf("ValidateUpdate() expected error")
+			}
+		})
+	}
+}
+
+func TestMyStatefulset_validateSelector(t *testing.T) {
+	tests := []struct {
+		name     string
+		selector *metav1.LabelSelector
+		labels   map[string]string
+		wantErr  bool
+	}{
+		{
+			name:     "matchLabels subset of template labels",
+			selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "test"}},
+			labels:   map[string]string{"app": "test", "tier": "db"},
+		},
+		{
+			name: "matchExpressions match template labels",
+			selector: &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "app", Operator: metav1.LabelSelectorOpIn, Values: []string{"test", "canary"}},
+					{Key: "tier", Operator: metav1.LabelSelectorOpExists},
+				},
+			},
+			labels: map[string]string{"app": "test", "tier": "db"},
+		},
+		{
+			name: "matchExpressions do not match template labels",
+			selector: &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "app", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"test"}},
+				},
+			},
+			labels:  map[string]string{"app": "test"},
+			wantErr: true,
+		},
+		{
+			name:     "matchLabels value mismatch",
+			selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "other"}},
+			labels:   map[string]string{"app": "test"},
+			wantErr:  true,
+		},
+		{
+			name:     "missing template labels",
+			selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "test"}},
+			wantErr:  true,
+		},
+		{
+			name:     "empty selector",
+			selector: &metav1.LabelSelector{},
+			labels:   map[string]string{"app": "test"},
+			wantErr:  true,
+		},
+		{
+			name:    "nil selector",
+			labels:  map[string]string{"app": "test"},
+			wantErr: true,
+		},
+		{
+			name: "invalid operator",
+			selector: &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "app", Operator: "Like", Values: []string{"test"}},
+				},
+			},
+			labels:  map[string]string{"app": "test"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms := &MyStatefulset{
+				Spec: MyStatefulsetSpec{
+					Selector: tt.selector,
+					Template: PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{Labels: tt.labels},
+					},
+				},
+			}
+			errs := ms.validateSelector()
+			if (len(errs) > 0) != tt.wantErr {
+				t.Errorf("validateSelector() = %v, wantErr %v", errs, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMyStatefulset_validateVolumes(t *testing.T) {
+	configVolume := corev1.Volume{
+		Name: "config",
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "test-config"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		volumes   []corev1.Volume
+		claims    []corev1.PersistentVolumeClaim
+		mounts    []corev1.VolumeMount
+		wantPaths []string
+	}{
+		{
+			name:    "mounts reference template volume and claim",
+			volumes: []corev1.Volume{configVolume},
+			claims:  []corev1.PersistentVolumeClaim{{ObjectMeta: metav1.ObjectMeta{Name: "data"}}},
+			mounts: []corev1.VolumeMount{
+				{Name: "config", MountPath: "/etc/config"},
+				{Name: "data", MountPath: "/data"},
+			},
+		},
+		{
+			name:   "unnamed claim is mounted as www",
+			claims: []corev1.PersistentVolumeClaim{{}},
+			mounts: []corev1.VolumeMount{{Name: "www", MountPath: "/usr/share/nginx/html"}},
+		},
+		{
+			name: "template volume collides with claim",
+			volumes: []corev1.Volume{{
+				Name:         "data",
+				VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+			}},
+			claims:    []corev1.PersistentVolumeClaim{{ObjectMeta: metav1.ObjectMeta{Name: "data"}}},
+			wantPaths: []string{"spec.template.spec.volumes[0].name"},
+		},
+		{
+			name:      "mount references unknown volume",
+			volumes:   []corev1.Volume{configVolume},
+			mounts:    []corev1.VolumeMount{{Name: "missing", MountPath: "/missing"}},
+			wantPaths: []string{"spec.template.spec.containers[0].volumeMounts[0].name"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms := &MyStatefulset{
+				Spec: MyStatefulsetSpec{
+					Template: PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{Name: "nginx", Image: "nginx:latest", VolumeMounts: tt.mounts},
+							},
+							Volumes: tt.volumes,
+						},
+					},
+					VolumeClaimTemplates: tt.claims,
+				},
+			}
+			errs := ms.validateVolumes()
+			if len(errs) != len(tt.wantPaths) {
+				t.Fatalf("validateVolumes() = %v, want %d errors", errs, len(tt.wantPaths))
+			}
+			for i, err := range errs {
+				if err.Field != tt.wantPaths[i] {
+					t.Errorf("validateVolumes() error path = %s, want %s", err.Field, tt.wantPaths[i])
+				}
+			}
+		})
+	}
+}
+
+func TestMyStatefulset_ValidateDelete(t *testing.T) {
+	newMs := func(annotations map[string]string) *MyStatefulset {
+		ms := newPolicyTestMyStatefulset("default", 1)
+		ms.UID = "set-uid"
+		ms.Annotations = annotations
+		return ms
+	}
+	boundPVC := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "www-test-mystatefulset-0",
+			Namespace: "default",
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(newMs(nil), GroupVersion.WithKind("MyStatefulset")),
+			},
+		},
+		Status: corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimBound},
+	}
+	protectPolicy := &MyStatefulsetPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "protect"},
+		Spec: MyStatefulsetPolicySpec{
+			DeletionProtection: &DeletionProtectionRule{Enabled: true},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		objs        []client.Object
+		annotations map[string]string
+		wantErr     bool
+	}{
+		{
+			name: "no protection",
+			objs: []client.Object{boundPVC},
+		},
+		{
+			name:        "deletion-protection annotation",
+			annotations: map[string]string{DeletionProtectionAnnotation: "true"},
+			wantErr:     true,
+		},
+		{
+			name:        "deletion-protection annotation disabled",
+			annotations: map[string]string{DeletionProtectionAnnotation: "false"},
+		},
+		{
+			name:    "policy blocks set with bound PVCs",
+			objs:    []client.Object{protectPolicy, boundPVC},
+			wantErr: true,
+		},
+		{
+			name:        "policy allows with matching confirmation",
+			objs:        []client.Object{protectPolicy, boundPVC},
+			annotations: map[string]string{ConfirmDeleteAnnotation: "set-uid"},
+		},
+		{
+			name:        "policy blocks with stale confirmation",
+			objs:        []client.Object{protectPolicy, boundPVC},
+			annotations: map[string]string{ConfirmDeleteAnnotation: "old-uid"},
+			wantErr:     true,
+		},
+		{
+			name: "policy allows set without PVCs",
+			objs: []client.Object{protectPolicy},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withPolicyReader(t, tt.objs...)
+			err := newMs(tt.annotations).ValidateDelete()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateDelete() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !apierrors.IsForbidden(err) {
+				t.Errorf("ValidateDelete() error = %v, want Forbidden", err)
+			}
+		})
+	}
+}
+
+func TestMyStatefulset_validateOrdinalOverrides(t *testing.T) {
+	patch := func(raw string) runtime.RawExtension { return runtime.RawExtension{Raw: []byte(raw)} }
+	memoryPatch := patch(`{"spec":{"containers":[{"name":"nginx","resources":{"limits":{"memory":"4Gi"}}}]}}`)
+
+	tests := []struct {
+		name      string
+		overrides []OrdinalOverride
+		wantPaths []string
+	}{
+		{
+			name: "valid overrides",
+			overrides: []OrdinalOverride{
+				{Ordinals: []int32{0}, Patch: memoryPatch},
+				{Ranges: []OrdinalRange{{Start: 1, End: pointer.Int32(2)}}, Patch: memoryPatch},
+			},
+		},
+		{
+			name:      "no ordinals selected",
+			overrides: []OrdinalOverride{{Patch: memoryPatch}},
+			wantPaths: []string{"spec.ordinalOverrides[0]"},
+		},
+		{
+			name: "negative ordinal and reversed range",
+			overrides: []OrdinalOverride{{
+				Ordinals: []int32{-1},
+				Ranges:   []OrdinalRange{{Start: 3, End: pointer.Int32(1)}},
+				Patch:    memoryPatch,
+			}},
+			wantPaths: []string{"spec.ordinalOverrides[0].ordinals[0]", "spec.ordinalOverrides[0].ranges[0].end"},
+		},
+		{
+			name:      "missing patch",
+			overrides: []OrdinalOverride{{Ordinals: []int32{0}}},
+			wantPaths: []string{"spec.ordinalOverrides[0].patch"},
+		},
+		{
+			name:      "patch does not apply",
+			overrides: []OrdinalOverride{{Ordinals: []int32{0}, Patch: patch(`{"spec":{"containers":"nginx"}}`)}},
+			wantPaths: []string{"spec.ordinalOverrides[0].patch"},
+		},
+		{
+			name:      "patch breaks selector",
+			overrides: []OrdinalOverride{{Ordinals: []int32{0}, Patch: patch(`{"metadata":{"labels":{"app":"other"}}}`)}},
+			wantPaths: []string{"spec.ordinalOverrides[0].patch"},
+		},
+		{
+			name: "patch removes every container",
+			overrides: []OrdinalOverride{{
+				Ordinals: []int32{0},
+				Patch:    patch(`{"spec":{"containers":[{"name":"nginx","$patch":"delete"}]}}`),
+			}},
+			wantPaths: []string{"spec.ordinalOverrides[0].patch"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms := newPolicyTestMyStatefulset("default", 3)
+			ms.Spec.OrdinalOverrides = tt.overrides
+			errs := ms.validateOrdinalOverrides()
+			if len(errs) != len(tt.wantPaths) {
+				t.Fatalf("validateOrdinalOverrides() = %v, want %d errors", errs, len(tt.wantPaths))
+			}
+			for i, err := range errs {
+				if err.Field != tt.wantPaths[i] {
+					t.Errorf("validateOrdinalOverrides() error path = %s, want %s", err.Field, tt.wantPaths[i])
+				}
+			}
+		})
+	}
+}
+
+func TestMyStatefulset_validateUpdateStrategy(t *testing.T) {
+	tests := []struct {
+		name           string
+		maxUnavailable *intstr.IntOrString
+		wantErr        bool
+	}{
+		{name: "unset"},
+		{name: "integer", maxUnavailable: intOrStringPtr(intstr.FromInt(2))},
+		{name: "percentage", maxUnavailable: intOrStringPtr(intstr.FromString("50%"))},
+		{name: "zero", maxUnavailable: intOrStringPtr(intstr.FromInt(0)), wantErr: true},
+		{name: "zero percent", maxUnavailable: intOrStringPtr(intstr.FromString("0%")), wantErr: true},
+		{name: "over 100 percent", maxUnavailable: intOrStringPtr(intstr.FromString("150%")), wantErr: true},
+		{name: "not a percentage", maxUnavailable: intOrStringPtr(intstr.FromString("half")), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms := &MyStatefulset{
+				Spec: MyStatefulsetSpec{
+					UpdateStrategy: UpdateStrategy{
+						Type:          RollingUpdateStatefulSetStrategyType,
+						RollingUpdate: &RollingUpdateStatefulSetStrategy{MaxUnavailable: tt.maxUnavailable},
+					},
+				},
+			}
+			errs := ms.validateUpdateStrategy()
+			if (len(errs) > 0) != tt.wantErr {
+				t.Errorf("validateUpdateStrategy() = %v, wantErr %v", errs, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMyStatefulset_GetMaxUnavailable(t *testing.T) {
+	tests := []struct {
+		name           string
+		replicas       int32
+		maxUnavailable *intstr.IntOrString
+		want           int
+	}{
+		{name: "unset", replicas: 5, want: 1},
+		{name: "integer", replicas: 5, maxUnavailable: intOrStringPtr(intstr.FromInt(2)), want: 2},
+		{name: "percentage rounds down", replicas: 5, maxUnavailable: intOrStringPtr(intstr.FromString("50%")), want: 2},
+		{name: "percentage at least one", replicas: 3, maxUnavailable: intOrStringPtr(intstr.FromString("10%")), want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms := &MyStatefulset{
+				Spec: MyStatefulsetSpec{
+					Replicas: pointer.Int32(tt.replicas),
+					UpdateStrategy: UpdateStrategy{
+						RollingUpdate: &RollingUpdateStatefulSetStrategy{MaxUnavailable: tt.maxUnavailable},
+					},
+				},
+			}
+			if got := ms.GetMaxUnavailable(); got != tt.want {
+				t.Errorf("GetMaxUnavailable() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func intOrStringPtr(v intstr.IntOrString) *intstr.IntOrString {
+	return &v
+}
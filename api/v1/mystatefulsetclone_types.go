@@ -0,0 +1,224 @@
+/*
+Copyright 2024 bryant-rh.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CloneSourceAnnotation is set on a MyStatefulset created by a
+// MyStatefulsetClone to <namespace>/<name> of the clone.
+const CloneSourceAnnotation = "apps.mystatefulset.com/cloned-by"
+
+// CloneLabel is added to the selector and pod template labels of a cloned
+// MyStatefulset so that the clone never selects the source's pods. The
+// labels of the source's selector are set to the target name on the clone,
+// so that the source and its Services never select the clone's pods.
+const CloneLabel = "apps.mystatefulset.com/clone"
+
+// MyStatefulsetCloneSpec describes the MyStatefulset to copy and the new one
+// to create. The new MyStatefulset is created in the clone's namespace.
+type MyStatefulsetCloneSpec struct {
+	// Source is the MyStatefulset to clone.
+	Source CloneSource `json:"source"`
+
+	// TargetName is the name of the MyStatefulset to create.
+	// +kubebuilder:validation:MinLength=1
+	TargetName string `json:"targetName"`
+
+	// ServiceName is the headless service of the new MyStatefulset.
+	// It is created from the source's service if it does not exist.
+	// Defaults to TargetName.
+	// +optional
+	ServiceName string `json:"serviceName,omitempty"`
+
+	// Replicas of the new MyStatefulset. Defaults to the source's replicas.
+	// Every cloned ordinal must have a source PVC.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// SkipData copies only the spec, leaving the new PVCs empty.
+	// CSI volume cloning only works within a namespace, so SkipData is
+	// required when the source is in another namespace.
+	// +optional
+	SkipData bool `json:"skipData,omitempty"`
+}
+
+// CloneSource references the MyStatefulset to clone.
+type CloneSource struct {
+	// Namespace of the source. Defaults to the clone's namespace.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name of the source MyStatefulset.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// ClonePhase is the lifecycle phase of a MyStatefulsetClone.
+// +kubebuilder:validation:Enum=Pending;Cloning;Completed;Failed
+type ClonePhase string
+
+const (
+	// ClonePhasePending means the source is not available yet.
+	ClonePhasePending ClonePhase = "Pending"
+	// ClonePhaseCloning means the new MyStatefulset exists and its PVCs are
+	// being cloned.
+	ClonePhaseCloning ClonePhase = "Cloning"
+	// ClonePhaseCompleted means every cloned PVC is Bound.
+	ClonePhaseCompleted ClonePhase = "Completed"
+	// ClonePhaseFailed means the clone cannot proceed; see Message.
+	ClonePhaseFailed ClonePhase = "Failed"
+)
+
+// MyStatefulsetCloneStatus reports the progress of a clone.
+type MyStatefulsetCloneStatus struct {
+	// Phase of the clone.
+	// +optional
+	Phase ClonePhase `json:"phase,omitempty"`
+
+	// Message explains the phase.
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// Ordinals reports the progress of each cloned ordinal.
+	// +optional
+	Ordinals []CloneOrdinalStatus `json:"ordinals,omitempty"`
+}
+
+// CloneOrdinalStatus is the clone progress of one ordinal.
+type CloneOrdinalStatus struct {
+	// Ordinal of the pod.
+	Ordinal int32 `json:"ordinal"`
+
+	// Claims is the number of PVCs of this ordinal.
+	Claims int32 `json:"claims"`
+
+	// BoundClaims is the number of those PVCs that are Bound.
+	BoundClaims int32 `json:"boundClaims"`
+
+	// Cloned is true once every PVC of this ordinal is Bound.
+	Cloned bool `json:"cloned"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:shortName=kmsc
+//+kubebuilder:printcolumn:name="Source",type="string",JSONPath=".spec.source.name"
+//+kubebuilder:printcolumn:name="Target",type="string",JSONPath=".spec.targetName"
+//+kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// MyStatefulsetClone is the Schema for the mystatefulsetclones API.
+// It creates a copy of a MyStatefulset, cloning its PVCs ordinal by ordinal.
+type MyStatefulsetClone struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   MyStatefulsetCloneSpec   `json:"spec,omitempty"`
+	Status MyStatefulsetCloneStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// MyStatefulsetCloneList contains a list of MyStatefulsetClone
+type MyStatefulsetCloneList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MyStatefulsetClone `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&MyStatefulsetClone{}, &MyStatefulsetCloneList{})
+}
+
+// GetSourceNamespace 返回源对象所在的命名空间，未设置时为克隆对象的命名空间
+func (c *MyStatefulsetClone) GetSourceNamespace() string {
+	if c.Spec.Source.Namespace != "" {
+		return c.Spec.Source.Namespace
+	}
+	return c.Namespace
+}
+
+// GetServiceName 返回新对象使用的 headless service 名称
+func (c *MyStatefulsetClone) GetServiceName() string {
+	if c.Spec.ServiceName != "" {
+		return c.Spec.ServiceName
+	}
+	return c.Spec.TargetName
+}
+
+// BuildTarget 根据源对象生成新的 MyStatefulset：复制模板和 PVC 模板，改写 serviceName
+// 和选择器标签，并为每个 PVC 模板设置从源对象克隆的数据源
+func (c *MyStatefulsetClone) BuildTarget(source *MyStatefulset) *MyStatefulset {
+	spec := source.Spec.DeepCopy()
+	spec.ServiceName = c.GetServiceName()
+	if c.Spec.Replicas != nil {
+		replicas := *c.Spec.Replicas
+		spec.Replicas = &replicas
+	}
+
+	// 源对象选择器用到的标签在新对象上改写为 targetName，使源对象以及选中源对象 Pod 的
+	// Service 都不会选中新 Pod；额外的克隆标签保证新对象不会选中源对象的 Pod
+	if spec.Template.Labels == nil {
+		spec.Template.Labels = map[string]string{}
+	}
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{}}
+	if spec.Selector != nil {
+		for key := range spec.Selector.MatchLabels {
+			selector.MatchLabels[key] = c.Spec.TargetName
+			spec.Template.Labels[key] = c.Spec.TargetName
+		}
+		for _, requirement := range spec.Selector.MatchExpressions {
+			if _, ok := spec.Template.Labels[requirement.Key]; ok {
+				selector.MatchLabels[requirement.Key] = c.Spec.TargetName
+				spec.Template.Labels[requirement.Key] = c.Spec.TargetName
+				continue
+			}
+			selector.MatchExpressions = append(selector.MatchExpressions, requirement)
+		}
+	}
+	selector.MatchLabels[CloneLabel] = c.Spec.TargetName
+	spec.Template.Labels[CloneLabel] = c.Spec.TargetName
+	spec.Selector = selector
+
+	// 源对象的数据源只对源对象有意义
+	spec.VolumeClaimDataSources = nil
+	if !c.Spec.SkipData {
+		for _, pvcTemplate := range spec.VolumeClaimTemplates {
+			spec.VolumeClaimDataSources = append(spec.VolumeClaimDataSources, VolumeClaimDataSource{
+				ClaimTemplate: ClaimVolumeName(pvcTemplate),
+				SourceSet:     source.Name,
+			})
+		}
+	}
+
+	labels := make(map[string]string, len(source.Labels))
+	for k, v := range source.Labels {
+		labels[k] = v
+	}
+	return &MyStatefulset{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        c.Spec.TargetName,
+			Namespace:   c.Namespace,
+			Labels:      labels,
+			Annotations: map[string]string{CloneSourceAnnotation: c.Namespace + "/" + c.Name},
+		},
+		Spec: *spec,
+	}
+}
@@ -0,0 +1,194 @@
+/*
+Copyright 2024 bryant-rh.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// GroupRevisionAnnotation is set on a member MyStatefulset to the revision
+// of the group's templatePatch once the patch has been applied to it.
+const GroupRevisionAnnotation = "apps.mystatefulset.com/group-revision"
+
+// GroupPreviousTemplateAnnotation holds the member's pod template from
+// before the patch was applied, used to revert the member on abort.
+const GroupPreviousTemplateAnnotation = "apps.mystatefulset.com/group-previous-template"
+
+// GroupRevertedAnnotation is set on a member MyStatefulset to the revision
+// that was reverted on abort.
+const GroupRevertedAnnotation = "apps.mystatefulset.com/group-reverted"
+
+// MyStatefulsetGroupSpec describes a template patch to roll out across a set
+// of MyStatefulsets, a few members at a time.
+type MyStatefulsetGroupSpec struct {
+	// Selector selects the member MyStatefulsets in the group's namespace.
+	// Members are updated in name order.
+	Selector *metav1.LabelSelector `json:"selector"`
+
+	// TemplatePatch is a strategic merge patch applied to spec.template of
+	// every member, e.g. {"spec":{"containers":[{"name":"db","image":"db:2"}]}}.
+	// Changing it starts a new rollout.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Type=object
+	TemplatePatch runtime.RawExtension `json:"templatePatch"`
+
+	// MaxConcurrentMembers is the number of members updated at the same
+	// time. The next member is patched once an updated one is Available.
+	// Defaults to 1.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxConcurrentMembers *int32 `json:"maxConcurrentMembers,omitempty"`
+
+	// Paused stops patching further members. Members already patched keep
+	// rolling out. It does not hold back Abort.
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+
+	// Abort stops the rollout and restores the previous pod template of the
+	// members that were patched, in reverse order and at most
+	// MaxConcurrentMembers at a time.
+	// +optional
+	Abort bool `json:"abort,omitempty"`
+}
+
+// GroupPhase is the rollout phase of a MyStatefulsetGroup.
+// +kubebuilder:validation:Enum=Progressing;Paused;Completed;Aborting;Aborted;Failed
+type GroupPhase string
+
+const (
+	// GroupPhaseProgressing means members are being patched.
+	GroupPhaseProgressing GroupPhase = "Progressing"
+	// GroupPhasePaused means spec.paused holds back the remaining members.
+	GroupPhasePaused GroupPhase = "Paused"
+	// GroupPhaseCompleted means every member is patched and Available.
+	GroupPhaseCompleted GroupPhase = "Completed"
+	// GroupPhaseAborting means patched members are being reverted.
+	GroupPhaseAborting GroupPhase = "Aborting"
+	// GroupPhaseAborted means every patched member has been reverted.
+	GroupPhaseAborted GroupPhase = "Aborted"
+	// GroupPhaseFailed means the patch cannot be applied; see Message.
+	GroupPhaseFailed GroupPhase = "Failed"
+)
+
+// MemberRolloutState is the rollout state of one member.
+// +kubebuilder:validation:Enum=Pending;Updating;Updated;Reverting;Reverted
+type MemberRolloutState string
+
+const (
+	// MemberPending means the patch has not been applied yet.
+	MemberPending MemberRolloutState = "Pending"
+	// MemberUpdating means the patch is applied and the member is not
+	// Available for its new generation yet.
+	MemberUpdating MemberRolloutState = "Updating"
+	// MemberUpdated means the patch is applied and every replica is updated
+	// and available.
+	MemberUpdated MemberRolloutState = "Updated"
+	// MemberReverting means the previous template is restored and the member
+	// is not Available yet.
+	MemberReverting MemberRolloutState = "Reverting"
+	// MemberReverted means the previous template is restored and Available.
+	MemberReverted MemberRolloutState = "Reverted"
+)
+
+// MyStatefulsetGroupStatus reports the rollout across the members.
+type MyStatefulsetGroupStatus struct {
+	// ObservedGeneration is the most recent generation observed for this group.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Revision is the hash of the templatePatch being rolled out.
+	// +optional
+	Revision string `json:"revision,omitempty"`
+
+	// Phase of the rollout.
+	// +optional
+	Phase GroupPhase `json:"phase,omitempty"`
+
+	// Message explains the phase.
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// TotalMembers is the number of selected members.
+	// +optional
+	TotalMembers int32 `json:"totalMembers,omitempty"`
+
+	// UpdatedMembers is the number of members in the Updated state.
+	// +optional
+	UpdatedMembers int32 `json:"updatedMembers,omitempty"`
+
+	// Members reports the rollout state of each member in update order.
+	// +optional
+	Members []GroupMemberStatus `json:"members,omitempty"`
+}
+
+// GroupMemberStatus is the rollout state of one member.
+type GroupMemberStatus struct {
+	// Name of the member MyStatefulset.
+	Name string `json:"name"`
+
+	// State of the member in the rollout.
+	State MemberRolloutState `json:"state"`
+
+	// Replicas is spec.replicas of the member.
+	Replicas int32 `json:"replicas"`
+
+	// UpdatedReplicas is status.updatedReplicas of the member.
+	UpdatedReplicas int32 `json:"updatedReplicas"`
+
+	// AvailableReplicas is status.availableReplicas of the member.
+	AvailableReplicas int32 `json:"availableReplicas"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:shortName=kmsg
+//+kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+//+kubebuilder:printcolumn:name="Updated",type="integer",JSONPath=".status.updatedMembers"
+//+kubebuilder:printcolumn:name="Members",type="integer",JSONPath=".status.totalMembers"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// MyStatefulsetGroup is the Schema for the mystatefulsetgroups API.
+// It rolls a shared pod template patch out to its members a few at a time.
+type MyStatefulsetGroup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   MyStatefulsetGroupSpec   `json:"spec,omitempty"`
+	Status MyStatefulsetGroupStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// MyStatefulsetGroupList contains a list of MyStatefulsetGroup
+type MyStatefulsetGroupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MyStatefulsetGroup `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&MyStatefulsetGroup{}, &MyStatefulsetGroupList{})
+}
+
+// GetMaxConcurrentMembers 返回同时更新的成员数，未设置时为 1
+func (g *MyStatefulsetGroup) GetMaxConcurrentMembers() int {
+	if g.Spec.MaxConcurrentMembers == nil || *g.Spec.MaxConcurrentMembers < 1 {
+		return 1
+	}
+	return int(*g.Spec.MaxConcurrentMembers)
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2024 bryant-rh.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// MyStatefulsetPolicySpec defines the admission rules enforced by the
+// MyStatefulset validating webhook.
+//
+// Every rule is optional. A rule that is set replaces the built-in default for
+// that rule as a whole; a rule that is left unset keeps the default (or the
+// value from a lower-precedence policy).
+type MyStatefulsetPolicySpec struct {
+	// NamespaceSelector restricts the policy to MyStatefulsets in namespaces
+	// whose labels match. A nil selector makes the policy cluster-wide.
+	// Namespace-scoped policies take precedence over cluster-wide ones; within
+	// the same scope, policies are applied in name order and later ones win.
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
+	// MaxReplicas caps spec.replicas.
+	// +optional
+	MaxReplicas *MaxReplicasRule `json:"maxReplicas,omitempty"`
+
+	// ReplicaIncrease limits how much spec.replicas may grow in a single update.
+	// +optional
+	ReplicaIncrease *ReplicaIncreaseRule `json:"replicaIncrease,omitempty"`
+
+	// ResourceLimitDecrease forbids lowering container CPU and memory limits.
+	// +optional
+	ResourceLimitDecrease *ResourceLimitDecreaseRule `json:"resourceLimitDecrease,omitempty"`
+
+	// ImageChange forbids moving a container from one image line to another,
+	// for example from a production image to a test image.
+	// +optional
+	ImageChange *ImageChangeRule `json:"imageChange,omitempty"`
+
+	// DeletionProtection blocks deleting a MyStatefulset that still owns Bound
+	// PersistentVolumeClaims, unless the set is annotated with
+	// apps.mystatefulset.com/confirm-delete set to its UID.
+	// +optional
+	DeletionProtection *DeletionProtectionRule `json:"deletionProtection,omitempty"`
+
+	// Validations are CEL guardrails evaluated in addition to the rules above.
+	// Unlike the other rules, validations from every matching policy apply.
+	// +optional
+	Validations []ValidationRule `json:"validations,omitempty"`
+}
+
+// MaxReplicasRule caps the number of replicas of a MyStatefulset.
+type MaxReplicasRule struct {
+	// Enabled turns the rule on or off.
+	Enabled bool `json:"enabled"`
+
+	// Limit is the highest allowed value of spec.replicas.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	Limit int32 `json:"limit,omitempty"`
+}
+
+// ReplicaIncreaseRule limits the relative growth of spec.replicas in one update.
+type ReplicaIncreaseRule struct {
+	// Enabled turns the rule on or off.
+	Enabled bool `json:"enabled"`
+
+	// MaxPercent is the largest allowed increase, in percent of the old
+	// replica count. 100 allows doubling. Scaling up from zero is never limited.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	MaxPercent int32 `json:"maxPercent,omitempty"`
+}
+
+// ResourceLimitDecreaseRule forbids lowering container resource limits.
+type ResourceLimitDecreaseRule struct {
+	// Enabled turns the rule on or off.
+	Enabled bool `json:"enabled"`
+}
+
+// ImageChangeRule forbids updating a container image from one matching
+// FromSubstring to one matching ToSubstring.
+type ImageChangeRule struct {
+	// Enabled turns the rule on or off.
+	Enabled bool `json:"enabled"`
+
+	// FromSubstring is matched against the old image.
+	// +optional
+	FromSubstring string `json:"fromSubstring,omitempty"`
+
+	// ToSubstring is matched against the new image.
+	// +optional
+	ToSubstring string `json:"toSubstring,omitempty"`
+}
+
+// DeletionProtectionRule guards MyStatefulsets that still hold data.
+type DeletionProtectionRule struct {
+	// Enabled turns the rule on or off.
+	Enabled bool `json:"enabled"`
+}
+
+// ValidationRule is a CEL expression that a MyStatefulset must satisfy.
+type ValidationRule struct {
+	// Expression must evaluate to true for the object to be admitted.
+	// `self` is the MyStatefulset being admitted and `oldSelf` the stored one,
+	// e.g. `oldSelf.spec.replicas - self.spec.replicas <= 3`. Rules that refer
+	// to `oldSelf` are only evaluated on update.
+	// +kubebuilder:validation:MinLength=1
+	Expression string `json:"expression"`
+
+	// Message is returned when the expression evaluates to false.
+	// Defaults to "failed rule: <expression>".
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// FieldPath is the path the error is reported at, for example
+	// `spec.replicas` or `spec.template.spec.containers[0].image`.
+	// Defaults to `spec`.
+	// +optional
+	FieldPath string `json:"fieldPath,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:path=mystatefulsetpolicies,scope=Cluster,shortName=kmsp
+
+// MyStatefulsetPolicy is the Schema for the mystatefulsetpolicies API.
+// Policies are read by the validating webhook through the manager cache,
+// so changes take effect without restarting the webhook.
+type MyStatefulsetPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec MyStatefulsetPolicySpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// MyStatefulsetPolicyList contains a list of MyStatefulsetPolicy
+type MyStatefulsetPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MyStatefulsetPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&MyStatefulsetPolicy{}, &MyStatefulsetPolicyList{})
+}
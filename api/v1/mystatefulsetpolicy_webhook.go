@@ -0,0 +1,68 @@
+package v1
+
+import (
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// SetupWebhookWithManager 注册 MyStatefulsetPolicy 的校验 webhook
+func (r *MyStatefulsetPolicy) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-apps-mystatefulset-com-v1-mystatefulsetpolicy,mutating=false,failurePolicy=fail,sideEffects=None,groups=apps.mystatefulset.com,resources=mystatefulsetpolicies,verbs=create;update,versions=v1,name=vmystatefulsetpolicy.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Validator = &MyStatefulsetPolicy{}
+
+// ValidateCreate 实现了 webhook.Validator 接口
+func (r *MyStatefulsetPolicy) ValidateCreate() error {
+	return r.validatePolicy()
+}
+
+// ValidateUpdate 实现了 webhook.Validator 接口
+func (r *MyStatefulsetPolicy) ValidateUpdate(old runtime.Object) error {
+	return r.validatePolicy()
+}
+
+// ValidateDelete 实现了 webhook.Validator 接口，删除策略不做限制
+func (r *MyStatefulsetPolicy) ValidateDelete() error {
+	return nil
+}
+
+// validatePolicy 在策略写入时编译 CEL 规则并检查 namespaceSelector，
+// 避免错误的策略在 MyStatefulset 准入时才暴露，导致所有更新都被拒绝
+func (r *MyStatefulsetPolicy) validatePolicy() error {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	if r.Spec.NamespaceSelector != nil {
+		allErrs = append(allErrs, metav1validation.ValidateLabelSelector(
+			r.Spec.NamespaceSelector, specPath.Child("namespaceSelector"))...)
+	}
+
+	for i, rule := range r.Spec.Validations {
+		if _, err := compileCELRule(rule.Expression); err != nil {
+			allErrs = append(allErrs, field.Invalid(
+				specPath.Child("validations").Index(i).Child("expression"),
+				rule.Expression,
+				fmt.Sprintf("invalid validation rule: %v", err)))
+		}
+	}
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(
+		schema.GroupKind{Group: GroupVersion.Group, Kind: "MyStatefulsetPolicy"},
+		r.Name,
+		allErrs)
+}
@@ -0,0 +1,79 @@
+package v1
+
+import (
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMyStatefulsetPolicy_validatePolicy(t *testing.T) {
+	tests := []struct {
+		name       string
+		spec       MyStatefulsetPolicySpec
+		wantFields []string
+	}{
+		{
+			name: "valid rules",
+			spec: MyStatefulsetPolicySpec{
+				NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"env": "dev"}},
+				Validations: []ValidationRule{
+					{Expression: "oldSelf.spec.replicas - self.spec.replicas <= 3"},
+					{Expression: "self.spec.template.spec.containers.all(c, c.image.startsWith('nginx'))"},
+				},
+			},
+		},
+		{
+			name: "syntax error",
+			spec: MyStatefulsetPolicySpec{
+				Validations: []ValidationRule{
+					{Expression: "self.spec.replicas > 1"},
+					{Expression: "self.spec.replicas <"},
+				},
+			},
+			wantFields: []string{"spec.validations[1].expression"},
+		},
+		{
+			name: "non-bool result",
+			spec: MyStatefulsetPolicySpec{
+				Validations: []ValidationRule{{Expression: "1 + 2"}},
+			},
+			wantFields: []string{"spec.validations[0].expression"},
+		},
+		{
+			name: "invalid namespace selector",
+			spec: MyStatefulsetPolicySpec{
+				NamespaceSelector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "env", Operator: "Bogus"},
+				}},
+			},
+			wantFields: []string{"spec.namespaceSelector.matchExpressions[0].operator"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := &MyStatefulsetPolicy{ObjectMeta: metav1.ObjectMeta{Name: "test"}, Spec: tt.spec}
+			err := policy.ValidateCreate()
+			if len(tt.wantFields) == 0 {
+				if err != nil {
+					t.Fatalf("ValidateCreate() unexpected error = %v", err)
+				}
+				return
+			}
+			statusErr, ok := err.(*apierrors.StatusError)
+			if !ok {
+				t.Fatalf("ValidateCreate() error = %v, want an Invalid error", err)
+			}
+			causes := statusErr.ErrStatus.Details.Causes
+			if len(causes) != len(tt.wantFields) {
+				t.Fatalf("ValidateCreate() causes = %v, want %v", causes, tt.wantFields)
+			}
+			for i, cause := range causes {
+				if cause.Field != tt.wantFields[i] {
+					t.Errorf("ValidateCreate() cause field = %s, want %s", cause.Field, tt.wantFields[i])
+				}
+			}
+		})
+	}
+}
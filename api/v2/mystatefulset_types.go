@@ -0,0 +1,630 @@
+/*
+Copyright 2024 bryant-rh.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// MyStatefulsetSpec defines the desired state of MyStatefulset
+type MyStatefulsetSpec struct {
+	// Replicas is the desired number of replicas of the given Template.
+	// These are replicas in the sense that they are instantiations of the
+	// same Template, but individual replicas also have a consistent identity.
+	// Unset is defaulted to 1.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=1
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// ServiceName is the name of the service that governs this MyStatefulset.
+	// This service must exist before the MyStatefulset, and is responsible for
+	// the network identity of the set.
+	// +kubebuilder:validation:Required
+	ServiceName string `json:"serviceName"`
+
+	// Selector is a label query over pods that should match the replica count.
+	// It must match the pod template's labels.
+	// +kubebuilder:validation:Required
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// Template is the object that describes the pod that will be created if
+	// insufficient replicas are detected. Each pod stamped out by the MyStatefulset
+	// will fulfill this Template, but have a unique identity from the rest
+	// of the MyStatefulset.
+	// +kubebuilder:validation:Required
+	Template PodTemplateSpec `json:"template,omitempty"`
+
+	// VolumeClaimTemplates is a list of claims that pods are allowed to reference.
+	// +optional
+	VolumeClaimTemplates []corev1.PersistentVolumeClaim `json:"volumeClaimTemplates,omitempty"`
+
+	// VolumeClaimDataSources pre-populate the PVCs created from
+	// VolumeClaimTemplates, for restoring from snapshots or cloning another
+	// set. Each entry applies to one claim template.
+	// +optional
+	VolumeClaimDataSources []VolumeClaimDataSource `json:"volumeClaimDataSources,omitempty"`
+
+	// OrdinalOverrides patch the pod template for selected ordinals, for
+	// example a larger memory limit on the primary (ordinal 0). Overrides are
+	// applied in order on top of Template, so a later override wins. A change to
+	// an override only rolls the pods of the ordinals it selects.
+	// +optional
+	OrdinalOverrides []OrdinalOverride `json:"ordinalOverrides,omitempty"`
+
+	// Placement spreads the pods across topology domains (zones by default).
+	// +optional
+	Placement *PlacementSpec `json:"placement,omitempty"`
+
+	// Rollout controls how pods are replaced when Template changes.
+	// It replaces the v1 updateStrategy field.
+	// +optional
+	Rollout RolloutSpec `json:"rollout,omitempty"`
+
+	// MinReadySeconds is the minimum number of seconds for which a newly created pod should be ready
+	// without any of its container crashing, for it to be considered available.
+	// +optional
+	MinReadySeconds int32 `json:"minReadySeconds,omitempty"`
+
+	// NodeFailurePolicy lets the controller rebuild a replica elsewhere when its
+	// node stays NotReady or is deleted, for example a pod pinned by local
+	// storage to a dead node. Unset disables recovery.
+	// +optional
+	NodeFailurePolicy *NodeFailurePolicy `json:"nodeFailurePolicy,omitempty"`
+
+	// PodPendingDeadlineSeconds is how long a pod may stay Pending before it is
+	// deleted and recreated. Unset or 0 disables the deadline.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	PodPendingDeadlineSeconds *int32 `json:"podPendingDeadlineSeconds,omitempty"`
+
+	// ScaleDownDrainSeconds is how long a pod being removed by a scale-down is
+	// left to drain before it is deleted. The pod is annotated with
+	// apps.mystatefulset.com/draining-since when draining starts and is deleted
+	// early once it reports the apps.mystatefulset.com/drained condition as
+	// True. Pods are always removed one ordinal at a time, highest first;
+	// unset or 0 deletes them without draining.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	ScaleDownDrainSeconds *int32 `json:"scaleDownDrainSeconds,omitempty"`
+
+	// SnapshotPolicy takes VolumeSnapshots of an ordinal's PVCs before the
+	// ordinal is rolled to a new template.
+	// +optional
+	SnapshotPolicy *SnapshotPolicy `json:"snapshotPolicy,omitempty"`
+
+	// ScalingSchedule sets spec.replicas at the times given by cron schedules,
+	// for example 3 replicas at 08:00 on weekdays and 0 at 20:00 every day.
+	// When a boundary is crossed, spec.replicas is set to the replicas of the
+	// entry that fired last. A manual scale between two boundaries is kept
+	// until the next boundary.
+	// +optional
+	ScalingSchedule []ScheduledScaling `json:"scalingSchedule,omitempty"`
+
+	// Autoscaling lets the controller drive spec.replicas from pod CPU and
+	// memory utilization. Scale-up is applied at once; scale-down removes one
+	// ordinal at a time. It cannot be combined with ScalingSchedule.
+	// +optional
+	Autoscaling *AutoscalingSpec `json:"autoscaling,omitempty"`
+
+	// DependsOn lists objects that must be available before pods are created
+	// or updated, for example the database MyStatefulset of an app tier.
+	// For kinds other than MyStatefulset the controller's service account
+	// needs get on the kind, which the bundled RBAC does not grant. With list
+	// and watch across all namespaces as well, the MyStatefulset is
+	// reconciled as soon as the dependency changes; otherwise the dependency
+	// is checked every 30 seconds.
+	// +optional
+	DependsOn []Dependency `json:"dependsOn,omitempty"`
+
+	// Suspend deletes every pod in reverse ordinal order and keeps the PVCs.
+	// spec.replicas is left unchanged, and pods are recreated in ordinal
+	// order once Suspend is set back to false. The scale subresource rejects
+	// changes while the MyStatefulset is suspended.
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+
+	// FastDelete skips the ordered, one-pod-at-a-time teardown on foreground
+	// and background deletion and deletes all pods and PVCs at once.
+	// +optional
+	FastDelete bool `json:"fastDelete,omitempty"`
+}
+
+type PodTemplateSpec struct {
+	// +optional
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:XPreserveUnknownFields
+	metav1.ObjectMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	Spec corev1.PodSpec `json:"spec,omitempty" protobuf:"bytes,2,opt,name=spec"`
+}
+
+// PlacementSpec spreads pods across the domains of a topology key.
+//
+// With Zones set, ordinal i is pinned to Zones[i mod len(Zones)] through a
+// required node affinity, so consecutive ordinals never share a zone. Without
+// Zones, a topologySpreadConstraint with maxSkew 1 is added instead.
+type PlacementSpec struct {
+	// TopologyKey is the node label that identifies a domain.
+	// Defaults to topology.kubernetes.io/zone.
+	// +optional
+	TopologyKey string `json:"topologyKey,omitempty"`
+
+	// Zones is the ordered list of domains pods are assigned to by ordinal.
+	// +optional
+	Zones []string `json:"zones,omitempty"`
+}
+
+// VolumeClaimDataSource is the per-ordinal data source of a claim template.
+// Exactly one of VolumeSnapshotName and SourceSet must be set.
+type VolumeClaimDataSource struct {
+	// ClaimTemplate is the name of the volumeClaimTemplate ("www" if unnamed).
+	ClaimTemplate string `json:"claimTemplate"`
+
+	// VolumeSnapshotName is the name pattern of the VolumeSnapshot to restore
+	// each ordinal from; {{ordinal}} is replaced by the ordinal, e.g. db-snap-{{ordinal}}.
+	// +optional
+	VolumeSnapshotName string `json:"volumeSnapshotName,omitempty"`
+
+	// SourceSet is the name of a MyStatefulset in the same namespace whose PVC
+	// of the same claim template and ordinal is cloned.
+	// +optional
+	SourceSet string `json:"sourceSet,omitempty"`
+}
+
+// OrdinalOverride is a strategic-merge patch applied to the pod template of
+// the selected ordinals.
+type OrdinalOverride struct {
+	// Ordinals lists individual ordinals the override applies to.
+	// +optional
+	Ordinals []int32 `json:"ordinals,omitempty"`
+
+	// Ranges lists ordinal ranges the override applies to.
+	// +optional
+	Ranges []OrdinalRange `json:"ranges,omitempty"`
+
+	// Patch is a strategic-merge patch applied to spec.template, for example
+	// {"spec":{"containers":[{"name":"db","resources":{"limits":{"memory":"4Gi"}}}]}}.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Type=object
+	Patch runtime.RawExtension `json:"patch"`
+}
+
+// OrdinalRange is an inclusive range of ordinals.
+type OrdinalRange struct {
+	// Start is the first ordinal of the range.
+	// +kubebuilder:validation:Minimum=0
+	Start int32 `json:"start"`
+
+	// End is the last ordinal of the range. Unset means no upper bound.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	End *int32 `json:"end,omitempty"`
+}
+
+// RolloutStrategyType enumerates the ways pods are replaced on a template change.
+// +kubebuilder:validation:Enum=RollingUpdate;OnDelete
+type RolloutStrategyType string
+
+const (
+	// RollingUpdateRolloutStrategyType replaces pods in reverse ordinal order.
+	RollingUpdateRolloutStrategyType RolloutStrategyType = "RollingUpdate"
+	// OnDeleteRolloutStrategyType only replaces pods that are deleted by the user.
+	OnDeleteRolloutStrategyType RolloutStrategyType = "OnDelete"
+)
+
+// RolloutSpec defines how a MyStatefulset rolls out a new template.
+type RolloutSpec struct {
+	// Strategy is the rollout strategy. Defaults to RollingUpdate.
+	// +optional
+	Strategy RolloutStrategyType `json:"strategy,omitempty"`
+
+	// RollingUpdate tunes the RollingUpdate strategy.
+	// +optional
+	RollingUpdate *RollingUpdateRollout `json:"rollingUpdate,omitempty"`
+
+	// Paused stops the rollout; pods keep running the revision they have.
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+}
+
+// RollingUpdateRollout tunes a RollingUpdate rollout.
+type RollingUpdateRollout struct {
+	// Partition is the ordinal at which the rollout starts; pods with a lower
+	// ordinal keep the old template. Defaults to 0.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	Partition *int32 `json:"partition,omitempty"`
+
+	// MaxUnavailable is the maximum number of pods that can be unavailable
+	// during the rollout, as an absolute number or a percentage of replicas.
+	// Defaults to 1.
+	// +optional
+	// +kubebuilder:validation:XIntOrString
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+}
+
+// NodeFailurePolicy controls how pods on lost nodes are recovered.
+type NodeFailurePolicy struct {
+	// GracePeriodSeconds is how long a node must be NotReady, or the pod must
+	// be stuck on a deleted node, before the pod is force deleted. Defaults to 300.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=300
+	GracePeriodSeconds *int32 `json:"gracePeriodSeconds,omitempty"`
+
+	// DeletePVC also deletes the PVCs of the recovered pod, so the replica is
+	// rebuilt with new storage on another node. Use it for node-local volumes.
+	// +optional
+	DeletePVC bool `json:"deletePVC,omitempty"`
+
+	// MaxConcurrentRecoveries is the maximum number of replicas that may be
+	// recovering at the same time. Defaults to 1.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=1
+	MaxConcurrentRecoveries *int32 `json:"maxConcurrentRecoveries,omitempty"`
+}
+
+// SnapshotPolicy controls the VolumeSnapshots taken before rolling updates.
+type SnapshotPolicy struct {
+	// Enabled turns on pre-update snapshots.
+	Enabled bool `json:"enabled"`
+
+	// VolumeSnapshotClassName is the VolumeSnapshotClass of the snapshots.
+	// Unset uses the default class of the CSI driver.
+	// +optional
+	VolumeSnapshotClassName *string `json:"volumeSnapshotClassName,omitempty"`
+
+	// Retain is the number of snapshots kept per PVC. Defaults to 3.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=3
+	Retain *int32 `json:"retain,omitempty"`
+}
+
+// ScheduledScaling sets the replicas at the times of a cron schedule.
+type ScheduledScaling struct {
+	// Name identifies the entry in status and events. Defaults to the schedule.
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Schedule is a standard five-field cron expression
+	// (minute hour day-of-month month day-of-week), e.g. "0 8 * * MON-FRI".
+	// Macros such as @daily are accepted.
+	Schedule string `json:"schedule"`
+
+	// TimeZone is the IANA time zone of the schedule, e.g. Europe/Berlin.
+	// Defaults to UTC.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+
+	// Replicas is the value spec.replicas is set to when the schedule fires.
+	// +kubebuilder:validation:Minimum=0
+	Replicas int32 `json:"replicas"`
+}
+
+// ScalingScheduleStatus is the state of spec.scalingSchedule.
+type ScalingScheduleStatus struct {
+	// ActiveEntry is the entry that fired last.
+	// +optional
+	ActiveEntry string `json:"activeEntry,omitempty"`
+	// ScheduledReplicas is the replicas of the active entry.
+	ScheduledReplicas int32 `json:"scheduledReplicas"`
+	// LastScheduleTime is when the active entry fired. spec.replicas is only
+	// set again once a later boundary is reached.
+	// +optional
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+	// NextEntry is the entry that fires next.
+	// +optional
+	NextEntry string `json:"nextEntry,omitempty"`
+	// NextReplicas is the replicas of the next entry.
+	// +optional
+	NextReplicas int32 `json:"nextReplicas,omitempty"`
+	// NextScheduleTime is when the next entry fires.
+	// +optional
+	NextScheduleTime *metav1.Time `json:"nextScheduleTime,omitempty"`
+	// Overridden is true when spec.replicas was changed manually after the
+	// active entry fired, or the admission webhook rejected the change.
+	Overridden bool `json:"overridden"`
+}
+
+// AutoscalingSpec configures metric-driven scaling from the metrics.k8s.io API.
+// Utilization is usage divided by the container requests of ready pods.
+type AutoscalingSpec struct {
+	// MinReplicas is the lower bound of spec.replicas. Defaults to 1.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+
+	// MaxReplicas is the upper bound of spec.replicas.
+	// +kubebuilder:validation:Minimum=1
+	MaxReplicas int32 `json:"maxReplicas"`
+
+	// TargetCPUUtilizationPercentage is the target average CPU utilization.
+	// Defaults to 80 when no target is set.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	TargetCPUUtilizationPercentage *int32 `json:"targetCPUUtilizationPercentage,omitempty"`
+
+	// TargetMemoryUtilizationPercentage is the target average memory utilization.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	TargetMemoryUtilizationPercentage *int32 `json:"targetMemoryUtilizationPercentage,omitempty"`
+
+	// ScaleUpStabilizationWindowSeconds is how long a higher replica count must
+	// be recommended before scaling up. Defaults to 0.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	ScaleUpStabilizationWindowSeconds *int32 `json:"scaleUpStabilizationWindowSeconds,omitempty"`
+
+	// ScaleDownStabilizationWindowSeconds is how long a lower replica count
+	// must be recommended before scaling down. Defaults to 300.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	ScaleDownStabilizationWindowSeconds *int32 `json:"scaleDownStabilizationWindowSeconds,omitempty"`
+}
+
+// AutoscalingStatus is the state of spec.autoscaling.
+type AutoscalingStatus struct {
+	// CurrentCPUUtilizationPercentage is the last observed average CPU utilization.
+	// +optional
+	CurrentCPUUtilizationPercentage *int32 `json:"currentCPUUtilizationPercentage,omitempty"`
+	// CurrentMemoryUtilizationPercentage is the last observed average memory utilization.
+	// +optional
+	CurrentMemoryUtilizationPercentage *int32 `json:"currentMemoryUtilizationPercentage,omitempty"`
+	// DesiredReplicas is the latest recommendation before stabilization.
+	DesiredReplicas int32 `json:"desiredReplicas"`
+	// Recommendations within the longest stabilization window, oldest first.
+	// +optional
+	Recommendations []ReplicaRecommendation `json:"recommendations,omitempty"`
+	// LastScaleTime is when the autoscaler last changed spec.replicas.
+	// +optional
+	LastScaleTime *metav1.Time `json:"lastScaleTime,omitempty"`
+	// LastRejection is the last spec.replicas change rejected by the
+	// admission webhook, for example by a stricter replica increase policy.
+	// It is cleared by the next successful scale.
+	// +optional
+	LastRejection *AutoscalingRejection `json:"lastRejection,omitempty"`
+}
+
+// AutoscalingRejection is a spec.replicas change the autoscaler could not
+// make.
+type AutoscalingRejection struct {
+	// Replicas is the rejected replica count.
+	Replicas int32 `json:"replicas"`
+	// Message is the admission error.
+	Message string `json:"message"`
+	// Time is when the change was rejected.
+	Time metav1.Time `json:"time"`
+}
+
+// ScaleDownStatus is the state of a scale-down in progress.
+type ScaleDownStatus struct {
+	// PendingReplicas is the number of pods at or above spec.replicas that
+	// are not deleted yet.
+	PendingReplicas int32 `json:"pendingReplicas"`
+	// DrainingPod is the pod being drained before it is deleted.
+	// +optional
+	DrainingPod string `json:"drainingPod,omitempty"`
+	// DrainStartTime is when DrainingPod started draining.
+	// +optional
+	DrainStartTime *metav1.Time `json:"drainStartTime,omitempty"`
+}
+
+// ReplicaRecommendation is a replica count recommended at a point in time.
+type ReplicaRecommendation struct {
+	Replicas int32       `json:"replicas"`
+	Time     metav1.Time `json:"time"`
+}
+
+// Dependency references an object the MyStatefulset waits for.
+// Without APIVersion and Kind it is another MyStatefulset, which is available
+// when its Available condition is True for its current generation.
+type Dependency struct {
+	// APIVersion of a generic object, e.g. apps/v1.
+	// +optional
+	APIVersion string `json:"apiVersion,omitempty"`
+
+	// Kind of a generic object, e.g. Deployment.
+	// +optional
+	Kind string `json:"kind,omitempty"`
+
+	// Namespace of the object. Defaults to the namespace of the MyStatefulset.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name of the object.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// ConditionType is the status condition that must be True.
+	// Defaults to Available.
+	// +optional
+	ConditionType string `json:"conditionType,omitempty"`
+}
+
+// ZoneReplicas is the number of pods scheduled in a topology domain.
+type ZoneReplicas struct {
+	Zone     string `json:"zone"`
+	Replicas int32  `json:"replicas"`
+}
+
+// PodFailure is the failure history of one ordinal.
+type PodFailure struct {
+	Ordinal int32 `json:"ordinal"`
+	// Count is the number of consecutive failures of the ordinal.
+	Count int32 `json:"count"`
+	// Reason is the reason of the last failure, e.g. Evicted or PendingTimeout.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// LastFailureTime is when the last failure was detected.
+	// +optional
+	LastFailureTime metav1.Time `json:"lastFailureTime,omitempty"`
+	// PodUID is the UID of the failed pod, so a failure is only counted once.
+	// +optional
+	PodUID types.UID `json:"podUID,omitempty"`
+}
+
+// PodStatusDetail is the observed state of the pod of one ordinal.
+type PodStatusDetail struct {
+	Ordinal int32  `json:"ordinal"`
+	PodName string `json:"podName"`
+	// +optional
+	Phase corev1.PodPhase `json:"phase,omitempty"`
+	Ready bool            `json:"ready"`
+	// Available is true when the pod has been ready for minReadySeconds.
+	Available bool `json:"available"`
+	// Revision is the controller-revision-hash label of the pod.
+	// +optional
+	Revision string `json:"revision,omitempty"`
+	// Updated is true when the pod runs the current template of its ordinal.
+	Updated bool `json:"updated"`
+	// +optional
+	NodeName string `json:"nodeName,omitempty"`
+	// VolumeClaimPhase is Bound when all PVCs of the ordinal are bound, otherwise
+	// the phase of the first unbound PVC, or Missing if a PVC does not exist.
+	// +optional
+	VolumeClaimPhase string `json:"volumeClaimPhase,omitempty"`
+	// LastTransitionTime is the last time the pod's Ready condition changed.
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+}
+
+// VolumeSnapshotReference is a VolumeSnapshot taken of an ordinal's PVC.
+type VolumeSnapshotReference struct {
+	Ordinal   int32  `json:"ordinal"`
+	ClaimName string `json:"claimName"`
+	Name      string `json:"name"`
+	// Revision is the pod revision the ordinal was about to be updated to.
+	// +optional
+	Revision   string `json:"revision,omitempty"`
+	ReadyToUse bool   `json:"readyToUse"`
+	// +optional
+	CreationTime metav1.Time `json:"creationTime,omitempty"`
+}
+
+// MyStatefulsetStatus defines the observed state of MyStatefulset.
+type MyStatefulsetStatus struct {
+	CurrentGeneration int64 `json:"currentGeneration,omitempty"`
+	Replicas          int32 `json:"replicas"`
+	ReadyReplicas     int32 `json:"readyReplicas"`
+	CurrentReplicas   int32 `json:"currentReplicas"`
+	UpdatedReplicas   int32 `json:"updatedReplicas"`
+	AvailableReplicas int32 `json:"availableReplicas,omitempty"`
+
+	// Selector is the label selector of the pods, serialized in string form.
+	// It is consumed by the scale subresource (e.g. HorizontalPodAutoscaler).
+	// +optional
+	Selector string `json:"selector,omitempty"`
+
+	// ZoneDistribution is the number of scheduled pods in each domain of
+	// spec.placement.topologyKey. It is only reported when placement is set.
+	// +optional
+	ZoneDistribution []ZoneReplicas `json:"zoneDistribution,omitempty"`
+
+	// PodFailures records, per ordinal, pods that were replaced because they
+	// ended up Failed, Succeeded, Unknown or stuck Pending. Count drives the
+	// replacement backoff and is reset once the replica runs stably again.
+	// +optional
+	PodFailures []PodFailure `json:"podFailures,omitempty"`
+
+	// PodStatuses is the per-ordinal detail of the pods, sorted by ordinal.
+	// It holds at most 50 entries; for larger sets pods that are not ready or
+	// not updated are listed first.
+	// +optional
+	PodStatuses []PodStatusDetail `json:"podStatuses,omitempty"`
+
+	// NotReadyOrdinals summarizes the ordinals below spec.replicas whose pod is
+	// missing or not ready, as compact ranges such as "3,7-9".
+	// +optional
+	NotReadyOrdinals string `json:"notReadyOrdinals,omitempty"`
+
+	// Snapshots lists the pre-update VolumeSnapshots kept for each ordinal.
+	// +optional
+	Snapshots []VolumeSnapshotReference `json:"snapshots,omitempty"`
+
+	// ScalingSchedule is the state of spec.scalingSchedule.
+	// +optional
+	ScalingSchedule *ScalingScheduleStatus `json:"scalingSchedule,omitempty"`
+
+	// Autoscaling is the state of spec.autoscaling.
+	// +optional
+	Autoscaling *AutoscalingStatus `json:"autoscaling,omitempty"`
+
+	// SuspendedReplicas is spec.replicas when the MyStatefulset was suspended.
+	// It is cleared on resume.
+	// +optional
+	SuspendedReplicas *int32 `json:"suspendedReplicas,omitempty"`
+
+	// ScaleDown reports the pods at or above spec.replicas that are still to
+	// be removed. It is unset when no scale-down is in progress.
+	// +optional
+	ScaleDown *ScaleDownStatus `json:"scaleDown,omitempty"`
+
+	// ObservedGeneration is the most recent generation observed for this MyStatefulset
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions represent the latest available observations of the MyStatefulset's state.
+	// +optional
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:storageversion
+// +kubebuilder:subresource:status
+// +kubebuilder:subresource:scale:specpath=.spec.replicas,statuspath=.status.replicas,selectorpath=.status.selector
+//+kubebuilder:resource:path=mystatefulsets,scope=Namespaced,shortName=kms
+//+kubebuilder:printcolumn:name="DESIRED",type="integer",JSONPath=".spec.replicas",description="Desired number of pods"
+//+kubebuilder:printcolumn:name="READY",type="integer",JSONPath=".status.readyReplicas",description="Number of pods ready"
+//+kubebuilder:printcolumn:name="CURRENT",type="integer",JSONPath=".status.currentReplicas",description="Current number of pods"
+//+kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+//+kubebuilder:printcolumn:name="UPDATED",type="integer",JSONPath=".status.updatedReplicas",description="Number of pods updated"
+//+kubebuilder:printcolumn:name="AVAILABLE",type="integer",JSONPath=".status.availableReplicas",description="Number of pods available"
+//+kubebuilder:printcolumn:name="NOT-READY",type="string",JSONPath=".status.notReadyOrdinals",description="Ordinals whose pod is missing or not ready"
+
+// MyStatefulset is the Schema for the mystatefulsets API.
+// v2 is the storage version and the conversion hub; v1 is converted to and
+// from it by the conversion webhook.
+type MyStatefulset struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              MyStatefulsetSpec   `json:"spec,omitempty"`
+	Status            MyStatefulsetStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// MyStatefulsetList contains a list of MyStatefulset
+type MyStatefulsetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MyStatefulset `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&MyStatefulset{}, &MyStatefulsetList{})
+}
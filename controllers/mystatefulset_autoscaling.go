@@ -0,0 +1,319 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	appsv1 "github.com/bryant-rh/my-statefulset/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+//+kubebuilder:rbac:groups=metrics.k8s.io,resources=pods,verbs=get;list
+
+const (
+	// autoscaleInterval 自动扩缩容的评估间隔，与 HPA 的默认同步周期一致
+	autoscaleInterval = 15 * time.Second
+
+	// autoscaleTolerance 利用率与目标的偏差在该比例内时不调整副本数
+	autoscaleTolerance = 0.1
+)
+
+// podMetricsListGVK 是 metrics-server 提供的 PodMetrics 列表，按 unstructured 读取以避免引入 k8s.io/metrics
+var podMetricsListGVK = schema.GroupVersionKind{Group: "metrics.k8s.io", Version: "v1beta1", Kind: "PodMetricsList"}
+
+// PodMetricsClient reads the resource usage of pods.
+type PodMetricsClient interface {
+	// PodUsage returns the usage summed over the containers of each pod in
+	// the namespace that matches the selector, keyed by pod name.
+	PodUsage(ctx context.Context, namespace string, selector labels.Selector) (map[string]corev1.ResourceList, error)
+}
+
+// NewPodMetricsClient returns a PodMetricsClient backed by the metrics.k8s.io
+// API. The metrics API does not support watch, so reader must read from the
+// API server directly, e.g. mgr.GetAPIReader().
+func NewPodMetricsClient(reader client.Reader) PodMetricsClient {
+	return &apiPodMetricsClient{reader: reader}
+}
+
+type apiPodMetricsClient struct {
+	reader client.Reader
+}
+
+func (c *apiPodMetricsClient) PodUsage(ctx context.Context, namespace string, selector labels.Selector) (map[string]corev1.ResourceList, error) {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(podMetricsListGVK)
+	if err := c.reader.List(ctx, list, client.InNamespace(namespace),
+		client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, err
+	}
+
+	usage := make(map[string]corev1.ResourceList, len(list.Items))
+	for _, item := range list.Items {
+		containers, _, _ := unstructured.NestedSlice(item.Object, "containers")
+		total := corev1.ResourceList{}
+		for _, container := range containers {
+			fields, ok := container.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			containerUsage, _, _ := unstructured.NestedStringMap(fields, "usage")
+			for name, value := range containerUsage {
+				quantity, err := resource.ParseQuantity(value)
+				if err != nil {
+					continue
+				}
+				sum := total[corev1.ResourceName(name)]
+				sum.Add(quantity)
+				total[corev1.ResourceName(name)] = sum
+			}
+		}
+		usage[item.GetName()] = total
+	}
+	return usage, nil
+}
+
+// autoscale 按 CPU 和内存利用率调整 spec.replicas，返回下一次评估的间隔。
+// 扩容在扩容窗口内的推荐值都高于当前副本数时进行，每次最多翻倍；缩容在缩容窗口内的推荐值
+// 都低于当前副本数时每次只减少一个序号，并等待上一个序号的 Pod 删除后再继续
+func (r *MyStatefulsetReconciler) autoscale(ctx context.Context, mystatefulset *appsv1.MyStatefulset) (time.Duration, error) {
+	log := log.FromContext(ctx)
+	autoscaling := mystatefulset.Spec.Autoscaling
+	if autoscaling == nil {
+		if mystatefulset.Status.Autoscaling == nil {
+			return 0, nil
+		}
+		patch := client.MergeFrom(mystatefulset.DeepCopy())
+		mystatefulset.Status.Autoscaling = nil
+		return 0, r.Status().Patch(ctx, mystatefulset, patch)
+	}
+	if r.Metrics == nil {
+		return 0, nil
+	}
+	// 距离上一次推荐不到 autoscaleInterval 时不评估，状态更新触发的调谐不会反复读取指标
+	if mystatefulset.Status.Autoscaling != nil {
+		if n := len(mystatefulset.Status.Autoscaling.Recommendations); n > 0 {
+			elapsed := time.Since(mystatefulset.Status.Autoscaling.Recommendations[n-1].Time.Time)
+			if elapsed < autoscaleInterval {
+				return autoscaleInterval - elapsed, nil
+			}
+		}
+	}
+
+	selector, err := podSelector(mystatefulset)
+	if err != nil {
+		return 0, err
+	}
+	podList := &corev1.PodList{}
+	if err := r.List(ctx, podList, client.InNamespace(mystatefulset.Namespace),
+		client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return 0, err
+	}
+	pods := ownedPods(podList.Items, mystatefulset)
+
+	usage, err := r.Metrics.PodUsage(ctx, mystatefulset.Namespace, selector)
+	if err != nil {
+		// metrics-server 不可用时保持当前副本数
+		r.Recorder.Event(mystatefulset, corev1.EventTypeWarning, "MetricsUnavailable",
+			fmt.Sprintf("Failed to read pod metrics: %v", err))
+		return autoscaleInterval, nil
+	}
+
+	now := time.Now()
+	current := mystatefulset.GetReplicas()
+	status := &appsv1.AutoscalingStatus{}
+	if mystatefulset.Status.Autoscaling != nil {
+		status = mystatefulset.Status.Autoscaling.DeepCopy()
+	}
+
+	// 每种资源分别计算推荐值，取最大值
+	desired := int32(-1)
+	cpuTarget, memoryTarget := autoscaling.GetTargets()
+	status.CurrentCPUUtilizationPercentage, status.CurrentMemoryUtilizationPercentage = nil, nil
+	for _, metric := range []struct {
+		name    corev1.ResourceName
+		target  int32
+		current **int32
+	}{
+		{corev1.ResourceCPU, cpuTarget, &status.CurrentCPUUtilizationPercentage},
+		{corev1.ResourceMemory, memoryTarget, &status.CurrentMemoryUtilizationPercentage},
+	} {
+		if metric.target == 0 {
+			continue
+		}
+		utilization, podCount, ok := resourceUtilization(pods, usage, metric.name)
+		if !ok {
+			continue
+		}
+		*metric.current = &utilization
+		if proposal := replicasForUtilization(current, podCount, utilization, metric.target); proposal > desired {
+			desired = proposal
+		}
+	}
+	// 没有可用的指标时只保证副本数在上下限之内
+	if desired < 0 {
+		desired = current
+	}
+	if minReplicas := autoscaling.GetMinReplicas(); desired < minReplicas {
+		desired = minReplicas
+	}
+	if desired > autoscaling.MaxReplicas {
+		desired = autoscaling.MaxReplicas
+	}
+	status.DesiredReplicas = desired
+	// 与最新的推荐值相同时不改变稳定窗口内的取值，只刷新它的时间，推荐值列表不随评估次数增长
+	if n := len(status.Recommendations); n > 0 && status.Recommendations[n-1].Replicas == desired {
+		status.Recommendations[n-1].Time = metav1.Time{Time: now}
+	} else {
+		status.Recommendations = append(status.Recommendations,
+			appsv1.ReplicaRecommendation{Replicas: desired, Time: metav1.Time{Time: now}})
+	}
+	status.Recommendations = pruneRecommendations(status.Recommendations,
+		now, maxDuration(autoscaling.GetScaleUpWindow(), autoscaling.GetScaleDownWindow()))
+
+	scaleTo := stabilizedReplicas(status.Recommendations, current, now,
+		autoscaling.GetScaleUpWindow(), autoscaling.GetScaleDownWindow())
+	if scaleTo < current {
+		// 上一个被缩掉的序号还在删除中
+		for _, pod := range pods {
+			if getOrdinal(pod.Name) >= int(current) {
+				scaleTo = current
+				break
+			}
+		}
+	}
+
+	// 每次扩容最多翻倍，与默认准入策略的副本数增幅限制一致，剩余的在下一次评估时继续扩容
+	if current > 0 && scaleTo > 2*current {
+		scaleTo = 2 * current
+	}
+
+	if scaleTo != current {
+		original := mystatefulset.Spec.Replicas
+		mystatefulset.Spec.Replicas = &scaleTo
+		err := r.Update(ctx, mystatefulset)
+		switch {
+		case errors.IsInvalid(err) || errors.IsForbidden(err):
+			// 更严格的准入策略拒绝时不中断调谐，记录在状态中，下一次评估时重试
+			mystatefulset.Spec.Replicas = original
+			log.Info("Autoscaling rejected", "from", current, "to", scaleTo, "reason", err.Error())
+			r.Recorder.Event(mystatefulset, corev1.EventTypeWarning, "AutoscalingRejected",
+				fmt.Sprintf("Could not scale from %d to %d replicas: %v", current, scaleTo, err))
+			status.LastRejection = &appsv1.AutoscalingRejection{
+				Replicas: scaleTo,
+				Message:  err.Error(),
+				Time:     metav1.Time{Time: now},
+			}
+		case err != nil:
+			return 0, fmt.Errorf("failed to autoscale: %w", err)
+		default:
+			log.Info("Autoscaled", "from", current, "to", scaleTo, "desired", desired)
+			r.Recorder.Event(mystatefulset, corev1.EventTypeNormal, "Autoscaled",
+				fmt.Sprintf("Scaled from %d to %d replicas (desired %d)", current, scaleTo, desired))
+			status.LastScaleTime = &metav1.Time{Time: now}
+			status.LastRejection = nil
+		}
+	}
+
+	if !equality.Semantic.DeepEqual(mystatefulset.Status.Autoscaling, status) {
+		patch := client.MergeFrom(mystatefulset.DeepCopy())
+		mystatefulset.Status.Autoscaling = status
+		if err := r.Status().Patch(ctx, mystatefulset, patch); err != nil {
+			return 0, fmt.Errorf("failed to record autoscaling status: %w", err)
+		}
+	}
+	return autoscaleInterval, nil
+}
+
+// resourceUtilization 返回 Ready Pod 的平均利用率（用量之和除以 requests 之和）以及参与计算的 Pod 数，
+// 没有指标或没有设置 requests 的 Pod 不参与计算
+func resourceUtilization(pods []corev1.Pod, usage map[string]corev1.ResourceList, name corev1.ResourceName) (int32, int32, bool) {
+	var totalUsage, totalRequests int64
+	var count int32
+	for i := range pods {
+		pod := &pods[i]
+		if !isPodReady(pod) {
+			continue
+		}
+		podUsage, ok := usage[pod.Name][name]
+		if !ok {
+			continue
+		}
+		var requests int64
+		for _, container := range pod.Spec.Containers {
+			if request, ok := container.Resources.Requests[name]; ok {
+				requests += request.MilliValue()
+			}
+		}
+		if requests == 0 {
+			continue
+		}
+		totalUsage += podUsage.MilliValue()
+		totalRequests += requests
+		count++
+	}
+	if count == 0 {
+		return 0, 0, false
+	}
+	return int32(totalUsage * 100 / totalRequests), count, true
+}
+
+// replicasForUtilization 按利用率与目标的比例计算副本数，偏差在容忍范围内时保持当前副本数
+func replicasForUtilization(current, podCount, utilization, target int32) int32 {
+	ratio := float64(utilization) / float64(target)
+	if math.Abs(ratio-1) <= autoscaleTolerance {
+		return current
+	}
+	return int32(math.Ceil(ratio * float64(podCount)))
+}
+
+// stabilizedReplicas 根据稳定窗口内的推荐值决定目标副本数：
+// 扩容取扩容窗口内的最小推荐值，缩容取缩容窗口内的最大推荐值且每次只减少一个
+func stabilizedReplicas(recommendations []appsv1.ReplicaRecommendation, current int32, now time.Time, upWindow, downWindow time.Duration) int32 {
+	up, down := int32(math.MaxInt32), int32(-1)
+	for _, rec := range recommendations {
+		age := now.Sub(rec.Time.Time)
+		if age <= upWindow && rec.Replicas < up {
+			up = rec.Replicas
+		}
+		if age <= downWindow && rec.Replicas > down {
+			down = rec.Replicas
+		}
+	}
+	switch {
+	case up != math.MaxInt32 && up > current:
+		return up
+	case down >= 0 && down < current:
+		return current - 1
+	default:
+		return current
+	}
+}
+
+// pruneRecommendations 删除超出最长稳定窗口的推荐值，最新的一条总是保留
+func pruneRecommendations(recommendations []appsv1.ReplicaRecommendation, now time.Time, window time.Duration) []appsv1.ReplicaRecommendation {
+	kept := recommendations[:0]
+	for i, rec := range recommendations {
+		if i == len(recommendations)-1 || now.Sub(rec.Time.Time) <= window {
+			kept = append(kept, rec)
+		}
+	}
+	return kept
+}
+
+func maxDuration(a, b time.Duration) time.Duration {
+	if a > b {
+		return a
+	}
+	return b
+}
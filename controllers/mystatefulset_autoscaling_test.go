@@ -0,0 +1,324 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	appsv1 "github.com/bryant-rh/my-statefulset/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// fakePodMetricsClient 返回固定的 Pod 用量
+type fakePodMetricsClient struct {
+	usage map[string]corev1.ResourceList
+	err   error
+}
+
+func (f *fakePodMetricsClient) PodUsage(_ context.Context, _ string, _ labels.Selector) (map[string]corev1.ResourceList, error) {
+	return f.usage, f.err
+}
+
+// cpuUsage 为每个 Pod 设置相同的 CPU 用量
+func cpuUsage(quantity string, podNames ...string) map[string]corev1.ResourceList {
+	usage := make(map[string]corev1.ResourceList, len(podNames))
+	for _, name := range podNames {
+		usage[name] = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(quantity)}
+	}
+	return usage
+}
+
+func createAutoscaledPod(name string) *corev1.Pod {
+	pod := createPodWithOwner(name, "test-uid")
+	pod.Spec.Containers[0].Resources.Requests = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")}
+	return pod
+}
+
+// expireRecommendations 把推荐值的时间提前 autoscaleInterval，使下一次调用立即评估
+func expireRecommendations(t *testing.T, c client.Client, ms *appsv1.MyStatefulset) {
+	for i := range ms.Status.Autoscaling.Recommendations {
+		rec := &ms.Status.Autoscaling.Recommendations[i]
+		rec.Time = metav1.Time{Time: rec.Time.Add(-autoscaleInterval)}
+	}
+	require.NoError(t, c.Status().Update(context.Background(), ms))
+}
+
+// statusWriteCounter 统计状态子资源的写入次数
+type statusWriteCounter struct {
+	client.Client
+	writes int
+}
+
+func (c *statusWriteCounter) Status() client.StatusWriter {
+	return &countingStatusWriter{StatusWriter: c.Client.Status(), writes: &c.writes}
+}
+
+type countingStatusWriter struct {
+	client.StatusWriter
+	writes *int
+}
+
+func (w *countingStatusWriter) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	*w.writes++
+	return w.StatusWriter.Update(ctx, obj, opts...)
+}
+
+func (w *countingStatusWriter) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	*w.writes++
+	return w.StatusWriter.Patch(ctx, obj, patch, opts...)
+}
+
+func TestMyStatefulsetReconciler_autoscale(t *testing.T) {
+	s := runtime.NewScheme()
+	_ = appsv1.AddToScheme(s)
+	_ = corev1.AddToScheme(s)
+
+	newSet := func(replicas int32, downWindow int32) *appsv1.MyStatefulset {
+		return &appsv1.MyStatefulset{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-statefulset", Namespace: "default", UID: "test-uid"},
+			Spec: appsv1.MyStatefulsetSpec{
+				Replicas: pointer.Int32(replicas),
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "test"}},
+				Autoscaling: &appsv1.AutoscalingSpec{
+					MinReplicas:                         pointer.Int32(1),
+					MaxReplicas:                         6,
+					TargetCPUUtilizationPercentage:      pointer.Int32(50),
+					ScaleDownStabilizationWindowSeconds: pointer.Int32(downWindow),
+				},
+			},
+		}
+	}
+	key := types.NamespacedName{Name: "test-statefulset", Namespace: "default"}
+
+	t.Run("scale up doubles per step up to maxReplicas", func(t *testing.T) {
+		c := fake.NewClientBuilder().WithScheme(s).WithObjects(
+			newSet(2, 300), createAutoscaledPod("test-statefulset-0"), createAutoscaledPod("test-statefulset-1"),
+		).Build()
+		recorder := record.NewFakeRecorder(10)
+		metrics := &fakePodMetricsClient{usage: cpuUsage("200m", "test-statefulset-0", "test-statefulset-1")}
+		r := &MyStatefulsetReconciler{Client: c, Scheme: s, Recorder: recorder, Metrics: metrics}
+
+		ms := &appsv1.MyStatefulset{}
+		require.NoError(t, c.Get(context.Background(), key, ms))
+		after, err := r.autoscale(context.Background(), ms)
+		require.NoError(t, err)
+		assert.Equal(t, autoscaleInterval, after)
+		assert.Contains(t, <-recorder.Events, "Autoscaled ")
+
+		got := &appsv1.MyStatefulset{}
+		require.NoError(t, c.Get(context.Background(), key, got))
+		// 200% / 50% * 2 = 8，受 maxReplicas 限制为 6，每次最多翻倍
+		assert.Equal(t, int32(4), got.GetReplicas())
+		require.NotNil(t, got.Status.Autoscaling)
+		assert.Equal(t, int32(200), *got.Status.Autoscaling.CurrentCPUUtilizationPercentage)
+		assert.Equal(t, int32(6), got.Status.Autoscaling.DesiredReplicas)
+		assert.NotNil(t, got.Status.Autoscaling.LastScaleTime)
+
+		// 距离上一次推荐不到评估间隔时不评估
+		after, err = r.autoscale(context.Background(), got)
+		require.NoError(t, err)
+		assert.True(t, after > 0 && after <= autoscaleInterval, "after = %v", after)
+		require.NoError(t, c.Get(context.Background(), key, got))
+		assert.Equal(t, int32(4), got.GetReplicas())
+
+		expireRecommendations(t, c, got)
+		_, err = r.autoscale(context.Background(), got)
+		require.NoError(t, err)
+		require.NoError(t, c.Get(context.Background(), key, got))
+		assert.Equal(t, int32(6), got.GetReplicas())
+	})
+
+	t.Run("rejected scale up is recorded in status", func(t *testing.T) {
+		c := fake.NewClientBuilder().WithScheme(s).WithObjects(
+			newSet(2, 300), createAutoscaledPod("test-statefulset-0"), createAutoscaledPod("test-statefulset-1"),
+		).Build()
+		recorder := record.NewFakeRecorder(10)
+		metrics := &fakePodMetricsClient{usage: cpuUsage("200m", "test-statefulset-0", "test-statefulset-1")}
+		r := &MyStatefulsetReconciler{Client: rejectingClient{c}, Scheme: s, Recorder: recorder, Metrics: metrics}
+
+		ms := &appsv1.MyStatefulset{}
+		require.NoError(t, c.Get(context.Background(), key, ms))
+		after, err := r.autoscale(context.Background(), ms)
+		require.NoError(t, err)
+		assert.Equal(t, autoscaleInterval, after)
+		assert.Equal(t, int32(2), ms.GetReplicas())
+		assert.Contains(t, <-recorder.Events, "AutoscalingRejected ")
+
+		got := &appsv1.MyStatefulset{}
+		require.NoError(t, c.Get(context.Background(), key, got))
+		assert.Equal(t, int32(2), got.GetReplicas())
+		require.NotNil(t, got.Status.Autoscaling.LastRejection)
+		assert.Equal(t, int32(4), got.Status.Autoscaling.LastRejection.Replicas)
+		assert.Contains(t, got.Status.Autoscaling.LastRejection.Message, "cannot increase replicas")
+		assert.Nil(t, got.Status.Autoscaling.LastScaleTime)
+	})
+
+	t.Run("scale down one ordinal at a time", func(t *testing.T) {
+		c := fake.NewClientBuilder().WithScheme(s).WithObjects(
+			newSet(3, 0),
+			createAutoscaledPod("test-statefulset-0"), createAutoscaledPod("test-statefulset-1"), createAutoscaledPod("test-statefulset-2"),
+		).Build()
+		metrics := &fakePodMetricsClient{usage: cpuUsage("5m", "test-statefulset-0", "test-statefulset-1", "test-statefulset-2")}
+		r := &MyStatefulsetReconciler{Client: c, Scheme: s, Recorder: record.NewFakeRecorder(10), Metrics: metrics}
+		ctx := context.Background()
+
+		ms := &appsv1.MyStatefulset{}
+		require.NoError(t, c.Get(ctx, key, ms))
+		_, err := r.autoscale(ctx, ms)
+		require.NoError(t, err)
+		require.NoError(t, c.Get(ctx, key, ms))
+		assert.Equal(t, int32(2), ms.GetReplicas())
+		assert.Equal(t, int32(1), ms.Status.Autoscaling.DesiredReplicas)
+
+		// 序号 2 的 Pod 还没有删除，不继续缩容
+		expireRecommendations(t, c, ms)
+		_, err = r.autoscale(ctx, ms)
+		require.NoError(t, err)
+		require.NoError(t, c.Get(ctx, key, ms))
+		assert.Equal(t, int32(2), ms.GetReplicas())
+
+		require.NoError(t, c.Delete(ctx, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test-statefulset-2", Namespace: "default"}}))
+		expireRecommendations(t, c, ms)
+		_, err = r.autoscale(ctx, ms)
+		require.NoError(t, err)
+		require.NoError(t, c.Get(ctx, key, ms))
+		assert.Equal(t, int32(1), ms.GetReplicas())
+		// 推荐值相同时只刷新时间
+		assert.Len(t, ms.Status.Autoscaling.Recommendations, 1)
+	})
+
+	t.Run("scale down waits for the stabilization window", func(t *testing.T) {
+		ms := newSet(2, 300)
+		ms.Status.Autoscaling = &appsv1.AutoscalingStatus{
+			DesiredReplicas: 2,
+			Recommendations: []appsv1.ReplicaRecommendation{
+				{Replicas: 2, Time: metav1.Time{Time: time.Now().Add(-time.Minute)}},
+			},
+		}
+		c := fake.NewClientBuilder().WithScheme(s).WithObjects(
+			ms, createAutoscaledPod("test-statefulset-0"), createAutoscaledPod("test-statefulset-1"),
+		).Build()
+		metrics := &fakePodMetricsClient{usage: cpuUsage("5m", "test-statefulset-0", "test-statefulset-1")}
+		r := &MyStatefulsetReconciler{Client: c, Scheme: s, Recorder: record.NewFakeRecorder(10), Metrics: metrics}
+
+		got := &appsv1.MyStatefulset{}
+		require.NoError(t, c.Get(context.Background(), key, got))
+		_, err := r.autoscale(context.Background(), got)
+		require.NoError(t, err)
+		require.NoError(t, c.Get(context.Background(), key, got))
+		assert.Equal(t, int32(2), got.GetReplicas())
+		assert.Equal(t, int32(1), got.Status.Autoscaling.DesiredReplicas)
+		assert.Len(t, got.Status.Autoscaling.Recommendations, 2)
+	})
+
+	t.Run("metrics unavailable keeps replicas", func(t *testing.T) {
+		c := fake.NewClientBuilder().WithScheme(s).WithObjects(newSet(2, 0)).Build()
+		recorder := record.NewFakeRecorder(10)
+		r := &MyStatefulsetReconciler{Client: c, Scheme: s, Recorder: recorder,
+			Metrics: &fakePodMetricsClient{err: errors.New("the server could not find the requested resource")}}
+
+		got := &appsv1.MyStatefulset{}
+		require.NoError(t, c.Get(context.Background(), key, got))
+		after, err := r.autoscale(context.Background(), got)
+		require.NoError(t, err)
+		assert.Equal(t, autoscaleInterval, after)
+		assert.Contains(t, <-recorder.Events, "MetricsUnavailable ")
+		require.NoError(t, c.Get(context.Background(), key, got))
+		assert.Equal(t, int32(2), got.GetReplicas())
+	})
+}
+
+func TestMyStatefulsetReconciler_autoscaleDoesNotRewriteStatus(t *testing.T) {
+	s := runtime.NewScheme()
+	_ = appsv1.AddToScheme(s)
+	_ = corev1.AddToScheme(s)
+	ctx := context.Background()
+	key := types.NamespacedName{Name: "test-statefulset", Namespace: "default"}
+
+	ms := newDependentMyStatefulset()
+	ms.Spec.Autoscaling = &appsv1.AutoscalingSpec{MaxReplicas: 4, TargetCPUUtilizationPercentage: pointer.Int32(50)}
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-service", Namespace: "default"},
+		Spec:       corev1.ServiceSpec{ClusterIP: "None"},
+	}
+	c := &statusWriteCounter{Client: fake.NewClientBuilder().WithScheme(s).WithObjects(
+		ms, service, createAutoscaledPod("test-statefulset-0"), createAutoscaledPod("test-statefulset-1"),
+	).Build()}
+	metrics := &fakePodMetricsClient{usage: cpuUsage("50m", "test-statefulset-0", "test-statefulset-1")}
+	r := &MyStatefulsetReconciler{Client: c, Scheme: s, Recorder: record.NewFakeRecorder(10), Metrics: metrics,
+		PodInformer: &fakePodInformer{}, PVCInformer: &fakePVCInformer{}}
+
+	_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+	require.NotZero(t, c.writes)
+
+	// 第一次调谐的状态更新触发的调谐不再评估，也不写状态
+	c.writes = 0
+	result, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+	assert.Zero(t, c.writes)
+	assert.True(t, result.RequeueAfter > 0 && result.RequeueAfter <= autoscaleInterval, "RequeueAfter = %v", result.RequeueAfter)
+
+	got := &appsv1.MyStatefulset{}
+	require.NoError(t, c.Get(ctx, key, got))
+	assert.Len(t, got.Status.Autoscaling.Recommendations, 1)
+}
+
+func TestReplicasForUtilization(t *testing.T) {
+	tests := []struct {
+		name                                   string
+		current, podCount, utilization, target int32
+		want                                   int32
+	}{
+		{name: "within tolerance", current: 3, podCount: 3, utilization: 85, target: 80, want: 3},
+		{name: "scale up", current: 3, podCount: 3, utilization: 160, target: 80, want: 6},
+		{name: "scale down", current: 4, podCount: 4, utilization: 20, target: 80, want: 1},
+		{name: "only ready pods count", current: 4, podCount: 2, utilization: 120, target: 80, want: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, replicasForUtilization(tt.current, tt.podCount, tt.utilization, tt.target))
+		})
+	}
+}
+
+func TestAPIPodMetricsClient_PodUsage(t *testing.T) {
+	s := runtime.NewScheme()
+	s.AddKnownTypeWithName(podMetricsListGVK.GroupVersion().WithKind("PodMetrics"), &unstructured.Unstructured{})
+	s.AddKnownTypeWithName(podMetricsListGVK, &unstructured.UnstructuredList{})
+
+	podMetrics := &unstructured.Unstructured{Object: map[string]interface{}{
+		"containers": []interface{}{
+			map[string]interface{}{"name": "app", "usage": map[string]interface{}{"cpu": "150m", "memory": "64Mi"}},
+			map[string]interface{}{"name": "sidecar", "usage": map[string]interface{}{"cpu": "50m", "memory": "16Mi"}},
+		},
+	}}
+	podMetrics.SetGroupVersionKind(podMetricsListGVK.GroupVersion().WithKind("PodMetrics"))
+	podMetrics.SetName("test-statefulset-0")
+	podMetrics.SetNamespace("default")
+	podMetrics.SetLabels(map[string]string{"app": "test"})
+
+	c := fake.NewClientBuilder().WithScheme(s).WithObjects(podMetrics).Build()
+	usage, err := NewPodMetricsClient(c).PodUsage(context.Background(), "default",
+		labels.SelectorFromSet(labels.Set{"app": "test"}))
+	require.NoError(t, err)
+
+	cpu := usage["test-statefulset-0"][corev1.ResourceCPU]
+	memory := usage["test-statefulset-0"][corev1.ResourceMemory]
+	assert.Equal(t, int64(200), cpu.MilliValue())
+	assert.Equal(t, int64(80*1024*1024), memory.Value())
+}
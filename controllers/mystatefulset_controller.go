@@ -2,25 +2,36 @@ package controllers
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"hash/fnv"
 	"reflect"
 	"sort"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	appsv1 "github.com/bryant-rh/my-statefulset/api/v1"
+	k8sappsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 const (
@@ -32,10 +43,12 @@ const (
 //+kubebuilder:rbac:groups=apps.mystatefulset.com,resources=mystatefulsets/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=apps.mystatefulset.com,resources=mystatefulsets/finalizers,verbs=update
 //+kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;create;update;patch;delete
-//+kubebuilder:rbac:groups=core,resources=pods/status,verbs=get
+//+kubebuilder:rbac:groups=core,resources=pods/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+//+kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch
+//+kubebuilder:rbac:groups=snapshot.storage.k8s.io,resources=volumesnapshots,verbs=get;list;watch;create;delete
 
 //+kubebuilder:printcolumn:name="Desired",type="integer",JSONPath=".spec.replicas",description="Desired number of pods"
 //+kubebuilder:printcolumn:name="Current",type="integer",JSONPath=".status.replicas",description="Current number of pods"
@@ -44,7 +57,7 @@ const (
 //+kubebuilder:printcolumn:name="Available",type="integer",JSONPath=".status.availableReplicas",description="Number of pods available"
 //+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 //+kubebuilder:subresource:status
-//+kubebuilder:subresource:scale:specpath=.spec.replicas,statuspath=.status.replicas,selectorpath=.spec.selector
+//+kubebuilder:subresource:scale:specpath=.spec.replicas,statuspath=.status.replicas,selectorpath=.status.selector
 
 // MyStatefulsetReconciler reconciles a MyStatefulset object
 type MyStatefulsetReconciler struct {
@@ -53,6 +66,12 @@ type MyStatefulsetReconciler struct {
 	Recorder    record.EventRecorder
 	PodInformer cache.SharedIndexInformer
 	PVCInformer cache.SharedIndexInformer
+	// Metrics 为 spec.autoscaling 提供 Pod 用量，为 nil 时不自动扩缩容
+	Metrics PodMetricsClient
+
+	// controller 和 dependencyWatches 用于按需 watch spec.dependsOn 中的任意类型
+	controller        controller.Controller
+	dependencyWatches sync.Map
 }
 
 // Reconcile is part of the main kubernetes reconciliation loop
@@ -97,24 +116,16 @@ func (r *MyStatefulsetReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 	log.Info("Reconciling MyStatefulset",
 		"name", mystatefulset.Name,
 		"namespace", mystatefulset.Namespace,
-		"replicas", mystatefulset.Spec.Replicas,
+		"replicas", mystatefulset.GetReplicas(),
 		"selector", mystatefulset.Spec.Selector.MatchLabels,
 		"template_labels", mystatefulset.Spec.Template.Labels)
 
-	// Add validation for template labels
-	if mystatefulset.Spec.Template.ObjectMeta.Labels == nil {
-		err := fmt.Errorf("pod template labels are required")
-		r.Recorder.Event(&mystatefulset, corev1.EventTypeWarning, "ValidationFailed", err.Error())
-		return ctrl.Result{}, err
-	}
-
-	// Validate that template labels match selector
-	for key, value := range mystatefulset.Spec.Selector.MatchLabels {
-		if v, ok := mystatefulset.Spec.Template.Labels[key]; !ok || v != value {
-			err := fmt.Errorf("pod template labels must match selector")
-			r.Recorder.Event(&mystatefulset, corev1.EventTypeWarning, "ValidationFailed", err.Error())
-			return ctrl.Result{}, err
-		}
+	// selector 与模板标签是否匹配已在 webhook 中校验，这里只确认 selector 可以被解析。
+	// 重试无法修复非法的 spec，因此只记录事件，等待对象被修改后再次调谐
+	if _, err := podSelector(&mystatefulset); err != nil {
+		log.Error(err, "Invalid selector")
+		r.Recorder.Event(&mystatefulset, corev1.EventTypeWarning, "InvalidSpec", err.Error())
+		return ctrl.Result{}, nil
 	}
 
 	// 添加 Finalizer
@@ -131,6 +142,14 @@ func (r *MyStatefulsetReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		return ctrl.Result{}, err
 	}
 
+	// 暂停时按序号逆序删除 Pod 并保留 PVC，不需要 Service，也不执行扩缩容
+	if mystatefulset.Spec.Suspend {
+		return r.suspend(ctx, &mystatefulset)
+	}
+	if err := r.resume(ctx, &mystatefulset); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	// 验证 Service 存在（而不是创建）
 	if mystatefulset.Spec.ServiceName != "" {
 		service := &corev1.Service{}
@@ -149,6 +168,79 @@ func (r *MyStatefulsetReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		}
 	}
 
+	// 按定时计划设置副本数
+	scheduleAfter, err := r.applyScalingSchedule(ctx, &mystatefulset)
+	if err != nil {
+		log.Error(err, "Failed to apply scaling schedule")
+		return ctrl.Result{}, err
+	}
+
+	// 按指标自动扩缩容
+	autoscaleAfter, err := r.autoscale(ctx, &mystatefulset)
+	if err != nil {
+		log.Error(err, "Failed to autoscale")
+		return ctrl.Result{}, err
+	}
+
+	// 恢复所在节点失联的 Pod
+	recoverAfter, err := r.recoverFromNodeFailure(ctx, &mystatefulset)
+	if err != nil {
+		log.Error(err, "Failed to recover pods from lost nodes")
+		return ctrl.Result{}, err
+	}
+
+	// 替换失败或 Pending 超时的 Pod
+	replaceAfter, err := r.replaceFailedPods(ctx, &mystatefulset)
+	if err != nil {
+		log.Error(err, "Failed to replace failed pods")
+		return ctrl.Result{}, err
+	}
+
+	// 按序号从大到小逐个删除多余的 Pod，缩容不需要数据源和依赖，在等待它们之前进行
+	scaleDownAfter, err := r.scaleDown(ctx, &mystatefulset)
+	if err != nil {
+		log.Error(err, "Failed to scale down")
+		return ctrl.Result{}, err
+	}
+	afters := []time.Duration{recoverAfter, replaceAfter, scheduleAfter, autoscaleAfter, scaleDownAfter}
+
+	// 控制器侧检查通过后设置 ready-to-serve 条件。等待数据源和依赖时已有的 Pod 也要更新该条件，
+	// 例如缩容中的 Pod 需要立即摘除流量；本次新建的 Pod 在下一次调谐时设置
+	if err := r.admitPods(ctx, &mystatefulset); err != nil {
+		log.Error(err, "Failed to set ready-to-serve conditions")
+		return ctrl.Result{}, err
+	}
+
+	// 数据源缺失时不创建 PVC 和 Pod，避免部分序号以空卷启动
+	missing, err := r.missingDataSources(ctx, &mystatefulset)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if len(missing) > 0 {
+		r.Recorder.Event(&mystatefulset, corev1.EventTypeWarning, "DataSourceNotFound",
+			fmt.Sprintf("Waiting for PVC data sources: %s", strings.Join(missing, ", ")))
+		if err := r.updateStatus(ctx, &mystatefulset); err != nil {
+			return ctrl.Result{}, err
+		}
+		return requeueAfter(afters), nil
+	}
+
+	// 依赖不可用时不创建 PVC 和 Pod，依赖变化时通过 watch 立即重新调谐
+	unmet, err := r.unmetDependencies(ctx, &mystatefulset)
+	if err != nil {
+		log.Error(err, "Failed to check dependencies")
+		return ctrl.Result{}, err
+	}
+	setWaitingForDependencies(&mystatefulset, unmet)
+	if len(unmet) > 0 {
+		r.Recorder.Event(&mystatefulset, corev1.EventTypeNormal, "WaitingForDependencies",
+			fmt.Sprintf("Waiting for dependencies: %s", strings.Join(unmet, ", ")))
+		if err := r.updateStatus(ctx, &mystatefulset); err != nil {
+			return ctrl.Result{}, err
+		}
+		return requeueAfter(afters), nil
+	}
+
 	// 确保 PVC 存在
 	if err := r.reconcilePVCs(ctx, &mystatefulset); err != nil {
 		return ctrl.Result{}, err
@@ -167,19 +259,27 @@ func (r *MyStatefulsetReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		return ctrl.Result{}, err
 	}
 
-	return ctrl.Result{RequeueAfter: time.Second * 30}, nil
+	return requeueAfter(afters), nil
+}
+
+// requeueAfter 返回最早需要重新调谐的时间，最长 30 秒
+func requeueAfter(afters []time.Duration) ctrl.Result {
+	result := ctrl.Result{RequeueAfter: time.Second * 30}
+	for _, after := range afters {
+		if after > 0 && after < result.RequeueAfter {
+			result.RequeueAfter = after
+		}
+	}
+	return result
 }
 
 // reconcilePVCs 确保 PVC 存在
 func (r *MyStatefulsetReconciler) reconcilePVCs(ctx context.Context, mystatefulset *appsv1.MyStatefulset) error {
-	for _, pvcTemplate := range mystatefulset.Spec.VolumeClaimTemplates {
-		volumeName := "www" // 使用固定的名称
-		if pvcTemplate.Name != "" {
-			volumeName = pvcTemplate.Name
-		}
+	for i, pvcTemplate := range mystatefulset.Spec.VolumeClaimTemplates {
+		volumeName := appsv1.ClaimVolumeName(pvcTemplate)
 
-		for ordinal := 0; ordinal < int(mystatefulset.Spec.Replicas); ordinal++ {
-			pvcName := fmt.Sprintf("%s-%s-%d", volumeName, mystatefulset.Name, ordinal)
+		for ordinal := 0; ordinal < int(mystatefulset.GetReplicas()); ordinal++ {
+			pvcName := claimNamesForOrdinal(mystatefulset, ordinal)[i]
 
 			pvc := &corev1.PersistentVolumeClaim{}
 			err := r.Get(ctx, types.NamespacedName{
@@ -188,7 +288,21 @@ func (r *MyStatefulsetReconciler) reconcilePVCs(ctx context.Context, mystatefuls
 			}, pvc)
 
 			if errors.IsNotFound(err) {
-				// 创建新的 PVC
+				// 创建新的 PVC，配置了数据源时从快照或源副本集的 PVC 恢复
+				spec := *pvcTemplate.Spec.DeepCopy()
+				if dataSource := mystatefulset.DataSourceForClaim(volumeName, ordinal); dataSource != nil {
+					// 初次恢复之后新增的序号（例如扩容超过源副本集的副本数）可能没有对应的数据源，此时创建空卷
+					exists, err := r.dataSourceExists(ctx, mystatefulset.Namespace, dataSource)
+					if err != nil {
+						return err
+					}
+					if exists {
+						spec.DataSource = dataSource
+					} else {
+						r.Recorder.Event(mystatefulset, corev1.EventTypeWarning, "DataSourceNotFound",
+							fmt.Sprintf("Creating PVC %s without data source %s/%s", pvcName, dataSource.Kind, dataSource.Name))
+					}
+				}
 				newPVC := &corev1.PersistentVolumeClaim{
 					ObjectMeta: metav1.ObjectMeta{
 						Name:      pvcName,
@@ -198,7 +312,7 @@ func (r *MyStatefulsetReconciler) reconcilePVCs(ctx context.Context, mystatefuls
 							*metav1.NewControllerRef(mystatefulset, appsv1.GroupVersion.WithKind("MyStatefulset")),
 						},
 					},
-					Spec: pvcTemplate.Spec,
+					Spec: spec,
 				}
 
 				if err := r.Create(ctx, newPVC); err != nil {
@@ -206,70 +320,108 @@ func (r *MyStatefulsetReconciler) reconcilePVCs(ctx context.Context, mystatefuls
 				}
 			} else if err != nil {
 				return err
+			} else if err := r.expandPVC(ctx, mystatefulset, pvc, &pvcTemplate); err != nil {
+				return err
 			}
 		}
 	}
 	return nil
 }
 
+// expandPVC 当模板中的存储容量变大时，同步扩容已存在的 PVC。
+// 是否能扩容取决于 StorageClass 的 allowVolumeExpansion，失败时记录事件。
+func (r *MyStatefulsetReconciler) expandPVC(ctx context.Context, mystatefulset *appsv1.MyStatefulset,
+	pvc *corev1.PersistentVolumeClaim, pvcTemplate *corev1.PersistentVolumeClaim) error {
+	desired, ok := pvcTemplate.Spec.Resources.Requests[corev1.ResourceStorage]
+	if !ok {
+		return nil
+	}
+	current := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+	if desired.Cmp(current) <= 0 {
+		return nil
+	}
+
+	log.FromContext(ctx).Info("Expanding PVC", "pvc", pvc.Name, "from", current.String(), "to", desired.String())
+
+	patch := client.MergeFrom(pvc.DeepCopy())
+	if pvc.Spec.Resources.Requests == nil {
+		pvc.Spec.Resources.Requests = corev1.ResourceList{}
+	}
+	pvc.Spec.Resources.Requests[corev1.ResourceStorage] = desired
+	if err := r.Patch(ctx, pvc, patch); err != nil {
+		r.Recorder.Event(mystatefulset, corev1.EventTypeWarning, "PVCExpansionFailed",
+			fmt.Sprintf("Failed to expand PVC %s to %s: %v", pvc.Name, desired.String(), err))
+		return fmt.Errorf("failed to expand PVC %s: %w", pvc.Name, err)
+	}
+	return nil
+}
+
 // reconcilePods 处理 Pod 的创建、更新和删除
 func (r *MyStatefulsetReconciler) reconcilePods(ctx context.Context, mystatefulset *appsv1.MyStatefulset) error {
 	log := log.FromContext(ctx)
 
 	// Add validation and logging for pod creation prerequisites
-	if mystatefulset.Spec.Replicas == 0 {
+	// replicas 为 0 时不能直接返回，后面还需要删除多余的 Pod
+	replicas := mystatefulset.GetReplicas()
+	if replicas == 0 {
 		log.Info("Replicas is set to 0, no pods will be created")
-		return nil
-	}
-
-	if len(mystatefulset.Spec.Template.Labels) == 0 {
-		log.Error(nil, "Pod template labels are empty")
-		return fmt.Errorf("pod template labels cannot be empty")
 	}
 
-	// Log selector and template labels match
-	if !reflect.DeepEqual(mystatefulset.Spec.Selector.MatchLabels, mystatefulset.Spec.Template.Labels) {
-		log.Error(nil, "Pod template labels don't match selector",
-			"selector", mystatefulset.Spec.Selector.MatchLabels,
-			"template_labels", mystatefulset.Spec.Template.Labels)
-		return fmt.Errorf("pod template labels must match selector")
+	selector, err := podSelector(mystatefulset)
+	if err != nil {
+		return err
 	}
 
 	// Get existing pods with detailed logging
 	existingPods := &corev1.PodList{}
 	if err := r.List(ctx, existingPods,
 		client.InNamespace(mystatefulset.Namespace),
-		client.MatchingLabels(mystatefulset.Spec.Selector.MatchLabels)); err != nil {
+		client.MatchingLabelsSelector{Selector: selector}); err != nil {
 		log.Error(err, "Failed to list pods",
 			"namespace", mystatefulset.Namespace,
-			"selector", mystatefulset.Spec.Selector.MatchLabels)
+			"selector", selector.String())
 		return err
 	}
+	existingPods.Items = ownedPods(existingPods.Items, mystatefulset)
 
 	log.Info("Current pod status",
-		"desired_replicas", mystatefulset.Spec.Replicas,
+		"desired_replicas", replicas,
 		"existing_pods", len(existingPods.Items),
-		"selector", mystatefulset.Spec.Selector.MatchLabels)
+		"selector", selector.String())
 
-	// 根据更新策略选择处理方式
-	if mystatefulset.Spec.UpdateStrategy.Type == appsv1.RollingUpdateStatefulSetStrategyType {
+	// 根据更新策略选择处理方式，暂停时保留 Pod 当前的版本
+	if mystatefulset.Spec.UpdateStrategy.Type == appsv1.RollingUpdateStatefulSetStrategyType &&
+		!mystatefulset.Spec.UpdateStrategy.Paused {
 		// 处理滚动更新
-		partition := int32(0)
-		if mystatefulset.Spec.UpdateStrategy.RollingUpdate != nil &&
-			mystatefulset.Spec.UpdateStrategy.RollingUpdate.Partition != nil {
-			partition = *mystatefulset.Spec.UpdateStrategy.RollingUpdate.Partition
-		}
+		partition := rollingUpdatePartition(mystatefulset)
 
 		// 按序号排序 pods（降序，从高到低）
 		sort.Slice(existingPods.Items, func(i, j int) bool {
 			return getOrdinal(existingPods.Items[i].Name) > getOrdinal(existingPods.Items[j].Name)
 		})
 
+		// 不可用的 Pod 数达到 maxUnavailable 时等待，缺失的 Pod 也计为不可用
+		budget := mystatefulset.GetMaxUnavailable() - unavailableReplicas(mystatefulset, existingPods.Items)
+		updated := 0
+
 		// 处理现有 Pod 的更新
 		for _, pod := range existingPods.Items {
+			if updated >= budget {
+				break
+			}
 			ordinal := getOrdinal(pod.Name)
-			if ordinal >= int(partition) {
+			// 多余的 Pod 由 scaleDown 逐个删除，不需要更新
+			if ordinal >= int(partition) && ordinal < int(replicas) {
 				if needsUpdate(&pod, mystatefulset) {
+					// 按 snapshotPolicy 先为该序号的 PVC 创建快照，快照就绪前不更新
+					ready, err := r.ensurePreUpdateSnapshots(ctx, mystatefulset, &pod)
+					if err != nil {
+						return err
+					}
+					if !ready {
+						log.Info("Waiting for pre-update snapshots", "pod", pod.Name)
+						return nil
+					}
 					if err := r.Delete(ctx, &pod); err != nil && !errors.IsNotFound(err) {
 						return err
 					}
@@ -281,15 +433,19 @@ func (r *MyStatefulsetReconciler) reconcilePods(ctx context.Context, mystatefuls
 					if err := r.createPod(ctx, mystatefulset, ordinal); err != nil {
 						return err
 					}
-					// 每次只更新一个 Pod
-					return nil
+					updated++
 				}
 			}
 		}
+		// 新 Pod 可用前不继续处理
+		if updated > 0 {
+			return nil
+		}
 	}
 
 	// 处理常规的 Pod 创建和删除
-	for i := 0; i < int(mystatefulset.Spec.Replicas); i++ {
+	resuming := isResuming(mystatefulset)
+	for i := 0; i < int(replicas); i++ {
 		podName := fmt.Sprintf("%s-%d", mystatefulset.Name, i)
 		log.Info("Checking pod", "podName", podName)
 
@@ -316,22 +472,19 @@ func (r *MyStatefulsetReconciler) reconcilePods(ctx context.Context, mystatefuls
 					"error", err)
 				return err
 			}
-		}
-	}
-
-	// 删除多余的 Pods
-	for _, pod := range existingPods.Items {
-		ordinal := getOrdinal(pod.Name)
-		if ordinal >= int(mystatefulset.Spec.Replicas) {
-			if err := r.Delete(ctx, &pod); err != nil && !errors.IsNotFound(err) {
-				return err
+			// 从暂停中恢复时每次只创建一个 Pod
+			if resuming {
+				break
 			}
+		} else if resuming && !isPodReady(&existingPod) {
+			// 等前一个序号 Ready 后再创建下一个
+			break
 		}
 	}
 
 	log.V(1).Info("Reconciling pods",
 		"existingPods", len(existingPods.Items),
-		"desiredReplicas", mystatefulset.Spec.Replicas,
+		"desiredReplicas", replicas,
 	)
 
 	return nil
@@ -347,17 +500,34 @@ func (r *MyStatefulsetReconciler) createPod(ctx context.Context, mystatefulset *
 		return fmt.Errorf("pod template must contain at least one container")
 	}
 
+	// 应用该序号的 ordinalOverrides，并以最终模板的哈希作为 Pod 的修订版本
+	template, err := mystatefulset.PodTemplateForOrdinal(int32(ordinal))
+	if err != nil {
+		return fmt.Errorf("failed to build template for Pod %s: %w", podName, err)
+	}
+	podLabels := make(map[string]string, len(template.Labels)+1)
+	for k, v := range template.Labels {
+		podLabels[k] = v
+	}
+	podLabels[k8sappsv1.ControllerRevisionHashLabelKey] = podRevisionHash(template)
+
 	// Create pod with additional logging
 	pod := &corev1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      podName,
 			Namespace: mystatefulset.Namespace,
-			Labels:    mystatefulset.Spec.Template.Labels,
+			Labels:    podLabels,
 			OwnerReferences: []metav1.OwnerReference{
 				*metav1.NewControllerRef(mystatefulset, appsv1.GroupVersion.WithKind("MyStatefulset")),
 			},
 		},
-		Spec: *mystatefulset.Spec.Template.Spec.DeepCopy(),
+		Spec: template.Spec,
+	}
+
+	// 注入 ready-to-serve readiness gate，由 admitPods 设置对应条件
+	if !hasReadyToServeGate(pod) {
+		pod.Spec.ReadinessGates = append(pod.Spec.ReadinessGates,
+			corev1.PodReadinessGate{ConditionType: appsv1.PodConditionReadyToServe})
 	}
 
 	// 设置 hostname 和 subdomain
@@ -366,33 +536,23 @@ func (r *MyStatefulsetReconciler) createPod(ctx context.Context, mystatefulset *
 		pod.Spec.Subdomain = mystatefulset.Spec.ServiceName
 	}
 
-	// 初始化 volumes 数组
-	pod.Spec.Volumes = []corev1.Volume{}
-
-	// 为每个 PVC 模板创建 volume
-	for _, pvcTemplate := range mystatefulset.Spec.VolumeClaimTemplates {
-		volumeName := "www" // 使用固定的名称，与 volumeMount 匹配
-		if pvcTemplate.Name != "" {
-			volumeName = pvcTemplate.Name
-		}
-
-		pvcName := fmt.Sprintf("%s-%s-%d", volumeName, mystatefulset.Name, ordinal)
-
-		volume := corev1.Volume{
-			Name: volumeName, // 使用相同的名称
-			VolumeSource: corev1.VolumeSource{
-				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
-					ClaimName: pvcName,
-				},
-			},
+	// 保留模板中的 volumes（configMap、secret、emptyDir 等），再追加 PVC 对应的 volume。
+	// webhook 会拒绝与 PVC 模板同名的模板 volume，这里仍以 PVC 为准，避免挂载到错误的卷
+	claimVolumes := claimVolumesForOrdinal(mystatefulset, ordinal)
+	claimVolumeNames := make(map[string]bool, len(claimVolumes))
+	for _, volume := range claimVolumes {
+		claimVolumeNames[volume.Name] = true
+	}
+	templateVolumes := pod.Spec.Volumes
+	pod.Spec.Volumes = make([]corev1.Volume, 0, len(templateVolumes)+len(claimVolumes))
+	for _, volume := range templateVolumes {
+		if claimVolumeNames[volume.Name] {
+			log.Info("Template volume is shadowed by volumeClaimTemplate", "volumeName", volume.Name)
+			continue
 		}
-
-		log.Info("Adding volume to pod",
-			"volumeName", volume.Name,
-			"pvcName", pvcName)
-
 		pod.Spec.Volumes = append(pod.Spec.Volumes, volume)
 	}
+	pod.Spec.Volumes = append(pod.Spec.Volumes, claimVolumes...)
 
 	log.Info("Creating pod with volumes",
 		"pod", pod.Name,
@@ -400,7 +560,7 @@ func (r *MyStatefulsetReconciler) createPod(ctx context.Context, mystatefulset *
 		"volumes", pod.Spec.Volumes,
 		"volumeMounts", pod.Spec.Containers[0].VolumeMounts)
 
-	err := r.Create(ctx, pod)
+	err = r.Create(ctx, pod)
 	if err != nil {
 		if errors.IsAlreadyExists(err) {
 			log.Info("Pod already exists", "pod", podName)
@@ -417,16 +577,24 @@ func (r *MyStatefulsetReconciler) createPod(ctx context.Context, mystatefulset *
 func (r *MyStatefulsetReconciler) updateStatus(ctx context.Context, mystatefulset *appsv1.MyStatefulset) error {
 	log := log.FromContext(ctx)
 
+	// scale 子资源通过 status.selector 获取 Pod 选择器
+	selector, err := podSelector(mystatefulset)
+	if err != nil {
+		log.Error(err, "Failed to convert label selector")
+		return err
+	}
+
 	podList := &corev1.PodList{}
 	if err := r.List(ctx, podList, client.InNamespace(mystatefulset.Namespace),
-		client.MatchingLabels(mystatefulset.Spec.Selector.MatchLabels)); err != nil {
+		client.MatchingLabelsSelector{Selector: selector}); err != nil {
 		log.Error(err, "Failed to list pods")
 		return err
 	}
+	podList.Items = ownedPods(podList.Items, mystatefulset)
 
 	log.Info("Found pods for MyStatefulset",
 		"podCount", len(podList.Items),
-		"selector", mystatefulset.Spec.Selector.MatchLabels)
+		"selector", selector.String())
 
 	var readyReplicas, currentReplicas, updatedReplicas, availableReplicas int32
 
@@ -456,8 +624,35 @@ func (r *MyStatefulsetReconciler) updateStatus(ctx context.Context, mystatefulse
 		}
 	}
 
-	// 记录旧状态
-	oldStatus := mystatefulset.Status.DeepCopy()
+	pvcList := &corev1.PersistentVolumeClaimList{}
+	if err := r.List(ctx, pvcList, client.InNamespace(mystatefulset.Namespace)); err != nil {
+		log.Error(err, "Failed to list PVCs")
+		return err
+	}
+	pvcs := make(map[string]*corev1.PersistentVolumeClaim, len(pvcList.Items))
+	for i := range pvcList.Items {
+		pvcs[pvcList.Items[i].Name] = &pvcList.Items[i]
+	}
+
+	zoneDistribution, err := r.zoneDistribution(ctx, mystatefulset, podList.Items)
+	if err != nil {
+		log.Error(err, "Failed to compute zone distribution")
+		return err
+	}
+
+	snapshots, err := r.snapshotReferences(ctx, mystatefulset)
+	if err != nil {
+		log.Error(err, "Failed to list volume snapshots")
+		return err
+	}
+
+	// 记录旧状态。调谐过程中在内存里修改的字段（如 Conditions）还没有写入，需要与服务端的状态比较
+	current := &appsv1.MyStatefulset{}
+	if err := r.Get(ctx, client.ObjectKeyFromObject(mystatefulset), current); err != nil {
+		log.Error(err, "Failed to get MyStatefulset")
+		return err
+	}
+	oldStatus := current.Status
 
 	// 更新状态
 	newStatus := appsv1.MyStatefulsetStatus{
@@ -467,7 +662,22 @@ func (r *MyStatefulsetReconciler) updateStatus(ctx context.Context, mystatefulse
 		CurrentReplicas:    currentReplicas,
 		UpdatedReplicas:    updatedReplicas,
 		AvailableReplicas:  availableReplicas,
+		Selector:           selector.String(),
+		ZoneDistribution:   zoneDistribution,
+		PodStatuses:        podStatusDetails(mystatefulset, podList.Items, pvcs),
+		NotReadyOrdinals:   notReadyOrdinals(mystatefulset.GetReplicas(), podList.Items),
+		Snapshots:          snapshots,
+		// 以下字段分别由 replaceFailedPods、applyScalingSchedule、autoscale 和 suspend 维护
+		PodFailures:       mystatefulset.Status.PodFailures,
+		ScalingSchedule:   mystatefulset.Status.ScalingSchedule,
+		Autoscaling:       mystatefulset.Status.Autoscaling,
+		SuspendedReplicas: mystatefulset.Status.SuspendedReplicas,
+		ScaleDown:         scaleDownStatus(mystatefulset, podList.Items),
+		// WaitingForDependencies 由 Reconcile 设置，Available 在这里根据可用副本数设置
+		Conditions: append([]metav1.Condition(nil), mystatefulset.Status.Conditions...),
 	}
+	setAvailable(&newStatus, mystatefulset.GetReplicas(), mystatefulset.Generation)
+	finishResume(&newStatus, mystatefulset.GetReplicas())
 
 	log.Info("Status update",
 		"oldStatus", oldStatus,
@@ -478,7 +688,7 @@ func (r *MyStatefulsetReconciler) updateStatus(ctx context.Context, mystatefulse
 		"availableReplicas", availableReplicas)
 
 	// 只有在状态发生变化时才更新
-	if !reflect.DeepEqual(oldStatus, newStatus) {
+	if !equality.Semantic.DeepEqual(oldStatus, newStatus) {
 		mystatefulset.Status = newStatus
 		if err := r.Status().Update(ctx, mystatefulset); err != nil {
 			log.Error(err, "Failed to update MyStatefulset status")
@@ -492,23 +702,129 @@ func (r *MyStatefulsetReconciler) updateStatus(ctx context.Context, mystatefulse
 	return nil
 }
 
+// zoneDistribution 根据 Pod 所在节点的拓扑标签统计各拓扑域中的 Pod 数量，未设置 placement 时返回 nil
+func (r *MyStatefulsetReconciler) zoneDistribution(ctx context.Context, mystatefulset *appsv1.MyStatefulset, pods []corev1.Pod) ([]appsv1.ZoneReplicas, error) {
+	if mystatefulset.Spec.Placement == nil {
+		return nil, nil
+	}
+	key := mystatefulset.Spec.Placement.GetTopologyKey()
+
+	counts := make(map[string]int32)
+	nodeZones := make(map[string]string)
+	for i := range pods {
+		nodeName := pods[i].Spec.NodeName
+		if nodeName == "" {
+			continue
+		}
+		zone, ok := nodeZones[nodeName]
+		if !ok {
+			node := &corev1.Node{}
+			if err := r.Get(ctx, types.NamespacedName{Name: nodeName}, node); err != nil {
+				if !errors.IsNotFound(err) {
+					return nil, err
+				}
+			}
+			zone = node.Labels[key]
+			nodeZones[nodeName] = zone
+		}
+		if zone != "" {
+			counts[zone]++
+		}
+	}
+
+	distribution := make([]appsv1.ZoneReplicas, 0, len(counts))
+	for zone, replicas := range counts {
+		distribution = append(distribution, appsv1.ZoneReplicas{Zone: zone, Replicas: replicas})
+	}
+	sort.Slice(distribution, func(i, j int) bool {
+		return distribution[i].Zone < distribution[j].Zone
+	})
+	return distribution, nil
+}
+
 // handleDeletion 处理删除操作
 func (r *MyStatefulsetReconciler) handleDeletion(ctx context.Context, mystatefulset *appsv1.MyStatefulset) (ctrl.Result, error) {
 	log := log.FromContext(ctx)
 	log.Info("Handling deletion", "name", mystatefulset.Name, "namespace", mystatefulset.Namespace)
 
+	// finalizer 已经移除，剩余的 finalizer（例如 orphan）由垃圾回收器处理
+	if !controllerutil.ContainsFinalizer(mystatefulset, myStatefulsetFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
 	// 创建一个带超时的上下文
 	timeoutCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
+	selector, err := podSelector(mystatefulset)
+	if err != nil {
+		log.Error(err, "Failed to convert label selector")
+		return ctrl.Result{}, err
+	}
+
+	// 按删除时指定的级联策略处理 Pod 和 PVC
+	switch {
+	case deletionPropagation(mystatefulset) == metav1.DeletePropagationOrphan:
+		// --cascade=orphan：解除 ownerReference，Pod 和 PVC 继续运行，便于迁移到新的控制器
+		log.Info("Orphaning pods and PVCs")
+		if err := r.orphanDependents(timeoutCtx, mystatefulset, selector); err != nil {
+			return ctrl.Result{}, err
+		}
+	case !mystatefulset.Spec.FastDelete:
+		// --cascade=foreground 或 background：按序号逆序逐个删除 Pod，最后删除 PVC
+		if result, done, err := r.teardownInOrder(timeoutCtx, mystatefulset, selector); err != nil || !done {
+			return result, err
+		}
+	default:
+		// 设置了 fastDelete：一次性删除所有 Pod 和 PVC
+		log.Info("Deleting pods and PVCs without ordering", "fastDelete", mystatefulset.Spec.FastDelete)
+		if err := r.deleteDependents(timeoutCtx, mystatefulset, selector); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	// 所有资源都已处理，移除 finalizer
+	log.Info("Removing finalizer")
+	controllerutil.RemoveFinalizer(mystatefulset, myStatefulsetFinalizer)
+	if err := r.Update(timeoutCtx, mystatefulset); err != nil {
+		if !errors.IsNotFound(err) {
+			log.Error(err, "Failed to remove finalizer")
+			return ctrl.Result{}, err
+		}
+		// 资源已经被删除，直接返回
+		return ctrl.Result{}, nil
+	}
+
+	log.Info("Successfully handled deletion")
+	return ctrl.Result{}, nil
+}
+
+// deletionPropagation 根据 API Server 添加的 finalizer 判断删除时使用的级联策略
+func deletionPropagation(mystatefulset *appsv1.MyStatefulset) metav1.DeletionPropagation {
+	switch {
+	case controllerutil.ContainsFinalizer(mystatefulset, metav1.FinalizerOrphanDependents):
+		return metav1.DeletePropagationOrphan
+	case controllerutil.ContainsFinalizer(mystatefulset, metav1.FinalizerDeleteDependents):
+		return metav1.DeletePropagationForeground
+	default:
+		return metav1.DeletePropagationBackground
+	}
+}
+
+// teardownInOrder 按序号逆序逐个删除 Pod，Pod 全部删除后再删除 PVC。
+// 返回的 done 为 true 表示所有资源都已删除。
+func (r *MyStatefulsetReconciler) teardownInOrder(ctx context.Context, mystatefulset *appsv1.MyStatefulset, selector labels.Selector) (ctrl.Result, bool, error) {
+	log := log.FromContext(ctx)
+
 	// 检查是否仍然存在 Pod
 	podList := &corev1.PodList{}
-	if err := r.List(timeoutCtx, podList,
+	if err := r.List(ctx, podList,
 		client.InNamespace(mystatefulset.Namespace),
-		client.MatchingLabels(mystatefulset.Spec.Selector.MatchLabels)); err != nil {
+		client.MatchingLabelsSelector{Selector: selector}); err != nil {
 		log.Error(err, "Failed to list pods")
-		return ctrl.Result{}, err
+		return ctrl.Result{}, false, err
 	}
+	podList.Items = controlledPods(podList.Items, mystatefulset)
 
 	// 如果还有 Pod 存在，按照逆序删除
 	if len(podList.Items) > 0 {
@@ -525,99 +841,267 @@ func (r *MyStatefulsetReconciler) handleDeletion(ctx context.Context, mystateful
 		if pod.DeletionTimestamp != nil {
 			log.Info("Pod is already being deleted", "pod", pod.Name)
 			// 如果 Pod 正在删除中，等待短暂时间后重新排队
-			return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+			return ctrl.Result{RequeueAfter: 5 * time.Second}, false, nil
 		}
 
 		// 删除 Pod
-		if err := r.Delete(timeoutCtx, pod); err != nil {
+		if err := r.Delete(ctx, pod); err != nil {
 			if !errors.IsNotFound(err) {
 				log.Error(err, "Failed to delete pod", "pod", pod.Name)
-				return ctrl.Result{}, err
+				return ctrl.Result{}, false, err
 			}
 			// Pod 已经不存在，继续处理
 			log.Info("Pod already deleted", "pod", pod.Name)
 		}
 
 		// 重新排队以检查剩余的 Pod
-		return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+		return ctrl.Result{RequeueAfter: 5 * time.Second}, false, nil
 	}
 
 	// 检查 PVC
 	pvcList := &corev1.PersistentVolumeClaimList{}
-	if err := r.List(timeoutCtx, pvcList,
+	if err := r.List(ctx, pvcList,
 		client.InNamespace(mystatefulset.Namespace),
-		client.MatchingLabels(mystatefulset.Spec.Selector.MatchLabels)); err != nil {
+		client.MatchingLabelsSelector{Selector: selector}); err != nil {
 		log.Error(err, "Failed to list PVCs")
-		return ctrl.Result{}, err
+		return ctrl.Result{}, false, err
 	}
+	pvcList.Items = controlledClaims(pvcList.Items, mystatefulset)
 
 	// 如果还有 PVC 存在，删除它们
 	if len(pvcList.Items) > 0 {
 		pvc := &pvcList.Items[0]
 		log.Info("Deleting PVC", "pvc", pvc.Name)
 
-		if err := r.Delete(timeoutCtx, pvc); err != nil {
+		if err := r.Delete(ctx, pvc); err != nil {
 			if !errors.IsNotFound(err) {
 				log.Error(err, "Failed to delete PVC", "pvc", pvc.Name)
-				return ctrl.Result{}, err
+				return ctrl.Result{}, false, err
 			}
 			log.Info("PVC already deleted", "pvc", pvc.Name)
 		}
 
 		// 重新排队以检查剩余的 PVC
-		return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+		return ctrl.Result{RequeueAfter: 5 * time.Second}, false, nil
 	}
 
-	// 所有资源都已清理，移除 finalizer
-	if controllerutil.ContainsFinalizer(mystatefulset, myStatefulsetFinalizer) {
-		log.Info("Removing finalizer")
-		controllerutil.RemoveFinalizer(mystatefulset, myStatefulsetFinalizer)
-		if err := r.Update(timeoutCtx, mystatefulset); err != nil {
-			if !errors.IsNotFound(err) {
-				log.Error(err, "Failed to remove finalizer")
-				return ctrl.Result{}, err
+	return ctrl.Result{}, true, nil
+}
+
+// deleteDependents 一次性删除所有 Pod 和 PVC，不等待它们真正消失
+func (r *MyStatefulsetReconciler) deleteDependents(ctx context.Context, mystatefulset *appsv1.MyStatefulset, selector labels.Selector) error {
+	log := log.FromContext(ctx)
+
+	podList := &corev1.PodList{}
+	if err := r.List(ctx, podList,
+		client.InNamespace(mystatefulset.Namespace),
+		client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		log.Error(err, "Failed to list pods")
+		return err
+	}
+	podList.Items = controlledPods(podList.Items, mystatefulset)
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		if err := r.Delete(ctx, pod); err != nil && !errors.IsNotFound(err) {
+			log.Error(err, "Failed to delete pod", "pod", pod.Name)
+			return err
+		}
+	}
+
+	pvcList := &corev1.PersistentVolumeClaimList{}
+	if err := r.List(ctx, pvcList,
+		client.InNamespace(mystatefulset.Namespace),
+		client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		log.Error(err, "Failed to list PVCs")
+		return err
+	}
+	pvcList.Items = controlledClaims(pvcList.Items, mystatefulset)
+	for i := range pvcList.Items {
+		pvc := &pvcList.Items[i]
+		if err := r.Delete(ctx, pvc); err != nil && !errors.IsNotFound(err) {
+			log.Error(err, "Failed to delete PVC", "pvc", pvc.Name)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// orphanDependents 移除 Pod 和 PVC 上指向该 MyStatefulset 的 ownerReference，保留资源本身
+func (r *MyStatefulsetReconciler) orphanDependents(ctx context.Context, mystatefulset *appsv1.MyStatefulset, selector labels.Selector) error {
+	log := log.FromContext(ctx)
+
+	podList := &corev1.PodList{}
+	if err := r.List(ctx, podList,
+		client.InNamespace(mystatefulset.Namespace),
+		client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		log.Error(err, "Failed to list pods")
+		return err
+	}
+	podList.Items = controlledPods(podList.Items, mystatefulset)
+	pvcList := &corev1.PersistentVolumeClaimList{}
+	if err := r.List(ctx, pvcList,
+		client.InNamespace(mystatefulset.Namespace),
+		client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		log.Error(err, "Failed to list PVCs")
+		return err
+	}
+	pvcList.Items = controlledClaims(pvcList.Items, mystatefulset)
+
+	dependents := make([]client.Object, 0, len(podList.Items)+len(pvcList.Items))
+	for i := range podList.Items {
+		dependents = append(dependents, &podList.Items[i])
+	}
+	for i := range pvcList.Items {
+		dependents = append(dependents, &pvcList.Items[i])
+	}
+
+	for _, obj := range dependents {
+		refs := obj.GetOwnerReferences()
+		kept := make([]metav1.OwnerReference, 0, len(refs))
+		for _, ref := range refs {
+			if ref.UID != mystatefulset.UID {
+				kept = append(kept, ref)
 			}
-			// 资源已经被删除，直接返回
-			return ctrl.Result{}, nil
 		}
+		if len(kept) == len(refs) {
+			continue
+		}
+
+		patch := client.MergeFrom(obj.DeepCopyObject().(client.Object))
+		obj.SetOwnerReferences(kept)
+		if err := r.Patch(ctx, obj, patch); err != nil && !errors.IsNotFound(err) {
+			log.Error(err, "Failed to remove ownerReference", "object", obj.GetName())
+			return err
+		}
+		log.Info("Orphaned object", "object", obj.GetName())
 	}
 
-	log.Info("Successfully handled deletion")
-	return ctrl.Result{}, nil
+	return nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *MyStatefulsetReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	r.Recorder = mgr.GetEventRecorderFor(controllerName)
 
-	return ctrl.NewControllerManagedBy(mgr).
+	c, err := ctrl.NewControllerManagedBy(mgr).
 		For(&appsv1.MyStatefulset{}).
 		Owns(&corev1.Pod{}).
 		Owns(&corev1.PersistentVolumeClaim{}).
-		Complete(r)
+		// 被依赖的 MyStatefulset 变化时调谐依赖它的 MyStatefulset，其他依赖类型在首次使用时添加 watch
+		Watches(&source.Kind{Type: &appsv1.MyStatefulset{}},
+			handler.EnqueueRequestsFromMapFunc(r.dependentsOf(myStatefulsetGVK))).
+		Build(r)
+	if err != nil {
+		return err
+	}
+	r.controller = c
+	return nil
 }
 
 // 工具函数
+
+// podSelector 将 spec.selector 转换为 labels.Selector，同时支持 matchLabels 和 matchExpressions
+func podSelector(mystatefulset *appsv1.MyStatefulset) (labels.Selector, error) {
+	if mystatefulset.Spec.Selector == nil {
+		return nil, fmt.Errorf("selector cannot be nil")
+	}
+	selector, err := metav1.LabelSelectorAsSelector(mystatefulset.Spec.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector: %w", err)
+	}
+	return selector, nil
+}
+
+// ownedPods 过滤掉由其他控制器管理的 Pod。克隆出的对象与源对象的标签有重叠，
+// 源对象的选择器也会选中克隆出的 Pod
+func ownedPods(pods []corev1.Pod, mystatefulset *appsv1.MyStatefulset) []corev1.Pod {
+	owned := pods[:0]
+	for _, pod := range pods {
+		if ref := metav1.GetControllerOf(&pod); ref != nil && ref.UID != mystatefulset.UID {
+			continue
+		}
+		owned = append(owned, pod)
+	}
+	return owned
+}
+
+// controlledPods 只保留 controller ownerReference 指向该 MyStatefulset 的 Pod。
+// 删除时使用，避免按选择器误删克隆对象或其他控制器的 Pod
+func controlledPods(pods []corev1.Pod, mystatefulset *appsv1.MyStatefulset) []corev1.Pod {
+	controlled := pods[:0]
+	for i := range pods {
+		if metav1.IsControlledBy(&pods[i], mystatefulset) {
+			controlled = append(controlled, pods[i])
+		}
+	}
+	return controlled
+}
+
+// controlledClaims 只保留 controller ownerReference 指向该 MyStatefulset 的 PVC
+func controlledClaims(pvcs []corev1.PersistentVolumeClaim, mystatefulset *appsv1.MyStatefulset) []corev1.PersistentVolumeClaim {
+	controlled := pvcs[:0]
+	for i := range pvcs {
+		if metav1.IsControlledBy(&pvcs[i], mystatefulset) {
+			controlled = append(controlled, pvcs[i])
+		}
+	}
+	return controlled
+}
+
+// getOrdinal 从 Pod 名称（<name>-<ordinal>）中解析序号
 func getOrdinal(podName string) int {
-	ordinalStr := podName[len(podName)-1:]
+	ordinalStr := podName[strings.LastIndex(podName, "-")+1:]
 	ordinal, _ := strconv.Atoi(ordinalStr)
 	return ordinal
 }
 
+// podRevisionHash 计算 Pod 模板的哈希，作为 Pod 的修订版本
+func podRevisionHash(template *appsv1.PodTemplateSpec) string {
+	hasher := fnv.New32a()
+	data, _ := json.Marshal(template)
+	hasher.Write(data)
+	return rand.SafeEncodeString(fmt.Sprint(hasher.Sum32()))
+}
+
+// podRevisionMatches 比较带修订哈希的 Pod 与其序号对应的模板；
+// ok 为 false 表示 Pod 没有修订哈希（由旧版本控制器创建）
+func podRevisionMatches(pod *corev1.Pod, template *appsv1.PodTemplateSpec) (matches, ok bool) {
+	hash, ok := pod.Labels[k8sappsv1.ControllerRevisionHashLabelKey]
+	if !ok {
+		return false, false
+	}
+	return hash == podRevisionHash(template), true
+}
+
 func needsUpdate(pod *corev1.Pod, mystatefulset *appsv1.MyStatefulset) bool {
+	template, err := mystatefulset.PodTemplateForOrdinal(int32(getOrdinal(pod.Name)))
+	if err != nil {
+		// 覆盖配置无法应用时不重建 Pod，错误会在创建 Pod 时报告
+		return false
+	}
+
+	// 覆盖配置计入修订哈希，只有对应序号的 Pod 会被更新
+	if matches, ok := podRevisionMatches(pod, template); ok {
+		return !matches
+	}
+
 	// 检查 Pod 标签
-	if !reflect.DeepEqual(pod.Labels, mystatefulset.Spec.Template.Labels) {
+	if !reflect.DeepEqual(pod.Labels, template.Labels) {
 		return true
 	}
 
 	// 检查容器规格
-	if len(pod.Spec.Containers) != len(mystatefulset.Spec.Template.Spec.Containers) {
+	if len(pod.Spec.Containers) != len(template.Spec.Containers) {
 		return true
 	}
 
 	// 检查每个容器的镜像和资源
 	for i, container := range pod.Spec.Containers {
-		templateContainer := mystatefulset.Spec.Template.Spec.Containers[i]
+		templateContainer := template.Spec.Containers[i]
 		if container.Image != templateContainer.Image {
 			return true
 		}
@@ -655,19 +1139,27 @@ func isPodUpdated(pod *corev1.Pod, mystatefulset *appsv1.MyStatefulset) bool {
 		return false
 	}
 
+	template, err := mystatefulset.PodTemplateForOrdinal(int32(getOrdinal(pod.Name)))
+	if err != nil {
+		return false
+	}
+	if matches, ok := podRevisionMatches(pod, template); ok {
+		return matches
+	}
+
 	// 检查容器镜像
-	if len(pod.Spec.Containers) != len(mystatefulset.Spec.Template.Spec.Containers) {
+	if len(pod.Spec.Containers) != len(template.Spec.Containers) {
 		return false
 	}
 
 	for i, container := range pod.Spec.Containers {
-		if container.Image != mystatefulset.Spec.Template.Spec.Containers[i].Image {
+		if container.Image != template.Spec.Containers[i].Image {
 			return false
 		}
 	}
 
 	// 检查标签
-	for key, value := range mystatefulset.Spec.Template.Labels {
+	for key, value := range template.Labels {
 		if pod.Labels[key] != value {
 			return false
 		}
@@ -676,6 +1168,19 @@ func isPodUpdated(pod *corev1.Pod, mystatefulset *appsv1.MyStatefulset) bool {
 	return true
 }
 
+// unavailableReplicas 统计 spec.replicas 范围内缺失、正在终止或尚未可用的 Pod 数
+func unavailableReplicas(mystatefulset *appsv1.MyStatefulset, pods []corev1.Pod) int {
+	available := 0
+	for i := range pods {
+		pod := &pods[i]
+		if getOrdinal(pod.Name) < int(mystatefulset.GetReplicas()) && pod.DeletionTimestamp == nil &&
+			isPodAvailable(pod, mystatefulset.Spec.MinReadySeconds) {
+			available++
+		}
+	}
+	return int(mystatefulset.GetReplicas()) - available
+}
+
 func isPodAvailable(pod *corev1.Pod, minReadySeconds int32) bool {
 	if !isPodReady(pod) {
 		return false
@@ -8,15 +8,20 @@ import (
 	appsv1 "github.com/bryant-rh/my-statefulset/api/v1"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	k8sappsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
 func TestMyStatefulsetReconciler_Reconcile(t *testing.T) {
@@ -42,7 +47,7 @@ func TestMyStatefulsetReconciler_Reconcile(t *testing.T) {
 					Namespace: "default",
 				},
 				Spec: appsv1.MyStatefulsetSpec{
-					Replicas:    3,
+					Replicas:    pointer.Int32(3),
 					ServiceName: "test-service",
 					Selector: &metav1.LabelSelector{
 						MatchLabels: map[string]string{
@@ -83,7 +88,7 @@ func TestMyStatefulsetReconciler_Reconcile(t *testing.T) {
 					Namespace: "default",
 				},
 				Spec: appsv1.MyStatefulsetSpec{
-					Replicas:    3,
+					Replicas:    pointer.Int32(3),
 					ServiceName: "test-service",
 					Selector: &metav1.LabelSelector{
 						MatchLabels: map[string]string{
@@ -124,7 +129,7 @@ func TestMyStatefulsetReconciler_Reconcile(t *testing.T) {
 					Namespace: "default",
 				},
 				Spec: appsv1.MyStatefulsetSpec{
-					Replicas:    1,
+					Replicas:    pointer.Int32(1),
 					ServiceName: "test-service",
 					Selector: &metav1.LabelSelector{
 						MatchLabels: map[string]string{
@@ -165,7 +170,7 @@ func TestMyStatefulsetReconciler_Reconcile(t *testing.T) {
 					Namespace: "default",
 				},
 				Spec: appsv1.MyStatefulsetSpec{
-					Replicas:    3,
+					Replicas:    pointer.Int32(3),
 					ServiceName: "test-service",
 					Selector: &metav1.LabelSelector{
 						MatchLabels: map[string]string{
@@ -259,6 +264,361 @@ func TestMyStatefulsetReconciler_Reconcile(t *testing.T) {
 	}
 }
 
+func TestMyStatefulsetReconciler_ScaleToZero(t *testing.T) {
+	s := runtime.NewScheme()
+	_ = appsv1.AddToScheme(s)
+	_ = corev1.AddToScheme(s)
+
+	myStatefulset := &appsv1.MyStatefulset{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-statefulset",
+			Namespace: "default",
+		},
+		Spec: appsv1.MyStatefulsetSpec{
+			Replicas:    pointer.Int32(0),
+			ServiceName: "test-service",
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"app": "test",
+				},
+			},
+			Template: appsv1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						"app": "test",
+					},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:  "test-container",
+							Image: "nginx:latest",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-service",
+			Namespace: "default",
+		},
+		Spec: corev1.ServiceSpec{
+			ClusterIP: "None",
+		},
+	}
+
+	c := fake.NewClientBuilder().
+		WithScheme(s).
+		WithObjects(myStatefulset, service).
+		Build()
+
+	for i := 0; i < 2; i++ {
+		require.NoError(t, c.Create(context.Background(), createTestPod(fmt.Sprintf("test-statefulset-%d", i))))
+	}
+
+	r := &MyStatefulsetReconciler{
+		Client:   c,
+		Scheme:   s,
+		Recorder: record.NewFakeRecorder(100),
+	}
+
+	_, err := r.Reconcile(context.Background(), ctrl.Request{
+		NamespacedName: types.NamespacedName{Name: "test-statefulset", Namespace: "default"},
+	})
+	require.NoError(t, err)
+
+	// replicas=0 时所有 Pod 都应被删除
+	pods := &corev1.PodList{}
+	require.NoError(t, c.List(context.Background(), pods, client.InNamespace("default")))
+	assert.Empty(t, pods.Items)
+
+	updated := &appsv1.MyStatefulset{}
+	require.NoError(t, c.Get(context.Background(), types.NamespacedName{Name: "test-statefulset", Namespace: "default"}, updated))
+	require.NotNil(t, updated.Spec.Replicas)
+	assert.Equal(t, int32(0), *updated.Spec.Replicas)
+	assert.Equal(t, "app=test", updated.Status.Selector)
+}
+
+func TestMyStatefulsetReconciler_expandPVC(t *testing.T) {
+	s := runtime.NewScheme()
+	_ = appsv1.AddToScheme(s)
+	_ = corev1.AddToScheme(s)
+
+	myStatefulset := &appsv1.MyStatefulset{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-statefulset",
+			Namespace: "default",
+		},
+	}
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "www-test-statefulset-0",
+			Namespace: "default",
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceStorage: resource.MustParse("1Gi"),
+				},
+			},
+		},
+	}
+	pvcTemplate := &corev1.PersistentVolumeClaim{
+		Spec: corev1.PersistentVolumeClaimSpec{
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceStorage: resource.MustParse("2Gi"),
+				},
+			},
+		},
+	}
+
+	c := fake.NewClientBuilder().WithScheme(s).WithObjects(pvc).Build()
+	r := &MyStatefulsetReconciler{
+		Client:   c,
+		Scheme:   s,
+		Recorder: record.NewFakeRecorder(10),
+	}
+
+	require.NoError(t, r.expandPVC(context.Background(), myStatefulset, pvc, pvcTemplate))
+
+	updated := &corev1.PersistentVolumeClaim{}
+	require.NoError(t, c.Get(context.Background(), types.NamespacedName{Name: pvc.Name, Namespace: "default"}, updated))
+	size := updated.Spec.Resources.Requests[corev1.ResourceStorage]
+	assert.Equal(t, "2Gi", size.String())
+}
+
+func TestMyStatefulsetReconciler_handleDeletion(t *testing.T) {
+	s := runtime.NewScheme()
+	_ = appsv1.AddToScheme(s)
+	_ = corev1.AddToScheme(s)
+
+	tests := []struct {
+		name            string
+		finalizer       string
+		fastDelete      bool
+		wantPods        int
+		wantPVCs        int
+		wantOwnerRefs   bool
+		wantRequeue     bool
+		wantFinalizerOn bool
+	}{
+		{
+			name:          "orphan keeps pods and PVCs without ownerReferences",
+			finalizer:     metav1.FinalizerOrphanDependents,
+			wantPods:      2,
+			wantPVCs:      2,
+			wantOwnerRefs: false,
+		},
+		{
+			name:          "orphan ignores fastDelete",
+			finalizer:     metav1.FinalizerOrphanDependents,
+			fastDelete:    true,
+			wantPods:      2,
+			wantPVCs:      2,
+			wantOwnerRefs: false,
+		},
+		{
+			name:            "background deletes the highest ordinal first",
+			wantPods:        1,
+			wantPVCs:        2,
+			wantOwnerRefs:   true,
+			wantRequeue:     true,
+			wantFinalizerOn: true,
+		},
+		{
+			name:       "background with fastDelete deletes everything at once",
+			fastDelete: true,
+			wantPods:   0,
+			wantPVCs:   0,
+		},
+		{
+			name:            "foreground deletes the highest ordinal first",
+			finalizer:       metav1.FinalizerDeleteDependents,
+			wantPods:        1,
+			wantPVCs:        2,
+			wantOwnerRefs:   true,
+			wantRequeue:     true,
+			wantFinalizerOn: true,
+		},
+		{
+			name:       "foreground with fastDelete deletes everything at once",
+			finalizer:  metav1.FinalizerDeleteDependents,
+			fastDelete: true,
+			wantPods:   0,
+			wantPVCs:   0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := metav1.Now()
+			finalizers := []string{myStatefulsetFinalizer}
+			if tt.finalizer != "" {
+				finalizers = append(finalizers, tt.finalizer)
+			}
+			myStatefulset := &appsv1.MyStatefulset{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "test-statefulset",
+					Namespace:         "default",
+					UID:               "test-uid",
+					DeletionTimestamp: &now,
+					Finalizers:        finalizers,
+				},
+				Spec: appsv1.MyStatefulsetSpec{
+					Replicas:   pointer.Int32(2),
+					FastDelete: tt.fastDelete,
+					Selector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{"app": "test"},
+					},
+				},
+			}
+
+			objs := []client.Object{myStatefulset}
+			for i := 0; i < 2; i++ {
+				objs = append(objs,
+					createPodWithOwner(fmt.Sprintf("test-statefulset-%d", i), "test-uid"),
+					&corev1.PersistentVolumeClaim{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:            fmt.Sprintf("www-test-statefulset-%d", i),
+							Namespace:       "default",
+							Labels:          map[string]string{"app": "test"},
+							OwnerReferences: createPodWithOwner("", "test-uid").OwnerReferences,
+						},
+					})
+			}
+			// 克隆对象保留了源对象的模板标签，源对象的选择器也会选中它的 Pod 和 PVC
+			clonePod := createPodWithOwner("test-statefulset-staging-9", "clone-uid")
+			clonePVC := &corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            "www-test-statefulset-staging-9",
+					Namespace:       "default",
+					Labels:          map[string]string{"app": "test"},
+					OwnerReferences: createPodWithOwner("", "clone-uid").OwnerReferences,
+				},
+			}
+			objs = append(objs, clonePod, clonePVC)
+			c := fake.NewClientBuilder().WithScheme(s).WithObjects(objs...).Build()
+			r := &MyStatefulsetReconciler{Client: c, Scheme: s}
+
+			result, err := r.handleDeletion(context.Background(), myStatefulset)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantRequeue, result.RequeueAfter > 0)
+
+			for _, obj := range []client.Object{&corev1.Pod{}, &corev1.PersistentVolumeClaim{}} {
+				name := clonePod.Name
+				if _, ok := obj.(*corev1.PersistentVolumeClaim); ok {
+					name = clonePVC.Name
+				}
+				require.NoError(t, c.Get(context.Background(), types.NamespacedName{Name: name, Namespace: "default"}, obj))
+				assert.Len(t, obj.GetOwnerReferences(), 1, name)
+				require.NoError(t, c.Delete(context.Background(), obj))
+			}
+
+			pods := &corev1.PodList{}
+			require.NoError(t, c.List(context.Background(), pods, client.InNamespace("default")))
+			assert.Len(t, pods.Items, tt.wantPods)
+			pvcs := &corev1.PersistentVolumeClaimList{}
+			require.NoError(t, c.List(context.Background(), pvcs, client.InNamespace("default")))
+			assert.Len(t, pvcs.Items, tt.wantPVCs)
+
+			for _, pod := range pods.Items {
+				assert.Equal(t, tt.wantOwnerRefs, len(pod.OwnerReferences) > 0, "pod %s", pod.Name)
+			}
+			for _, pvc := range pvcs.Items {
+				assert.Equal(t, tt.wantOwnerRefs, len(pvc.OwnerReferences) > 0, "pvc %s", pvc.Name)
+			}
+			if tt.wantPods == 1 {
+				assert.Equal(t, "test-statefulset-0", pods.Items[0].Name)
+			}
+			assert.Equal(t, tt.wantFinalizerOn, controllerutil.ContainsFinalizer(myStatefulset, myStatefulsetFinalizer))
+		})
+	}
+}
+
+func TestMyStatefulsetReconciler_reconcilePodsRollingUpdate(t *testing.T) {
+	s := runtime.NewScheme()
+	_ = appsv1.AddToScheme(s)
+	_ = corev1.AddToScheme(s)
+
+	tests := []struct {
+		name           string
+		paused         bool
+		maxUnavailable *intstr.IntOrString
+		notReady       []string
+		wantUpdated    []string
+	}{
+		{
+			name:        "default updates the highest ordinal",
+			wantUpdated: []string{"test-statefulset-3"},
+		},
+		{
+			name:           "maxUnavailable updates several pods at once",
+			maxUnavailable: &intstr.IntOrString{Type: intstr.String, StrVal: "50%"},
+			wantUpdated:    []string{"test-statefulset-3", "test-statefulset-2"},
+		},
+		{
+			name:           "unavailable pods use up the budget",
+			maxUnavailable: &intstr.IntOrString{Type: intstr.Int, IntVal: 2},
+			notReady:       []string{"test-statefulset-0"},
+			wantUpdated:    []string{"test-statefulset-3"},
+		},
+		{
+			name:     "no budget left",
+			notReady: []string{"test-statefulset-0"},
+		},
+		{
+			name:   "paused",
+			paused: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms := newDependentMyStatefulset()
+			ms.Spec.Replicas = pointer.Int32(4)
+			ms.Spec.UpdateStrategy = appsv1.UpdateStrategy{
+				Type:          appsv1.RollingUpdateStatefulSetStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateStatefulSetStrategy{MaxUnavailable: tt.maxUnavailable},
+				Paused:        tt.paused,
+			}
+			template, err := ms.PodTemplateForOrdinal(0)
+			require.NoError(t, err)
+			current := podRevisionHash(template)
+
+			objects := []client.Object{ms.DeepCopy()}
+			for i := 0; i < 4; i++ {
+				pod := createPodWithOwner(fmt.Sprintf("test-statefulset-%d", i), "test-uid")
+				pod.Labels[k8sappsv1.ControllerRevisionHashLabelKey] = "old"
+				for _, name := range tt.notReady {
+					if name == pod.Name {
+						pod.Status.Conditions[0].Status = corev1.ConditionFalse
+					}
+				}
+				objects = append(objects, pod)
+			}
+			c := fake.NewClientBuilder().WithScheme(s).WithObjects(objects...).Build()
+			r := &MyStatefulsetReconciler{Client: c, Scheme: s, Recorder: record.NewFakeRecorder(10)}
+
+			require.NoError(t, r.reconcilePods(context.Background(), ms))
+
+			var updated []string
+			for i := 3; i >= 0; i-- {
+				pod := &corev1.Pod{}
+				require.NoError(t, c.Get(context.Background(),
+					types.NamespacedName{Name: fmt.Sprintf("test-statefulset-%d", i), Namespace: "default"}, pod))
+				if pod.Labels[k8sappsv1.ControllerRevisionHashLabelKey] == current {
+					updated = append(updated, pod.Name)
+				}
+			}
+			assert.Equal(t, tt.wantUpdated, updated)
+		})
+	}
+}
+
 func TestMyStatefulsetReconciler_createPod(t *testing.T) {
 	s := runtime.NewScheme()
 	_ = appsv1.AddToScheme(s)
@@ -270,7 +630,7 @@ func TestMyStatefulsetReconciler_createPod(t *testing.T) {
 			Namespace: "default",
 		},
 		Spec: appsv1.MyStatefulsetSpec{
-			Replicas:    3,
+			Replicas:    pointer.Int32(3),
 			ServiceName: "test-service",
 			Selector: &metav1.LabelSelector{
 				MatchLabels: map[string]string{
@@ -314,7 +674,164 @@ func TestMyStatefulsetReconciler_createPod(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "test-statefulset-0", pod.Name)
 	assert.Equal(t, "default", pod.Namespace)
-	assert.Equal(t, map[string]string{"app": "test"}, pod.Labels)
+	assert.Equal(t, "test", pod.Labels["app"])
+	assert.Equal(t, podRevisionHash(&myStatefulset.Spec.Template), pod.Labels[k8sappsv1.ControllerRevisionHashLabelKey])
+}
+
+func TestMyStatefulsetReconciler_createPodMergesVolumes(t *testing.T) {
+	s := runtime.NewScheme()
+	_ = appsv1.AddToScheme(s)
+	_ = corev1.AddToScheme(s)
+
+	myStatefulset := &appsv1.MyStatefulset{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-statefulset",
+			Namespace: "default",
+		},
+		Spec: appsv1.MyStatefulsetSpec{
+			Replicas:    pointer.Int32(1),
+			ServiceName: "test-service",
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"app": "test",
+				},
+			},
+			Template: appsv1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						"app": "test",
+					},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:  "test-container",
+							Image: "nginx:latest",
+						},
+					},
+					Volumes: []corev1.Volume{
+						{
+							Name: "config",
+							VolumeSource: corev1.VolumeSource{
+								ConfigMap: &corev1.ConfigMapVolumeSource{
+									LocalObjectReference: corev1.LocalObjectReference{Name: "test-config"},
+								},
+							},
+						},
+						{
+							Name:         "cache",
+							VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+						},
+					},
+				},
+			},
+			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{
+				{ObjectMeta: metav1.ObjectMeta{Name: "data"}},
+				{},
+			},
+		},
+	}
+
+	client := fake.NewClientBuilder().WithScheme(s).Build()
+	r := &MyStatefulsetReconciler{
+		Client: client,
+		Scheme: s,
+	}
+
+	require.NoError(t, r.createPod(context.Background(), myStatefulset, 0))
+
+	pod := &corev1.Pod{}
+	require.NoError(t, client.Get(context.Background(), types.NamespacedName{
+		Name:      "test-statefulset-0",
+		Namespace: "default",
+	}, pod))
+
+	// 模板中的 volume 保留，并追加 PVC 对应的 volume，volume 和 PVC 名称与 PVC 的创建保持一致
+	require.Len(t, pod.Spec.Volumes, 4)
+	assert.Equal(t, "config", pod.Spec.Volumes[0].Name)
+	assert.NotNil(t, pod.Spec.Volumes[0].ConfigMap)
+	assert.Equal(t, "cache", pod.Spec.Volumes[1].Name)
+	assert.NotNil(t, pod.Spec.Volumes[1].EmptyDir)
+	assert.Equal(t, "data", pod.Spec.Volumes[2].Name)
+	require.NotNil(t, pod.Spec.Volumes[2].PersistentVolumeClaim)
+	assert.Equal(t, "data-test-statefulset-0", pod.Spec.Volumes[2].PersistentVolumeClaim.ClaimName)
+	assert.Equal(t, "www", pod.Spec.Volumes[3].Name)
+	require.NotNil(t, pod.Spec.Volumes[3].PersistentVolumeClaim)
+	assert.Equal(t, claimNamesForOrdinal(myStatefulset, 0)[1], pod.Spec.Volumes[3].PersistentVolumeClaim.ClaimName)
+}
+
+func TestMyStatefulsetReconciler_createPodAppliesOrdinalOverrides(t *testing.T) {
+	s := runtime.NewScheme()
+	_ = appsv1.AddToScheme(s)
+	_ = corev1.AddToScheme(s)
+
+	myStatefulset := &appsv1.MyStatefulset{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-statefulset",
+			Namespace: "default",
+		},
+		Spec: appsv1.MyStatefulsetSpec{
+			Replicas:    pointer.Int32(2),
+			ServiceName: "test-service",
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"app": "test",
+				},
+			},
+			Template: appsv1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						"app": "test",
+					},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:  "test-container",
+							Image: "nginx:1.25",
+						},
+					},
+				},
+			},
+			OrdinalOverrides: []appsv1.OrdinalOverride{
+				{
+					Ordinals: []int32{0},
+					Patch: runtime.RawExtension{Raw: []byte(
+						`{"spec":{"containers":[{"name":"test-container","resources":{"limits":{"memory":"4Gi"}}}]}}`)},
+				},
+			},
+		},
+	}
+
+	client := fake.NewClientBuilder().WithScheme(s).Build()
+	r := &MyStatefulsetReconciler{
+		Client: client,
+		Scheme: s,
+	}
+
+	pods := make([]*corev1.Pod, 2)
+	for ordinal := range pods {
+		require.NoError(t, r.createPod(context.Background(), myStatefulset, ordinal))
+		pods[ordinal] = &corev1.Pod{}
+		require.NoError(t, client.Get(context.Background(), types.NamespacedName{
+			Name:      fmt.Sprintf("test-statefulset-%d", ordinal),
+			Namespace: "default",
+		}, pods[ordinal]))
+	}
+
+	// 只有序号 0 应用了覆盖配置
+	memory := pods[0].Spec.Containers[0].Resources.Limits.Memory()
+	assert.Equal(t, 0, memory.Cmp(resource.MustParse("4Gi")))
+	assert.True(t, pods[1].Spec.Containers[0].Resources.Limits.Memory().IsZero())
+	assert.NotEqual(t, pods[0].Labels[k8sappsv1.ControllerRevisionHashLabelKey], pods[1].Labels[k8sappsv1.ControllerRevisionHashLabelKey])
+	assert.False(t, needsUpdate(pods[0], myStatefulset))
+	assert.False(t, needsUpdate(pods[1], myStatefulset))
+
+	// 修改覆盖配置只会触发序号 0 的更新
+	myStatefulset.Spec.OrdinalOverrides[0].Patch.Raw = []byte(
+		`{"spec":{"containers":[{"name":"test-container","resources":{"limits":{"memory":"8Gi"}}}]}}`)
+	assert.True(t, needsUpdate(pods[0], myStatefulset))
+	assert.False(t, needsUpdate(pods[1], myStatefulset))
 }
 
 func TestMyStatefulsetReconciler_updateStatus(t *testing.T) {
@@ -327,6 +844,7 @@ func TestMyStatefulsetReconciler_updateStatus(t *testing.T) {
 		name           string
 		myStatefulset  *appsv1.MyStatefulset
 		pods           []*corev1.Pod
+		nodes          []*corev1.Node
 		expectedStatus appsv1.MyStatefulsetStatus
 	}{
 		{
@@ -338,7 +856,7 @@ func TestMyStatefulsetReconciler_updateStatus(t *testing.T) {
 					UID:       "test-uid",
 				},
 				Spec: appsv1.MyStatefulsetSpec{
-					Replicas: 3,
+					Replicas: pointer.Int32(3),
 					Selector: &metav1.LabelSelector{
 						MatchLabels: map[string]string{
 							"app": "test",
@@ -356,6 +874,76 @@ func TestMyStatefulsetReconciler_updateStatus(t *testing.T) {
 				ReadyReplicas: 3,
 			},
 		},
+		{
+			name: "Selector With MatchExpressions",
+			myStatefulset: &appsv1.MyStatefulset{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-statefulset",
+					Namespace: "default",
+					UID:       "test-uid",
+				},
+				Spec: appsv1.MyStatefulsetSpec{
+					Replicas: pointer.Int32(2),
+					Selector: &metav1.LabelSelector{
+						MatchExpressions: []metav1.LabelSelectorRequirement{
+							{
+								Key:      "app",
+								Operator: metav1.LabelSelectorOpIn,
+								Values:   []string{"test", "canary"},
+							},
+						},
+					},
+				},
+			},
+			pods: []*corev1.Pod{
+				createPodWithOwner("test-statefulset-0", "test-uid"),
+				createPodWithOwner("test-statefulset-1", "test-uid"),
+			},
+			expectedStatus: appsv1.MyStatefulsetStatus{
+				Replicas:      2,
+				ReadyReplicas: 2,
+				Selector:      "app in (canary,test)",
+			},
+		},
+		{
+			name: "Zone Distribution",
+			myStatefulset: &appsv1.MyStatefulset{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-statefulset",
+					Namespace: "default",
+					UID:       "test-uid",
+				},
+				Spec: appsv1.MyStatefulsetSpec{
+					Replicas: pointer.Int32(4),
+					Selector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{
+							"app": "test",
+						},
+					},
+					Placement: &appsv1.PlacementSpec{Zones: []string{"zone-a", "zone-b"}},
+				},
+			},
+			pods: []*corev1.Pod{
+				createScheduledPod("test-statefulset-0", "node-a"),
+				createScheduledPod("test-statefulset-1", "node-b"),
+				createScheduledPod("test-statefulset-2", "node-a"),
+				// 未调度和节点已不存在的 Pod 不计入分布
+				createScheduledPod("test-statefulset-3", ""),
+				createScheduledPod("test-statefulset-4", "node-gone"),
+			},
+			nodes: []*corev1.Node{
+				{ObjectMeta: metav1.ObjectMeta{Name: "node-a", Labels: map[string]string{corev1.LabelTopologyZone: "zone-a"}}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "node-b", Labels: map[string]string{corev1.LabelTopologyZone: "zone-b"}}},
+			},
+			expectedStatus: appsv1.MyStatefulsetStatus{
+				Replicas:      5,
+				ReadyReplicas: 5,
+				ZoneDistribution: []appsv1.ZoneReplicas{
+					{Zone: "zone-a", Replicas: 2},
+					{Zone: "zone-b", Replicas: 1},
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -372,6 +960,10 @@ func TestMyStatefulsetReconciler_updateStatus(t *testing.T) {
 				require.NoError(t, err)
 			}
 
+			for _, node := range tt.nodes {
+				require.NoError(t, client.Create(context.Background(), node))
+			}
+
 			// 创建 reconciler
 			r := &MyStatefulsetReconciler{
 				Client: client,
@@ -385,6 +977,10 @@ func TestMyStatefulsetReconciler_updateStatus(t *testing.T) {
 			// 验证状态
 			assert.Equal(t, tt.expectedStatus.Replicas, tt.myStatefulset.Status.Replicas)
 			assert.Equal(t, tt.expectedStatus.ReadyReplicas, tt.myStatefulset.Status.ReadyReplicas)
+			if tt.expectedStatus.Selector != "" {
+				assert.Equal(t, tt.expectedStatus.Selector, tt.myStatefulset.Status.Selector)
+			}
+			assert.Equal(t, tt.expectedStatus.ZoneDistribution, tt.myStatefulset.Status.ZoneDistribution)
 		})
 	}
 }
@@ -450,6 +1046,24 @@ func TestIsPodReady(t *testing.T) {
 	}
 }
 
+func TestGetOrdinal(t *testing.T) {
+	tests := []struct {
+		podName  string
+		expected int
+	}{
+		{podName: "web-0", expected: 0},
+		{podName: "web-7", expected: 7},
+		{podName: "web-12", expected: 12},
+		{podName: "my-web-105", expected: 105},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.podName, func(t *testing.T) {
+			assert.Equal(t, tt.expected, getOrdinal(tt.podName))
+		})
+	}
+}
+
 // 辅助函数
 func createTestPod(name string) *corev1.Pod {
 	return &corev1.Pod{
@@ -480,6 +1094,12 @@ func createTestPod(name string) *corev1.Pod {
 	}
 }
 
+func createScheduledPod(name, nodeName string) *corev1.Pod {
+	pod := createPodWithOwner(name, "test-uid")
+	pod.Spec.NodeName = nodeName
+	return pod
+}
+
 func createPodWithOwner(name, ownerUID string) *corev1.Pod {
 	trueVal := true
 	return &corev1.Pod{
@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "github.com/bryant-rh/my-statefulset/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// missingDataSources 在初次恢复时返回所需但不存在的数据源，格式为 <Kind>/<name>。
+// 副本集的 PVC 已经存在时初次恢复已经完成，之后新增序号的 PVC 由 reconcilePVCs 处理
+func (r *MyStatefulsetReconciler) missingDataSources(ctx context.Context, mystatefulset *appsv1.MyStatefulset) ([]string, error) {
+	if len(mystatefulset.Spec.VolumeClaimDataSources) == 0 {
+		return nil, nil
+	}
+	restored, err := r.claimsExist(ctx, mystatefulset)
+	if err != nil || restored {
+		return nil, err
+	}
+
+	var missing []string
+	for ordinal := 0; ordinal < int(mystatefulset.GetReplicas()); ordinal++ {
+		for _, pvcTemplate := range mystatefulset.Spec.VolumeClaimTemplates {
+			dataSource := mystatefulset.DataSourceForClaim(appsv1.ClaimVolumeName(pvcTemplate), ordinal)
+			if dataSource == nil {
+				continue
+			}
+			exists, err := r.dataSourceExists(ctx, mystatefulset.Namespace, dataSource)
+			if err != nil {
+				return nil, err
+			}
+			if !exists {
+				missing = append(missing, fmt.Sprintf("%s/%s", dataSource.Kind, dataSource.Name))
+			}
+		}
+	}
+	return missing, nil
+}
+
+// claimsExist 判断 spec.replicas 范围内是否已经存在副本集的 PVC
+func (r *MyStatefulsetReconciler) claimsExist(ctx context.Context, mystatefulset *appsv1.MyStatefulset) (bool, error) {
+	for ordinal := 0; ordinal < int(mystatefulset.GetReplicas()); ordinal++ {
+		for _, claimName := range claimNamesForOrdinal(mystatefulset, ordinal) {
+			err := r.Get(ctx, types.NamespacedName{Name: claimName, Namespace: mystatefulset.Namespace}, &corev1.PersistentVolumeClaim{})
+			if err == nil {
+				return true, nil
+			}
+			if !errors.IsNotFound(err) {
+				return false, err
+			}
+		}
+	}
+	return false, nil
+}
+
+// dataSourceExists 判断 VolumeSnapshot 或 PVC 数据源是否存在，未安装快照 CRD 时视为不存在
+func (r *MyStatefulsetReconciler) dataSourceExists(ctx context.Context, namespace string, dataSource *corev1.TypedLocalObjectReference) (bool, error) {
+	var obj client.Object
+	if dataSource.Kind == volumeSnapshotGVK.Kind {
+		snapshot := &unstructured.Unstructured{}
+		snapshot.SetGroupVersionKind(volumeSnapshotGVK)
+		obj = snapshot
+	} else {
+		obj = &corev1.PersistentVolumeClaim{}
+	}
+
+	err := r.Get(ctx, types.NamespacedName{Name: dataSource.Name, Namespace: namespace}, obj)
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.IsNotFound(err) || meta.IsNoMatchError(err):
+		return false, nil
+	default:
+		return false, err
+	}
+}
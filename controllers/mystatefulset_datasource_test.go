@@ -0,0 +1,102 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "github.com/bryant-rh/my-statefulset/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestMyStatefulsetReconciler_restoreFromDataSources(t *testing.T) {
+	s := runtime.NewScheme()
+	_ = appsv1.AddToScheme(s)
+	_ = corev1.AddToScheme(s)
+	s.AddKnownTypeWithName(volumeSnapshotGVK, &unstructured.Unstructured{})
+
+	ms := &appsv1.MyStatefulset{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default", UID: "test-uid"},
+		Spec: appsv1.MyStatefulsetSpec{
+			Replicas: pointer.Int32(2),
+			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{
+				{ObjectMeta: metav1.ObjectMeta{Name: "data"}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "logs"}},
+			},
+			VolumeClaimDataSources: []appsv1.VolumeClaimDataSource{
+				{ClaimTemplate: "data", VolumeSnapshotName: "db-snap-{{ordinal}}"},
+				{ClaimTemplate: "logs", SourceSet: "db-old"},
+			},
+		},
+	}
+	snapshot := func(name string) client.Object {
+		u := &unstructured.Unstructured{}
+		u.SetGroupVersionKind(volumeSnapshotGVK)
+		u.SetName(name)
+		u.SetNamespace("default")
+		return u
+	}
+	pvc := func(name string) client.Object {
+		return &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}}
+	}
+
+	c := fake.NewClientBuilder().WithScheme(s).WithObjects(
+		snapshot("db-snap-0"),
+		pvc("logs-db-old-0"),
+		pvc("logs-db-old-1"),
+	).Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &MyStatefulsetReconciler{Client: c, Scheme: s, Recorder: recorder}
+	ctx := context.Background()
+
+	// 序号 1 的快照缺失
+	missing, err := r.missingDataSources(ctx, ms)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"VolumeSnapshot/db-snap-1"}, missing)
+
+	// 已有 PVC 时初次恢复已经完成，不再检查数据源
+	require.NoError(t, c.Create(ctx, pvc("data-db-1")))
+	missing, err = r.missingDataSources(ctx, ms)
+	require.NoError(t, err)
+	assert.Empty(t, missing)
+
+	require.NoError(t, r.reconcilePVCs(ctx, ms))
+
+	data := &corev1.PersistentVolumeClaim{}
+	require.NoError(t, c.Get(ctx, types.NamespacedName{Name: "data-db-0", Namespace: "default"}, data))
+	require.NotNil(t, data.Spec.DataSource)
+	assert.Equal(t, "VolumeSnapshot", data.Spec.DataSource.Kind)
+	assert.Equal(t, "db-snap-0", data.Spec.DataSource.Name)
+	assert.Equal(t, appsv1.VolumeSnapshotAPIGroup, *data.Spec.DataSource.APIGroup)
+
+	logs := &corev1.PersistentVolumeClaim{}
+	require.NoError(t, c.Get(ctx, types.NamespacedName{Name: "logs-db-1", Namespace: "default"}, logs))
+	require.NotNil(t, logs.Spec.DataSource)
+	assert.Equal(t, "PersistentVolumeClaim", logs.Spec.DataSource.Kind)
+	assert.Equal(t, "logs-db-old-1", logs.Spec.DataSource.Name)
+
+	// 模板本身不被修改
+	assert.Nil(t, ms.Spec.VolumeClaimTemplates[0].Spec.DataSource)
+
+	// 扩容超过源副本集的副本数时不阻塞，新序号创建空卷
+	ms.Spec.Replicas = pointer.Int32(3)
+	missing, err = r.missingDataSources(ctx, ms)
+	require.NoError(t, err)
+	assert.Empty(t, missing)
+	require.NoError(t, r.reconcilePVCs(ctx, ms))
+	for _, name := range []string{"data-db-2", "logs-db-2"} {
+		claim := &corev1.PersistentVolumeClaim{}
+		require.NoError(t, c.Get(ctx, types.NamespacedName{Name: name, Namespace: "default"}, claim))
+		assert.Nil(t, claim.Spec.DataSource, name)
+		assert.Contains(t, <-recorder.Events, "DataSourceNotFound")
+	}
+}
@@ -0,0 +1,183 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	appsv1 "github.com/bryant-rh/my-statefulset/api/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// myStatefulsetGVK 是未设置 apiVersion 和 kind 的依赖
+var myStatefulsetGVK = appsv1.GroupVersion.WithKind("MyStatefulset")
+
+// unmetDependencies 返回 spec.dependsOn 中尚不可用的依赖，格式为 <Kind>/<namespace>/<name>。
+// 不存在的对象以及未安装的类型都视为不可用
+func (r *MyStatefulsetReconciler) unmetDependencies(ctx context.Context, mystatefulset *appsv1.MyStatefulset) ([]string, error) {
+	var unmet []string
+	for i := range mystatefulset.Spec.DependsOn {
+		dep := &mystatefulset.Spec.DependsOn[i]
+		gvk := dep.GroupVersionKind()
+		key := types.NamespacedName{Name: dep.Name, Namespace: dep.GetNamespace(mystatefulset.Namespace)}
+
+		var available bool
+		var err error
+		if dep.IsMyStatefulset() {
+			available, err = r.myStatefulsetAvailable(ctx, key, dep.GetConditionType())
+		} else {
+			available, err = r.objectAvailable(ctx, gvk, key, dep.GetConditionType())
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !available {
+			unmet = append(unmet, fmt.Sprintf("%s/%s/%s", gvk.Kind, key.Namespace, key.Name))
+		}
+	}
+	return unmet, nil
+}
+
+// myStatefulsetAvailable 判断 MyStatefulset 的条件是否为 True，且是针对当前 generation 的
+func (r *MyStatefulsetReconciler) myStatefulsetAvailable(ctx context.Context, key types.NamespacedName, conditionType string) (bool, error) {
+	dependency := &appsv1.MyStatefulset{}
+	if err := r.Get(ctx, key, dependency); err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	condition := meta.FindStatusCondition(dependency.Status.Conditions, conditionType)
+	return condition != nil && condition.Status == metav1.ConditionTrue &&
+		condition.ObservedGeneration == dependency.Generation, nil
+}
+
+// objectAvailable 判断任意对象 status.conditions 中的条件是否为 True，
+// 条件记录了 observedGeneration 时还要求与对象当前的 generation 一致
+func (r *MyStatefulsetReconciler) objectAvailable(ctx context.Context, gvk schema.GroupVersionKind, key types.NamespacedName, conditionType string) (bool, error) {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	err := r.Get(ctx, key, obj)
+	switch {
+	case meta.IsNoMatchError(err):
+		return false, nil
+	case errors.IsNotFound(err):
+		r.watchDependencyKind(ctx, gvk)
+		return false, nil
+	case err != nil:
+		return false, err
+	}
+	r.watchDependencyKind(ctx, gvk)
+
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok || condition["type"] != conditionType {
+			continue
+		}
+		if generation, ok, _ := unstructured.NestedInt64(condition, "observedGeneration"); ok && generation != obj.GetGeneration() {
+			return false, nil
+		}
+		return condition["status"] == string(metav1.ConditionTrue), nil
+	}
+	return false, nil
+}
+
+// watchDependencyKind 第一次遇到某种依赖类型时为其添加 watch，使依赖可用后立即调谐。
+// 没有权限或添加失败时依赖仍会在定期调谐时被检查，下一次遇到该类型时重试
+func (r *MyStatefulsetReconciler) watchDependencyKind(ctx context.Context, gvk schema.GroupVersionKind) {
+	if r.controller == nil {
+		return
+	}
+	if _, loaded := r.dependencyWatches.LoadOrStore(gvk, struct{}{}); loaded {
+		return
+	}
+	// informer 在所有命名空间 list 和 watch 该类型，没有权限时会一直重试而 Watch 不返回错误，先试一次 list
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+	if err := r.List(ctx, list, client.Limit(1)); err != nil {
+		log.FromContext(ctx).Info("Cannot list dependency kind, checking it periodically", "gvk", gvk, "reason", err.Error())
+		r.dependencyWatches.Delete(gvk)
+		return
+	}
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	if err := r.controller.Watch(&source.Kind{Type: obj},
+		handler.EnqueueRequestsFromMapFunc(r.dependentsOf(gvk))); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to watch dependency kind", "gvk", gvk)
+		r.dependencyWatches.Delete(gvk)
+	}
+}
+
+// dependentsOf 返回一个 MapFunc，将 gvk 类型对象的变化映射为依赖它的 MyStatefulset
+func (r *MyStatefulsetReconciler) dependentsOf(gvk schema.GroupVersionKind) handler.MapFunc {
+	return func(obj client.Object) []reconcile.Request {
+		list := &appsv1.MyStatefulsetList{}
+		if err := r.List(context.Background(), list); err != nil {
+			return nil
+		}
+
+		var requests []reconcile.Request
+		for _, ms := range list.Items {
+			for i := range ms.Spec.DependsOn {
+				dep := &ms.Spec.DependsOn[i]
+				if dep.GroupVersionKind().GroupKind() == gvk.GroupKind() &&
+					dep.GetNamespace(ms.Namespace) == obj.GetNamespace() && dep.Name == obj.GetName() {
+					requests = append(requests, reconcile.Request{
+						NamespacedName: types.NamespacedName{Name: ms.Name, Namespace: ms.Namespace},
+					})
+					break
+				}
+			}
+		}
+		return requests
+	}
+}
+
+// setWaitingForDependencies 根据尚不可用的依赖设置 WaitingForDependencies 条件，
+// 未设置 spec.dependsOn 时删除该条件
+func setWaitingForDependencies(mystatefulset *appsv1.MyStatefulset, unmet []string) {
+	if len(mystatefulset.Spec.DependsOn) == 0 {
+		meta.RemoveStatusCondition(&mystatefulset.Status.Conditions, appsv1.ConditionWaitingForDependencies)
+		return
+	}
+	condition := metav1.Condition{
+		Type:               appsv1.ConditionWaitingForDependencies,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: mystatefulset.Generation,
+		Reason:             "DependenciesAvailable",
+		Message:            "All dependencies are available",
+	}
+	if len(unmet) > 0 {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "DependenciesUnavailable"
+		condition.Message = fmt.Sprintf("Waiting for dependencies: %s", strings.Join(unmet, ", "))
+	}
+	meta.SetStatusCondition(&mystatefulset.Status.Conditions, condition)
+}
+
+// setAvailable 根据可用副本数设置 Available 条件
+func setAvailable(status *appsv1.MyStatefulsetStatus, replicas int32, generation int64) {
+	condition := metav1.Condition{
+		Type:               appsv1.ConditionAvailable,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: generation,
+		Reason:             "MinimumReplicasAvailable",
+		Message:            fmt.Sprintf("%d of %d replicas are available", status.AvailableReplicas, replicas),
+	}
+	if status.AvailableReplicas < replicas {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "ReplicasUnavailable"
+	}
+	meta.SetStatusCondition(&status.Conditions, condition)
+}
@@ -0,0 +1,281 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	appsv1 "github.com/bryant-rh/my-statefulset/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+var deploymentGVK = schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}
+
+func newDependentMyStatefulset(deps ...appsv1.Dependency) *appsv1.MyStatefulset {
+	return &appsv1.MyStatefulset{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-statefulset", Namespace: "default", UID: "test-uid"},
+		Spec: appsv1.MyStatefulsetSpec{
+			Replicas:    pointer.Int32(2),
+			ServiceName: "test-service",
+			Selector:    &metav1.LabelSelector{MatchLabels: map[string]string{"app": "test"}},
+			Template: appsv1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "test"}},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "test-container", Image: "nginx:latest"}},
+				},
+			},
+			DependsOn: deps,
+		},
+	}
+}
+
+func newDeployment(name string, conditions ...interface{}) *unstructured.Unstructured {
+	deployment := &unstructured.Unstructured{Object: map[string]interface{}{
+		"status": map[string]interface{}{"conditions": conditions},
+	}}
+	deployment.SetGroupVersionKind(deploymentGVK)
+	deployment.SetName(name)
+	deployment.SetNamespace("default")
+	return deployment
+}
+
+func TestMyStatefulsetReconciler_dependsOn(t *testing.T) {
+	s := runtime.NewScheme()
+	_ = appsv1.AddToScheme(s)
+	_ = corev1.AddToScheme(s)
+	ctx := context.Background()
+	key := types.NamespacedName{Name: "test-statefulset", Namespace: "default"}
+
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-service", Namespace: "default"},
+		Spec:       corev1.ServiceSpec{ClusterIP: "None"},
+	}
+	c := fake.NewClientBuilder().WithScheme(s).
+		WithObjects(newDependentMyStatefulset(appsv1.Dependency{Name: "db"}), service).Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &MyStatefulsetReconciler{Client: c, Scheme: s, Recorder: recorder,
+		PodInformer: &fakePodInformer{}, PVCInformer: &fakePVCInformer{}}
+
+	// 依赖不存在时不创建 Pod
+	result, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+	assert.NotZero(t, result.RequeueAfter)
+	assert.Contains(t, <-recorder.Events, "WaitingForDependencies ")
+
+	pods := &corev1.PodList{}
+	require.NoError(t, c.List(ctx, pods, client.InNamespace("default")))
+	assert.Empty(t, pods.Items)
+
+	got := &appsv1.MyStatefulset{}
+	require.NoError(t, c.Get(ctx, key, got))
+	assert.True(t, meta.IsStatusConditionTrue(got.Status.Conditions, appsv1.ConditionWaitingForDependencies))
+	assert.Contains(t, meta.FindStatusCondition(got.Status.Conditions, appsv1.ConditionWaitingForDependencies).Message,
+		"MyStatefulset/default/db")
+
+	// 依赖可用后创建 Pod
+	db := &appsv1.MyStatefulset{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"}}
+	require.NoError(t, c.Create(ctx, db))
+	meta.SetStatusCondition(&db.Status.Conditions, metav1.Condition{
+		Type: appsv1.ConditionAvailable, Status: metav1.ConditionTrue, Reason: "MinimumReplicasAvailable",
+		ObservedGeneration: db.Generation,
+	})
+	require.NoError(t, c.Status().Update(ctx, db))
+
+	_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+	require.NoError(t, c.List(ctx, pods, client.InNamespace("default")))
+	assert.Len(t, pods.Items, 2)
+
+	require.NoError(t, c.Get(ctx, key, got))
+	assert.True(t, meta.IsStatusConditionFalse(got.Status.Conditions, appsv1.ConditionWaitingForDependencies))
+	assert.NotNil(t, meta.FindStatusCondition(got.Status.Conditions, appsv1.ConditionAvailable))
+}
+
+func TestMyStatefulsetReconciler_scaleDownWhileWaitingForDependencies(t *testing.T) {
+	s := runtime.NewScheme()
+	_ = appsv1.AddToScheme(s)
+	_ = corev1.AddToScheme(s)
+	ctx := context.Background()
+	key := types.NamespacedName{Name: "test-statefulset", Namespace: "default"}
+
+	ms := newDependentMyStatefulset(appsv1.Dependency{Name: "db"})
+	ms.Spec.Replicas = pointer.Int32(1)
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-service", Namespace: "default"},
+		Spec:       corev1.ServiceSpec{ClusterIP: "None"},
+	}
+	objects := []client.Object{ms, service}
+	for i := 0; i < 3; i++ {
+		objects = append(objects, createPodWithOwner(fmt.Sprintf("test-statefulset-%d", i), "test-uid"))
+	}
+	c := fake.NewClientBuilder().WithScheme(s).WithObjects(objects...).Build()
+	r := &MyStatefulsetReconciler{Client: c, Scheme: s, Recorder: record.NewFakeRecorder(10),
+		PodInformer: &fakePodInformer{}, PVCInformer: &fakePVCInformer{}}
+
+	// 依赖不可用时不创建新的 Pod，但缩容照常进行
+	_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+
+	pods := &corev1.PodList{}
+	require.NoError(t, c.List(ctx, pods, client.InNamespace("default")))
+	require.Len(t, pods.Items, 1)
+	assert.Equal(t, "test-statefulset-0", pods.Items[0].Name)
+
+	got := &appsv1.MyStatefulset{}
+	require.NoError(t, c.Get(ctx, key, got))
+	assert.True(t, meta.IsStatusConditionTrue(got.Status.Conditions, appsv1.ConditionWaitingForDependencies))
+}
+
+func TestMyStatefulsetReconciler_unmetDependencies(t *testing.T) {
+	s := runtime.NewScheme()
+	_ = appsv1.AddToScheme(s)
+	_ = corev1.AddToScheme(s)
+	s.AddKnownTypeWithName(deploymentGVK, &unstructured.Unstructured{})
+	s.AddKnownTypeWithName(deploymentGVK.GroupVersion().WithKind("DeploymentList"), &unstructured.UnstructuredList{})
+
+	staleDB := &appsv1.MyStatefulset{
+		ObjectMeta: metav1.ObjectMeta{Name: "stale-db", Namespace: "default", Generation: 2},
+		Status: appsv1.MyStatefulsetStatus{Conditions: []metav1.Condition{
+			{Type: appsv1.ConditionAvailable, Status: metav1.ConditionTrue, ObservedGeneration: 1},
+		}},
+	}
+	ready := newDeployment("api", map[string]interface{}{"type": "Available", "status": "True"})
+	progressing := newDeployment("worker",
+		map[string]interface{}{"type": "Available", "status": "True"},
+		map[string]interface{}{"type": "Progressing", "status": "False"})
+
+	tests := []struct {
+		name string
+		deps []appsv1.Dependency
+		want []string
+	}{
+		{name: "no dependencies"},
+		{
+			name: "available deployment",
+			deps: []appsv1.Dependency{{APIVersion: "apps/v1", Kind: "Deployment", Name: "api"}},
+		},
+		{
+			name: "custom condition type",
+			deps: []appsv1.Dependency{{APIVersion: "apps/v1", Kind: "Deployment", Name: "worker", ConditionType: "Progressing"}},
+			want: []string{"Deployment/default/worker"},
+		},
+		{
+			name: "missing object",
+			deps: []appsv1.Dependency{{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "other", Name: "api"}},
+			want: []string{"Deployment/other/api"},
+		},
+		{
+			name: "condition for an older generation",
+			deps: []appsv1.Dependency{{Name: "stale-db"}},
+			want: []string{"MyStatefulset/default/stale-db"},
+		},
+	}
+
+	c := fake.NewClientBuilder().WithScheme(s).WithObjects(staleDB, ready, progressing).Build()
+	r := &MyStatefulsetReconciler{Client: c, Scheme: s}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			unmet, err := r.unmetDependencies(context.Background(), newDependentMyStatefulset(tt.deps...))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, unmet)
+		})
+	}
+}
+
+func TestMyStatefulsetReconciler_dependentsOf(t *testing.T) {
+	s := runtime.NewScheme()
+	_ = appsv1.AddToScheme(s)
+
+	app := newDependentMyStatefulset(appsv1.Dependency{Name: "db"})
+	cache := newDependentMyStatefulset(appsv1.Dependency{APIVersion: "apps/v1", Kind: "Deployment", Name: "db"})
+	cache.Name = "cache"
+	other := newDependentMyStatefulset(appsv1.Dependency{Namespace: "other", Name: "db"})
+	other.Name = "other"
+
+	c := fake.NewClientBuilder().WithScheme(s).WithObjects(app, cache, other).Build()
+	r := &MyStatefulsetReconciler{Client: c, Scheme: s}
+
+	db := &appsv1.MyStatefulset{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"}}
+	requests := r.dependentsOf(myStatefulsetGVK)(db)
+	require.Len(t, requests, 1)
+	assert.Equal(t, "test-statefulset", requests[0].Name)
+
+	requests = r.dependentsOf(deploymentGVK)(newDeployment("db"))
+	require.Len(t, requests, 1)
+	assert.Equal(t, "cache", requests[0].Name)
+}
+
+// fakeController 记录 Watch 的调用次数
+type fakeController struct {
+	controller.Controller
+	watches int
+}
+
+func (c *fakeController) Watch(_ source.Source, _ handler.EventHandler, _ ...predicate.Predicate) error {
+	c.watches++
+	return nil
+}
+
+// forbiddenListClient 拒绝 list 非结构化对象，模拟控制器没有该类型的 list 权限
+type forbiddenListClient struct {
+	client.Client
+}
+
+func (c forbiddenListClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	if u, ok := list.(*unstructured.UnstructuredList); ok {
+		gvk := u.GroupVersionKind()
+		return apierrors.NewForbidden(schema.GroupResource{Group: gvk.Group, Resource: "deployments"}, "", errors.New("no RBAC policy matched"))
+	}
+	return c.Client.List(ctx, list, opts...)
+}
+
+func TestMyStatefulsetReconciler_watchDependencyKind(t *testing.T) {
+	s := runtime.NewScheme()
+	_ = appsv1.AddToScheme(s)
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		forbidList  bool
+		wantWatches int
+	}{
+		{name: "watch when the kind can be listed", wantWatches: 1},
+		{name: "fall back to periodic checks without list permission", forbidList: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var c client.Client = fake.NewClientBuilder().WithScheme(s).WithObjects(newDeployment("db")).Build()
+			if tt.forbidList {
+				c = forbiddenListClient{c}
+			}
+			watcher := &fakeController{}
+			r := &MyStatefulsetReconciler{Client: c, Scheme: s, controller: watcher}
+
+			r.watchDependencyKind(ctx, deploymentGVK)
+			r.watchDependencyKind(ctx, deploymentGVK)
+			assert.Equal(t, tt.wantWatches, watcher.watches)
+			_, watched := r.dependencyWatches.Load(deploymentGVK)
+			assert.Equal(t, tt.wantWatches > 0, watched)
+		})
+	}
+}
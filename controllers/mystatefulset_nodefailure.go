@@ -0,0 +1,234 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	appsv1 "github.com/bryant-rh/my-statefulset/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// selectedNodeAnnotation 由调度器写到延迟绑定（WaitForFirstConsumer）的 PVC 上，
+// local-path 等本地存储的 PVC 通过它固定到某个节点
+const selectedNodeAnnotation = "volume.kubernetes.io/selected-node"
+
+// lostPod 是所在节点失联的 Pod
+type lostPod struct {
+	pod       *corev1.Pod
+	nodeName  string
+	lostSince time.Time
+}
+
+// recoverFromNodeFailure 按 spec.nodeFailurePolicy 强制删除节点失联超过宽限期的 Pod（以及可选的 PVC），
+// 使副本能在其他节点上重建。返回值为最近一个 Pod 到达宽限期的剩余时间，0 表示无需提前调谐
+func (r *MyStatefulsetReconciler) recoverFromNodeFailure(ctx context.Context, mystatefulset *appsv1.MyStatefulset) (time.Duration, error) {
+	policy := mystatefulset.Spec.NodeFailurePolicy
+	if policy == nil {
+		return 0, nil
+	}
+	log := log.FromContext(ctx)
+
+	selector, err := podSelector(mystatefulset)
+	if err != nil {
+		return 0, err
+	}
+	podList := &corev1.PodList{}
+	if err := r.List(ctx, podList, client.InNamespace(mystatefulset.Namespace),
+		client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return 0, err
+	}
+
+	// 未 Ready 或缺失的副本视为正在恢复，占用并发恢复的名额
+	replicas := int(mystatefulset.GetReplicas())
+	present := make(map[int]bool, replicas)
+	var lost []lostPod
+	var inFlight int32
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if !metav1.IsControlledBy(pod, mystatefulset) {
+			continue
+		}
+		ordinal := getOrdinal(pod.Name)
+		if ordinal >= replicas {
+			// 多余的 Pod 由缩容流程处理
+			continue
+		}
+		present[ordinal] = true
+
+		nodeName, since, isLost, err := r.podNodeLost(ctx, mystatefulset, pod, ordinal)
+		if err != nil {
+			return 0, err
+		}
+		if isLost {
+			lost = append(lost, lostPod{pod: pod, nodeName: nodeName, lostSince: since})
+			continue
+		}
+		if pod.DeletionTimestamp != nil || !isPodReady(pod) {
+			inFlight++
+		}
+	}
+	for ordinal := 0; ordinal < replicas; ordinal++ {
+		if !present[ordinal] {
+			inFlight++
+		}
+	}
+
+	sort.Slice(lost, func(i, j int) bool {
+		return getOrdinal(lost[i].pod.Name) < getOrdinal(lost[j].pod.Name)
+	})
+
+	now := time.Now()
+	grace := policy.GetGracePeriod()
+	budget := policy.GetMaxConcurrentRecoveries() - inFlight
+	var requeueAfter time.Duration
+	for _, lp := range lost {
+		if wait := lp.lostSince.Add(grace).Sub(now); wait > 0 {
+			log.Info("Node of pod is lost, waiting for grace period",
+				"pod", lp.pod.Name, "node", lp.nodeName, "remaining", wait)
+			if requeueAfter == 0 || wait < requeueAfter {
+				requeueAfter = wait
+			}
+			continue
+		}
+		if budget <= 0 {
+			r.Recorder.Event(mystatefulset, corev1.EventTypeWarning, "NodeFailureRecoveryDeferred",
+				fmt.Sprintf("Recovery of Pod %s on lost node %s deferred: %d recoveries already in progress",
+					lp.pod.Name, lp.nodeName, policy.GetMaxConcurrentRecoveries()))
+			continue
+		}
+		if err := r.recoverLostPod(ctx, mystatefulset, lp); err != nil {
+			return 0, err
+		}
+		budget--
+	}
+	return requeueAfter, nil
+}
+
+// podNodeLost 判断 Pod 所在的节点是否失联，并返回开始失联的时间。
+// 未调度的 Pod 使用其 PVC 被固定到的节点
+func (r *MyStatefulsetReconciler) podNodeLost(ctx context.Context, mystatefulset *appsv1.MyStatefulset,
+	pod *corev1.Pod, ordinal int) (string, time.Time, bool, error) {
+	nodeName := pod.Spec.NodeName
+	if nodeName == "" {
+		var err error
+		nodeName, err = r.selectedNode(ctx, mystatefulset, ordinal)
+		if err != nil || nodeName == "" {
+			return "", time.Time{}, false, err
+		}
+	}
+
+	node := &corev1.Node{}
+	if err := r.Get(ctx, types.NamespacedName{Name: nodeName}, node); err != nil {
+		if errors.IsNotFound(err) {
+			// 节点已被删除，从 Pod 卡住的时间开始计算宽限期
+			return nodeName, podStuckSince(pod), true, nil
+		}
+		return "", time.Time{}, false, err
+	}
+	for _, condition := range node.Status.Conditions {
+		if condition.Type != corev1.NodeReady {
+			continue
+		}
+		if condition.Status == corev1.ConditionTrue {
+			return nodeName, time.Time{}, false, nil
+		}
+		return nodeName, condition.LastTransitionTime.Time, true, nil
+	}
+	return nodeName, time.Time{}, false, nil
+}
+
+// selectedNode 返回该序号的 PVC 被固定到的节点
+func (r *MyStatefulsetReconciler) selectedNode(ctx context.Context, mystatefulset *appsv1.MyStatefulset, ordinal int) (string, error) {
+	for _, pvcName := range claimNamesForOrdinal(mystatefulset, ordinal) {
+		pvc := &corev1.PersistentVolumeClaim{}
+		if err := r.Get(ctx, types.NamespacedName{Name: pvcName, Namespace: mystatefulset.Namespace}, pvc); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return "", err
+		}
+		if node := pvc.Annotations[selectedNodeAnnotation]; node != "" {
+			return node, nil
+		}
+	}
+	return "", nil
+}
+
+// recoverLostPod 删除失联 Pod 的 PVC（如果策略要求）并强制删除 Pod，由 reconcilePVCs 和 reconcilePods 重建副本
+func (r *MyStatefulsetReconciler) recoverLostPod(ctx context.Context, mystatefulset *appsv1.MyStatefulset, lp lostPod) error {
+	log := log.FromContext(ctx)
+	ordinal := getOrdinal(lp.pod.Name)
+
+	var deletedPVCs []string
+	if mystatefulset.Spec.NodeFailurePolicy.DeletePVC {
+		for _, pvcName := range claimNamesForOrdinal(mystatefulset, ordinal) {
+			pvc := &corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: pvcName, Namespace: mystatefulset.Namespace},
+			}
+			if err := r.Delete(ctx, pvc); err != nil {
+				if errors.IsNotFound(err) {
+					continue
+				}
+				return fmt.Errorf("failed to delete PVC %s: %w", pvcName, err)
+			}
+			deletedPVCs = append(deletedPVCs, pvcName)
+		}
+	}
+
+	// 节点上的 kubelet 已不可用，必须强制删除，否则 Pod 会一直处于 Terminating
+	if err := r.Delete(ctx, lp.pod, client.GracePeriodSeconds(0)); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete Pod %s: %w", lp.pod.Name, err)
+	}
+
+	log.Info("Recovered pod from lost node", "pod", lp.pod.Name, "node", lp.nodeName, "deletedPVCs", deletedPVCs)
+	message := fmt.Sprintf("Force deleted Pod %s on lost node %s", lp.pod.Name, lp.nodeName)
+	if len(deletedPVCs) > 0 {
+		message += fmt.Sprintf(" and its PVCs %v", deletedPVCs)
+	}
+	r.Recorder.Event(mystatefulset, corev1.EventTypeWarning, "NodeFailureRecovery", message)
+	return nil
+}
+
+// podStuckSince 返回 Pod 开始不可用的时间：创建时间与 Ready 条件最近变化时间中较晚的一个
+func podStuckSince(pod *corev1.Pod) time.Time {
+	since := pod.CreationTimestamp.Time
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady && condition.LastTransitionTime.After(since) {
+			since = condition.LastTransitionTime.Time
+		}
+	}
+	return since
+}
+
+// claimNamesForOrdinal 返回该序号的 Pod 使用的 PVC 名称，顺序与 volumeClaimTemplates 一致
+func claimNamesForOrdinal(mystatefulset *appsv1.MyStatefulset, ordinal int) []string {
+	names := make([]string, 0, len(mystatefulset.Spec.VolumeClaimTemplates))
+	for _, pvcTemplate := range mystatefulset.Spec.VolumeClaimTemplates {
+		names = append(names, fmt.Sprintf("%s-%s-%d", appsv1.ClaimVolumeName(pvcTemplate), mystatefulset.Name, ordinal))
+	}
+	return names
+}
+
+// claimVolumesForOrdinal 返回该序号的 Pod 挂载 PVC 的 volume，volume 名称与 PVC 模板的 volumeMount 对应
+func claimVolumesForOrdinal(mystatefulset *appsv1.MyStatefulset, ordinal int) []corev1.Volume {
+	claimNames := claimNamesForOrdinal(mystatefulset, ordinal)
+	volumes := make([]corev1.Volume, 0, len(claimNames))
+	for i, pvcTemplate := range mystatefulset.Spec.VolumeClaimTemplates {
+		volumes = append(volumes, corev1.Volume{
+			Name: appsv1.ClaimVolumeName(pvcTemplate),
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: claimNames[i],
+				},
+			},
+		})
+	}
+	return volumes
+}
@@ -0,0 +1,183 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	appsv1 "github.com/bryant-rh/my-statefulset/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newNode(name string, ready corev1.ConditionStatus, since time.Time) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeReady, Status: ready, LastTransitionTime: metav1.NewTime(since)},
+			},
+		},
+	}
+}
+
+func TestMyStatefulsetReconciler_recoverFromNodeFailure(t *testing.T) {
+	s := runtime.NewScheme()
+	_ = appsv1.AddToScheme(s)
+	_ = corev1.AddToScheme(s)
+
+	longAgo := time.Now().Add(-time.Hour)
+	justNow := time.Now().Add(-time.Minute)
+
+	newMs := func(replicas int32, policy *appsv1.NodeFailurePolicy) *appsv1.MyStatefulset {
+		return &appsv1.MyStatefulset{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-statefulset", Namespace: "default", UID: "test-uid"},
+			Spec: appsv1.MyStatefulsetSpec{
+				Replicas: pointer.Int32(replicas),
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "test"}},
+				VolumeClaimTemplates: []corev1.PersistentVolumeClaim{
+					{ObjectMeta: metav1.ObjectMeta{Name: "data"}},
+				},
+				NodeFailurePolicy: policy,
+			},
+		}
+	}
+	newPVC := func(name, selectedNode string) *corev1.PersistentVolumeClaim {
+		pvc := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}}
+		if selectedNode != "" {
+			pvc.Annotations = map[string]string{selectedNodeAnnotation: selectedNode}
+		}
+		return pvc
+	}
+	unscheduledPod := func(name string) *corev1.Pod {
+		pod := createPodWithOwner(name, "test-uid")
+		pod.Status = corev1.PodStatus{Phase: corev1.PodPending}
+		return pod
+	}
+
+	tests := []struct {
+		name            string
+		ms              *appsv1.MyStatefulset
+		objects         []client.Object
+		wantDeletedPods []string
+		wantDeletedPVCs []string
+		wantRequeue     bool
+		wantEvent       string
+	}{
+		{
+			name: "policy unset",
+			ms:   newMs(1, nil),
+			objects: []client.Object{
+				createScheduledPod("test-statefulset-0", "node-a"),
+				newNode("node-a", corev1.ConditionUnknown, longAgo),
+			},
+		},
+		{
+			name: "node NotReady past grace period",
+			ms:   newMs(2, &appsv1.NodeFailurePolicy{DeletePVC: true}),
+			objects: []client.Object{
+				createScheduledPod("test-statefulset-0", "node-a"),
+				createScheduledPod("test-statefulset-1", "node-b"),
+				newNode("node-a", corev1.ConditionTrue, longAgo),
+				newNode("node-b", corev1.ConditionUnknown, longAgo),
+				newPVC("data-test-statefulset-0", ""),
+				newPVC("data-test-statefulset-1", ""),
+			},
+			wantDeletedPods: []string{"test-statefulset-1"},
+			wantDeletedPVCs: []string{"data-test-statefulset-1"},
+			wantEvent:       "NodeFailureRecovery",
+		},
+		{
+			name: "node NotReady within grace period",
+			ms:   newMs(1, &appsv1.NodeFailurePolicy{GracePeriodSeconds: pointer.Int32(300)}),
+			objects: []client.Object{
+				createScheduledPod("test-statefulset-0", "node-a"),
+				newNode("node-a", corev1.ConditionFalse, justNow),
+			},
+			wantRequeue: true,
+		},
+		{
+			name: "pending pod pinned to a deleted node keeps its PVC",
+			ms:   newMs(1, &appsv1.NodeFailurePolicy{}),
+			objects: []client.Object{
+				unscheduledPod("test-statefulset-0"),
+				newPVC("data-test-statefulset-0", "node-gone"),
+			},
+			wantDeletedPods: []string{"test-statefulset-0"},
+			wantEvent:       "NodeFailureRecovery",
+		},
+		{
+			name: "max concurrent recoveries",
+			ms:   newMs(2, &appsv1.NodeFailurePolicy{}),
+			objects: []client.Object{
+				createScheduledPod("test-statefulset-0", "node-a"),
+				createScheduledPod("test-statefulset-1", "node-a"),
+				newNode("node-a", corev1.ConditionUnknown, longAgo),
+			},
+			wantDeletedPods: []string{"test-statefulset-0"},
+			wantEvent:       "NodeFailureRecovery",
+		},
+		{
+			name: "replica still recovering uses the budget",
+			ms:   newMs(2, &appsv1.NodeFailurePolicy{}),
+			objects: []client.Object{
+				createScheduledPod("test-statefulset-0", "node-a"),
+				newNode("node-a", corev1.ConditionUnknown, longAgo),
+			},
+			wantEvent: "NodeFailureRecoveryDeferred",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithScheme(s).WithObjects(tt.objects...).Build()
+			recorder := record.NewFakeRecorder(10)
+			r := &MyStatefulsetReconciler{Client: c, Scheme: s, Recorder: recorder}
+
+			requeueAfter, err := r.recoverFromNodeFailure(context.Background(), tt.ms)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantRequeue, requeueAfter > 0)
+
+			deleted := func(obj client.Object) bool {
+				err := c.Get(context.Background(), client.ObjectKeyFromObject(obj), obj)
+				return errors.IsNotFound(err)
+			}
+			for _, obj := range tt.objects {
+				var want bool
+				switch o := obj.(type) {
+				case *corev1.Pod:
+					want = contains(tt.wantDeletedPods, o.Name)
+				case *corev1.PersistentVolumeClaim:
+					want = contains(tt.wantDeletedPVCs, o.Name)
+				default:
+					continue
+				}
+				assert.Equal(t, want, deleted(obj), "deleted %s", obj.GetName())
+			}
+
+			if tt.wantEvent == "" {
+				assert.Empty(t, recorder.Events)
+				return
+			}
+			require.NotEmpty(t, recorder.Events)
+			assert.Contains(t, <-recorder.Events, tt.wantEvent+" ")
+		})
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,179 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	appsv1 "github.com/bryant-rh/my-statefulset/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// podReplacementBaseBackoff 是第一次替换失败 Pod 前的等待时间，之后每次失败翻倍
+	podReplacementBaseBackoff = 10 * time.Second
+	// podReplacementMaxBackoff 是替换等待时间的上限，副本稳定 Ready 超过该时间后清除失败记录
+	podReplacementMaxBackoff = 5 * time.Minute
+
+	// podPendingTimeoutReason 是 Pod 处于 Pending 超过期限时记录的失败原因
+	podPendingTimeoutReason = "PendingTimeout"
+)
+
+// replaceFailedPods 删除处于终止阶段（Failed、Succeeded）、Unknown 或 Pending 超时的 Pod，
+// 由 reconcilePods 按序号重建。同一序号连续失败时按指数退避推迟替换，失败次数记录在 status.podFailures 中。
+// 返回值为最近一个 Pod 退避结束的剩余时间，0 表示无需提前调谐
+func (r *MyStatefulsetReconciler) replaceFailedPods(ctx context.Context, mystatefulset *appsv1.MyStatefulset) (time.Duration, error) {
+	log := log.FromContext(ctx)
+
+	selector, err := podSelector(mystatefulset)
+	if err != nil {
+		return 0, err
+	}
+	podList := &corev1.PodList{}
+	if err := r.List(ctx, podList, client.InNamespace(mystatefulset.Namespace),
+		client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return 0, err
+	}
+
+	replicas := mystatefulset.GetReplicas()
+	records := make(map[int32]appsv1.PodFailure, len(mystatefulset.Status.PodFailures))
+	changed := false
+	for _, record := range mystatefulset.Status.PodFailures {
+		// 缩容后不再存在的序号不需要保留失败记录
+		if record.Ordinal >= replicas {
+			changed = true
+			continue
+		}
+		records[record.Ordinal] = record
+	}
+
+	now := time.Now()
+	deadline := mystatefulset.GetPodPendingDeadline()
+	var requeueAfter time.Duration
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		ordinal := int32(getOrdinal(pod.Name))
+		if !metav1.IsControlledBy(pod, mystatefulset) || ordinal >= replicas {
+			continue
+		}
+
+		record, hasRecord := records[ordinal]
+		reason := podFailureReason(pod, deadline, now)
+		if reason == "" {
+			if hasRecord && podReadyFor(pod, now) >= podReplacementMaxBackoff {
+				delete(records, ordinal)
+				changed = true
+			}
+			continue
+		}
+
+		// 同一个 Pod 的失败只计一次
+		if !hasRecord || record.PodUID != pod.UID {
+			record = appsv1.PodFailure{
+				Ordinal:         ordinal,
+				Count:           record.Count + 1,
+				Reason:          reason,
+				LastFailureTime: metav1.NewTime(now),
+				PodUID:          pod.UID,
+			}
+			records[ordinal] = record
+			changed = true
+			r.Recorder.Event(mystatefulset, corev1.EventTypeWarning, "PodFailed",
+				fmt.Sprintf("Pod %s failed (%s), replacing it in %s (failure %d)",
+					pod.Name, reason, podReplacementBackoff(record.Count), record.Count))
+		}
+
+		if wait := record.LastFailureTime.Add(podReplacementBackoff(record.Count)).Sub(now); wait > 0 {
+			if requeueAfter == 0 || wait < requeueAfter {
+				requeueAfter = wait
+			}
+			continue
+		}
+
+		// 终止阶段的 Pod 没有运行中的容器，Unknown 的 Pod 所在节点已失联，都可以强制删除；
+		// Pending 的 Pod 可能已经在运行 init 容器，按正常流程删除
+		opts := []client.DeleteOption{}
+		if pod.Status.Phase != corev1.PodPending {
+			opts = append(opts, client.GracePeriodSeconds(0))
+		}
+		if err := r.Delete(ctx, pod, opts...); err != nil && !errors.IsNotFound(err) {
+			return 0, fmt.Errorf("failed to delete failed Pod %s: %w", pod.Name, err)
+		}
+		log.Info("Replacing failed pod", "pod", pod.Name, "reason", reason, "failures", record.Count)
+		r.Recorder.Event(mystatefulset, corev1.EventTypeNormal, "ReplacingFailedPod",
+			fmt.Sprintf("Deleted Pod %s (%s), it will be recreated", pod.Name, reason))
+	}
+
+	if !changed {
+		return requeueAfter, nil
+	}
+
+	// 立即持久化失败记录，保证退避在重试之间不会被重置
+	podFailures := make([]appsv1.PodFailure, 0, len(records))
+	for _, record := range records {
+		podFailures = append(podFailures, record)
+	}
+	sort.Slice(podFailures, func(i, j int) bool {
+		return podFailures[i].Ordinal < podFailures[j].Ordinal
+	})
+	if len(podFailures) == 0 {
+		podFailures = nil
+	}
+	patch := client.MergeFrom(mystatefulset.DeepCopy())
+	mystatefulset.Status.PodFailures = podFailures
+	if err := r.Status().Patch(ctx, mystatefulset, patch); err != nil {
+		return 0, fmt.Errorf("failed to record pod failures: %w", err)
+	}
+	return requeueAfter, nil
+}
+
+// podFailureReason 返回 Pod 需要被替换的原因，空字符串表示不需要替换
+func podFailureReason(pod *corev1.Pod, pendingDeadline time.Duration, now time.Time) string {
+	if pod.DeletionTimestamp != nil {
+		return ""
+	}
+	switch pod.Status.Phase {
+	case corev1.PodFailed, corev1.PodSucceeded, corev1.PodUnknown:
+		// 被驱逐的 Pod 的 reason 为 Evicted
+		if pod.Status.Reason != "" {
+			return pod.Status.Reason
+		}
+		return string(pod.Status.Phase)
+	case corev1.PodPending:
+		if pendingDeadline > 0 && !pod.CreationTimestamp.IsZero() &&
+			now.Sub(pod.CreationTimestamp.Time) > pendingDeadline {
+			return podPendingTimeoutReason
+		}
+	}
+	return ""
+}
+
+// podReplacementBackoff 返回第 count 次失败后替换 Pod 前的等待时间
+func podReplacementBackoff(count int32) time.Duration {
+	backoff := podReplacementBaseBackoff
+	for i := int32(1); i < count; i++ {
+		backoff *= 2
+		if backoff >= podReplacementMaxBackoff {
+			return podReplacementMaxBackoff
+		}
+	}
+	return backoff
+}
+
+// podReadyFor 返回 Pod 持续 Ready 的时间，未 Ready 时返回 0
+func podReadyFor(pod *corev1.Pod, now time.Time) time.Duration {
+	if !isPodReady(pod) {
+		return 0
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return now.Sub(condition.LastTransitionTime.Time)
+		}
+	}
+	return 0
+}
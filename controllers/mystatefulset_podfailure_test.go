@@ -0,0 +1,208 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	appsv1 "github.com/bryant-rh/my-statefulset/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestPodFailureReason(t *testing.T) {
+	now := time.Now()
+	newPod := func(phase corev1.PodPhase, reason string, age time.Duration) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(now.Add(-age))},
+			Status:     corev1.PodStatus{Phase: phase, Reason: reason},
+		}
+	}
+	terminating := newPod(corev1.PodFailed, "", time.Hour)
+	terminating.DeletionTimestamp = &metav1.Time{Time: now}
+
+	tests := []struct {
+		name     string
+		pod      *corev1.Pod
+		deadline time.Duration
+		want     string
+	}{
+		{name: "running", pod: newPod(corev1.PodRunning, "", time.Hour), deadline: time.Minute},
+		{name: "evicted", pod: newPod(corev1.PodFailed, "Evicted", time.Hour), want: "Evicted"},
+		{name: "failed", pod: newPod(corev1.PodFailed, "", time.Hour), want: "Failed"},
+		{name: "succeeded", pod: newPod(corev1.PodSucceeded, "", time.Hour), want: "Succeeded"},
+		{name: "unknown", pod: newPod(corev1.PodUnknown, "", time.Hour), want: "Unknown"},
+		{name: "pending within deadline", pod: newPod(corev1.PodPending, "", time.Minute), deadline: time.Hour},
+		{name: "pending past deadline", pod: newPod(corev1.PodPending, "", time.Hour), deadline: time.Minute, want: podPendingTimeoutReason},
+		{name: "pending deadline disabled", pod: newPod(corev1.PodPending, "", time.Hour)},
+		{name: "already terminating", pod: terminating},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, podFailureReason(tt.pod, tt.deadline, now))
+		})
+	}
+}
+
+func TestPodReplacementBackoff(t *testing.T) {
+	assert.Equal(t, 10*time.Second, podReplacementBackoff(1))
+	assert.Equal(t, 20*time.Second, podReplacementBackoff(2))
+	assert.Equal(t, 160*time.Second, podReplacementBackoff(5))
+	assert.Equal(t, podReplacementMaxBackoff, podReplacementBackoff(6))
+	assert.Equal(t, podReplacementMaxBackoff, podReplacementBackoff(100))
+}
+
+func TestMyStatefulsetReconciler_replaceFailedPods(t *testing.T) {
+	s := runtime.NewScheme()
+	_ = appsv1.AddToScheme(s)
+	_ = corev1.AddToScheme(s)
+
+	now := time.Now()
+	evictedPod := func(name string, uid types.UID) *corev1.Pod {
+		pod := createPodWithOwner(name, "test-uid")
+		pod.UID = uid
+		pod.Status = corev1.PodStatus{Phase: corev1.PodFailed, Reason: "Evicted"}
+		return pod
+	}
+	readyPod := func(name string, readySince time.Time) *corev1.Pod {
+		pod := createPodWithOwner(name, "test-uid")
+		pod.Status.Conditions[0].LastTransitionTime = metav1.NewTime(readySince)
+		return pod
+	}
+	pendingPod := func(name string, created time.Time) *corev1.Pod {
+		pod := createPodWithOwner(name, "test-uid")
+		pod.CreationTimestamp = metav1.NewTime(created)
+		pod.Status = corev1.PodStatus{Phase: corev1.PodPending}
+		return pod
+	}
+	failure := func(ordinal, count int32, uid types.UID, at time.Time) appsv1.PodFailure {
+		return appsv1.PodFailure{
+			Ordinal:         ordinal,
+			Count:           count,
+			Reason:          "Evicted",
+			LastFailureTime: metav1.NewTime(at),
+			PodUID:          uid,
+		}
+	}
+
+	tests := []struct {
+		name          string
+		replicas      int32
+		noDeadline    bool
+		podFailures   []appsv1.PodFailure
+		pod           *corev1.Pod
+		wantDeleted   bool
+		wantRequeue   bool
+		wantFailures  []appsv1.PodFailure
+		wantEventType string
+	}{
+		{
+			name:          "first failure waits for the backoff",
+			replicas:      1,
+			pod:           evictedPod("test-statefulset-0", "uid-1"),
+			wantRequeue:   true,
+			wantFailures:  []appsv1.PodFailure{{Ordinal: 0, Count: 1, Reason: "Evicted", PodUID: "uid-1"}},
+			wantEventType: "PodFailed",
+		},
+		{
+			name:          "backoff elapsed",
+			replicas:      1,
+			podFailures:   []appsv1.PodFailure{failure(0, 1, "uid-1", now.Add(-time.Minute))},
+			pod:           evictedPod("test-statefulset-0", "uid-1"),
+			wantDeleted:   true,
+			wantFailures:  []appsv1.PodFailure{{Ordinal: 0, Count: 1, Reason: "Evicted", PodUID: "uid-1"}},
+			wantEventType: "ReplacingFailedPod",
+		},
+		{
+			name:          "replacement fails again",
+			replicas:      1,
+			podFailures:   []appsv1.PodFailure{failure(0, 2, "uid-1", now.Add(-time.Hour))},
+			pod:           evictedPod("test-statefulset-0", "uid-2"),
+			wantRequeue:   true,
+			wantFailures:  []appsv1.PodFailure{{Ordinal: 0, Count: 3, Reason: "Evicted", PodUID: "uid-2"}},
+			wantEventType: "PodFailed",
+		},
+		{
+			name:          "pending past the deadline",
+			replicas:      1,
+			pod:           pendingPod("test-statefulset-0", now.Add(-time.Hour)),
+			wantRequeue:   true,
+			wantFailures:  []appsv1.PodFailure{{Ordinal: 0, Count: 1, Reason: podPendingTimeoutReason}},
+			wantEventType: "PodFailed",
+		},
+		{
+			name:       "pending deadline is opt-in",
+			replicas:   1,
+			noDeadline: true,
+			pod:        pendingPod("test-statefulset-0", now.Add(-time.Hour)),
+		},
+		{
+			name:         "stable replica clears its record",
+			replicas:     2,
+			podFailures:  []appsv1.PodFailure{failure(0, 4, "uid-1", now.Add(-time.Hour)), failure(2, 1, "uid-3", now)},
+			pod:          readyPod("test-statefulset-0", now.Add(-time.Hour)),
+			wantFailures: nil,
+		},
+		{
+			name:         "recently ready replica keeps its record",
+			replicas:     1,
+			podFailures:  []appsv1.PodFailure{failure(0, 4, "uid-1", now.Add(-time.Hour))},
+			pod:          readyPod("test-statefulset-0", now.Add(-time.Minute)),
+			wantFailures: []appsv1.PodFailure{{Ordinal: 0, Count: 4, Reason: "Evicted", PodUID: "uid-1"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms := &appsv1.MyStatefulset{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-statefulset", Namespace: "default", UID: "test-uid"},
+				Spec: appsv1.MyStatefulsetSpec{
+					Replicas:                  pointer.Int32(tt.replicas),
+					Selector:                  &metav1.LabelSelector{MatchLabels: map[string]string{"app": "test"}},
+					PodPendingDeadlineSeconds: pointer.Int32(600),
+				},
+				Status: appsv1.MyStatefulsetStatus{PodFailures: tt.podFailures},
+			}
+			if tt.noDeadline {
+				ms.Spec.PodPendingDeadlineSeconds = nil
+			}
+			c := fake.NewClientBuilder().WithScheme(s).WithObjects(ms, tt.pod).Build()
+			recorder := record.NewFakeRecorder(10)
+			r := &MyStatefulsetReconciler{Client: c, Scheme: s, Recorder: recorder}
+
+			requeueAfter, err := r.replaceFailedPods(context.Background(), ms)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantRequeue, requeueAfter > 0)
+
+			err = c.Get(context.Background(), types.NamespacedName{Name: tt.pod.Name, Namespace: "default"}, &corev1.Pod{})
+			assert.Equal(t, tt.wantDeleted, errors.IsNotFound(err))
+
+			// 失败记录已写入 API Server
+			stored := &appsv1.MyStatefulset{}
+			require.NoError(t, c.Get(context.Background(), types.NamespacedName{Name: ms.Name, Namespace: "default"}, stored))
+			require.Len(t, stored.Status.PodFailures, len(tt.wantFailures))
+			for i, want := range tt.wantFailures {
+				got := stored.Status.PodFailures[i]
+				assert.Equal(t, want.Ordinal, got.Ordinal)
+				assert.Equal(t, want.Count, got.Count)
+				assert.Equal(t, want.Reason, got.Reason)
+				assert.Equal(t, want.PodUID, got.PodUID)
+			}
+
+			if tt.wantEventType == "" {
+				assert.Empty(t, recorder.Events)
+				return
+			}
+			require.NotEmpty(t, recorder.Events)
+			assert.Contains(t, <-recorder.Events, tt.wantEventType+" ")
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package controllers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	appsv1 "github.com/bryant-rh/my-statefulset/api/v1"
+	k8sappsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// maxPodStatuses 是 status.podStatuses 的最大条目数，避免大规模副本集的状态过大
+const maxPodStatuses = 50
+
+// volumeClaimMissing 表示该序号的 PVC 不存在
+const volumeClaimMissing = "Missing"
+
+// podStatusDetails 计算每个序号的 Pod 状态明细，超过 maxPodStatuses 时优先保留未 Ready 或未更新的 Pod
+func podStatusDetails(mystatefulset *appsv1.MyStatefulset, pods []corev1.Pod,
+	pvcs map[string]*corev1.PersistentVolumeClaim) []appsv1.PodStatusDetail {
+	details := make([]appsv1.PodStatusDetail, 0, len(pods))
+	for i := range pods {
+		pod := &pods[i]
+		ordinal := getOrdinal(pod.Name)
+		detail := appsv1.PodStatusDetail{
+			Ordinal:          int32(ordinal),
+			PodName:          pod.Name,
+			Phase:            pod.Status.Phase,
+			Ready:            isPodReady(pod),
+			Available:        isPodAvailable(pod, mystatefulset.Spec.MinReadySeconds),
+			Revision:         pod.Labels[k8sappsv1.ControllerRevisionHashLabelKey],
+			Updated:          isPodUpdated(pod, mystatefulset),
+			NodeName:         pod.Spec.NodeName,
+			VolumeClaimPhase: volumeClaimPhase(mystatefulset, ordinal, pvcs),
+		}
+		for _, condition := range pod.Status.Conditions {
+			if condition.Type == corev1.PodReady {
+				detail.LastTransitionTime = condition.LastTransitionTime
+			}
+		}
+		details = append(details, detail)
+	}
+
+	if len(details) > maxPodStatuses {
+		sort.SliceStable(details, func(i, j int) bool {
+			iHealthy := details[i].Ready && details[i].Updated
+			jHealthy := details[j].Ready && details[j].Updated
+			if iHealthy != jHealthy {
+				return !iHealthy
+			}
+			return details[i].Ordinal < details[j].Ordinal
+		})
+		details = details[:maxPodStatuses]
+	}
+	sort.Slice(details, func(i, j int) bool {
+		return details[i].Ordinal < details[j].Ordinal
+	})
+	return details
+}
+
+// volumeClaimPhase 汇总该序号所有 PVC 的绑定状态，没有 volumeClaimTemplates 时返回空字符串
+func volumeClaimPhase(mystatefulset *appsv1.MyStatefulset, ordinal int, pvcs map[string]*corev1.PersistentVolumeClaim) string {
+	names := claimNamesForOrdinal(mystatefulset, ordinal)
+	if len(names) == 0 {
+		return ""
+	}
+	for _, name := range names {
+		pvc, ok := pvcs[name]
+		if !ok {
+			return volumeClaimMissing
+		}
+		if pvc.Status.Phase != corev1.ClaimBound {
+			if pvc.Status.Phase == "" {
+				return string(corev1.ClaimPending)
+			}
+			return string(pvc.Status.Phase)
+		}
+	}
+	return string(corev1.ClaimBound)
+}
+
+// notReadyOrdinals 返回 spec.replicas 以内缺失或未 Ready 的序号，连续的序号合并为区间，例如 "3,7-9"
+func notReadyOrdinals(replicas int32, pods []corev1.Pod) string {
+	ready := make(map[int32]bool, len(pods))
+	for i := range pods {
+		if isPodReady(&pods[i]) {
+			ready[int32(getOrdinal(pods[i].Name))] = true
+		}
+	}
+
+	var ranges []string
+	for ordinal := int32(0); ordinal < replicas; ordinal++ {
+		if ready[ordinal] {
+			continue
+		}
+		end := ordinal
+		for end+1 < replicas && !ready[end+1] {
+			end++
+		}
+		if end == ordinal {
+			ranges = append(ranges, fmt.Sprint(ordinal))
+		} else {
+			ranges = append(ranges, fmt.Sprintf("%d-%d", ordinal, end))
+		}
+		ordinal = end
+	}
+	return strings.Join(ranges, ",")
+}
@@ -0,0 +1,96 @@
+package controllers
+
+import (
+	"fmt"
+	"testing"
+
+	appsv1 "github.com/bryant-rh/my-statefulset/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	k8sappsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestPodStatusDetails(t *testing.T) {
+	ms := &appsv1.MyStatefulset{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-statefulset", Namespace: "default"},
+		Spec: appsv1.MyStatefulsetSpec{
+			Replicas: pointer.Int32(3),
+			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{
+				{ObjectMeta: metav1.ObjectMeta{Name: "data"}},
+			},
+		},
+	}
+	readySince := metav1.Now()
+
+	pod0 := *createScheduledPod("test-statefulset-0", "node-a")
+	pod0.Labels[k8sappsv1.ControllerRevisionHashLabelKey] = "rev-1"
+	pod0.Status.Conditions[0].LastTransitionTime = readySince
+	pod2 := *createPodWithOwner("test-statefulset-2", "test-uid")
+	pod2.Status = corev1.PodStatus{Phase: corev1.PodPending}
+
+	pvcs := map[string]*corev1.PersistentVolumeClaim{
+		"data-test-statefulset-0": {Status: corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimBound}},
+	}
+
+	details := podStatusDetails(ms, []corev1.Pod{pod2, pod0}, pvcs)
+	require.Len(t, details, 2)
+
+	assert.Equal(t, appsv1.PodStatusDetail{
+		Ordinal:            0,
+		PodName:            "test-statefulset-0",
+		Phase:              corev1.PodRunning,
+		Ready:              true,
+		Available:          true,
+		Revision:           "rev-1",
+		NodeName:           "node-a",
+		VolumeClaimPhase:   "Bound",
+		LastTransitionTime: readySince,
+	}, details[0])
+
+	assert.Equal(t, int32(2), details[1].Ordinal)
+	assert.False(t, details[1].Ready)
+	assert.Equal(t, corev1.PodPending, details[1].Phase)
+	assert.Equal(t, volumeClaimMissing, details[1].VolumeClaimPhase)
+
+	// 缺失序号 1 且序号 2 未 Ready
+	assert.Equal(t, "1-2", notReadyOrdinals(ms.GetReplicas(), []corev1.Pod{pod0, pod2}))
+}
+
+func TestPodStatusDetails_Bounded(t *testing.T) {
+	ms := &appsv1.MyStatefulset{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-statefulset", Namespace: "default"},
+		Spec: appsv1.MyStatefulsetSpec{
+			Replicas: pointer.Int32(80),
+			Template: appsv1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "test"}},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "test-container", Image: "nginx:latest"}},
+				},
+			},
+		},
+	}
+
+	pods := make([]corev1.Pod, 0, 80)
+	for i := 0; i < 80; i++ {
+		pod := createPodWithOwner(fmt.Sprintf("test-statefulset-%d", i), "test-uid")
+		if i == 70 || i == 75 {
+			pod.Status.Phase = corev1.PodPending
+		}
+		pods = append(pods, *pod)
+	}
+
+	details := podStatusDetails(ms, pods, nil)
+	require.Len(t, details, maxPodStatuses)
+
+	// 未 Ready 的 Pod 即使序号靠后也会保留，其余按序号填充
+	assert.Equal(t, int32(0), details[0].Ordinal)
+	assert.Equal(t, int32(47), details[maxPodStatuses-3].Ordinal)
+	assert.Equal(t, int32(70), details[maxPodStatuses-2].Ordinal)
+	assert.Equal(t, int32(75), details[maxPodStatuses-1].Ordinal)
+	assert.Equal(t, "", details[0].VolumeClaimPhase)
+
+	assert.Equal(t, "70,75", notReadyOrdinals(ms.GetReplicas(), pods))
+}
@@ -0,0 +1,163 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "github.com/bryant-rh/my-statefulset/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// admitPods 为带有 ready-to-serve readiness gate 的 Pod 设置同名条件，控制器侧的检查全部通过时为 True。
+// 条件为 False 时 Pod 不会 Ready，Service 不会把流量路由到该 Pod
+func (r *MyStatefulsetReconciler) admitPods(ctx context.Context, mystatefulset *appsv1.MyStatefulset) error {
+	log := log.FromContext(ctx)
+
+	selector, err := podSelector(mystatefulset)
+	if err != nil {
+		return err
+	}
+	podList := &corev1.PodList{}
+	if err := r.List(ctx, podList, client.InNamespace(mystatefulset.Namespace),
+		client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return err
+	}
+	pods := ownedPods(podList.Items, mystatefulset)
+
+	canaryLeaked := canaryBelowPartition(mystatefulset, pods)
+	for i := range pods {
+		pod := &pods[i]
+		// 旧版本控制器创建的 Pod 没有 readiness gate，且 readinessGates 创建后不可修改
+		if !hasReadyToServeGate(pod) || pod.DeletionTimestamp != nil {
+			continue
+		}
+
+		status, reason, message := readyToServe(mystatefulset, pod, canaryLeaked)
+		// 使用 strategic merge patch 只修改该条件，避免覆盖 kubelet 同时写入的其他条件
+		patch := client.StrategicMergeFrom(pod.DeepCopy())
+		if !setPodCondition(pod, corev1.PodCondition{
+			Type:    appsv1.PodConditionReadyToServe,
+			Status:  status,
+			Reason:  reason,
+			Message: message,
+		}) {
+			continue
+		}
+		log.Info("Setting ready-to-serve condition", "pod", pod.Name, "status", status, "reason", reason)
+		if err := r.Status().Patch(ctx, pod, patch); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// readyToServe 依次检查 Pod 是否正在被缩容删除、init 容器是否已完成、是否符合分区。
+// 设置了排空时间时，多余的 Pod 只有轮到它排空（带有 draining-since 注解）时才摘除流量，其余的继续提供服务
+func readyToServe(mystatefulset *appsv1.MyStatefulset, pod *corev1.Pod, canaryLeaked map[string]bool) (corev1.ConditionStatus, string, string) {
+	ordinal := getOrdinal(pod.Name)
+	_, draining := pod.Annotations[appsv1.DrainingSinceAnnotation]
+	if replicas := mystatefulset.GetReplicas(); ordinal >= int(replicas) &&
+		(draining || mystatefulset.GetScaleDownDrain() == 0) {
+		return corev1.ConditionFalse, "ScalingDown",
+			fmt.Sprintf("ordinal %d is being removed by a scale-down to %d replicas", ordinal, replicas)
+	}
+	if !podInitialized(pod) {
+		return corev1.ConditionFalse, "Initializing", "init containers have not completed"
+	}
+	if canaryLeaked[pod.Name] {
+		return corev1.ConditionFalse, "OutsidePartition",
+			fmt.Sprintf("pod runs the update revision below partition %d", rollingUpdatePartition(mystatefulset))
+	}
+	return corev1.ConditionTrue, "Admitted", ""
+}
+
+// canaryBelowPartition 返回序号低于 partition 却已运行新修订版本的 Pod（例如失败后被重建），
+// 只有在 partition 以下仍有旧版本 Pod、即分区滚动尚未完成时才成立
+func canaryBelowPartition(mystatefulset *appsv1.MyStatefulset, pods []corev1.Pod) map[string]bool {
+	partition := rollingUpdatePartition(mystatefulset)
+	if partition == 0 {
+		return nil
+	}
+	updated := map[string]bool{}
+	outdated := false
+	for i := range pods {
+		if getOrdinal(pods[i].Name) >= int(partition) {
+			continue
+		}
+		template, err := mystatefulset.PodTemplateForOrdinal(int32(getOrdinal(pods[i].Name)))
+		if err != nil {
+			return nil
+		}
+		matches, ok := podRevisionMatches(&pods[i], template)
+		if !ok {
+			continue
+		}
+		if matches {
+			updated[pods[i].Name] = true
+		} else {
+			outdated = true
+		}
+	}
+	if !outdated {
+		return nil
+	}
+	return updated
+}
+
+// rollingUpdatePartition 返回滚动更新的分区序号，OnDelete 策略或未设置时为 0
+func rollingUpdatePartition(mystatefulset *appsv1.MyStatefulset) int32 {
+	if mystatefulset.Spec.UpdateStrategy.Type != appsv1.RollingUpdateStatefulSetStrategyType ||
+		mystatefulset.Spec.UpdateStrategy.RollingUpdate == nil ||
+		mystatefulset.Spec.UpdateStrategy.RollingUpdate.Partition == nil {
+		return 0
+	}
+	return *mystatefulset.Spec.UpdateStrategy.RollingUpdate.Partition
+}
+
+// hasReadyToServeGate 判断 Pod 是否带有 ready-to-serve readiness gate
+func hasReadyToServeGate(pod *corev1.Pod) bool {
+	for _, gate := range pod.Spec.ReadinessGates {
+		if gate.ConditionType == appsv1.PodConditionReadyToServe {
+			return true
+		}
+	}
+	return false
+}
+
+// podInitialized 判断 Pod 的 init 容器是否都已成功完成
+func podInitialized(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodInitialized {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// setPodCondition 设置 Pod 条件，状态变化时更新 LastTransitionTime，返回条件是否有变化
+func setPodCondition(pod *corev1.Pod, condition corev1.PodCondition) bool {
+	for i := range pod.Status.Conditions {
+		existing := &pod.Status.Conditions[i]
+		if existing.Type != condition.Type {
+			continue
+		}
+		if existing.Status == condition.Status && existing.Reason == condition.Reason &&
+			existing.Message == condition.Message {
+			return false
+		}
+		if existing.Status != condition.Status {
+			existing.LastTransitionTime = metav1.Now()
+		}
+		existing.Status = condition.Status
+		existing.Reason = condition.Reason
+		existing.Message = condition.Message
+		return true
+	}
+	condition.LastTransitionTime = metav1.Now()
+	pod.Status.Conditions = append(pod.Status.Conditions, condition)
+	return true
+}
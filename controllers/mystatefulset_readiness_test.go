@@ -0,0 +1,210 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	appsv1 "github.com/bryant-rh/my-statefulset/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	k8sappsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestMyStatefulsetReconciler_admitPods(t *testing.T) {
+	s := runtime.NewScheme()
+	_ = appsv1.AddToScheme(s)
+	_ = corev1.AddToScheme(s)
+
+	newPod := func(name string, gate, initialized bool, revision string) *corev1.Pod {
+		pod := createPodWithOwner(name, "test-uid")
+		if gate {
+			pod.Spec.ReadinessGates = []corev1.PodReadinessGate{{ConditionType: appsv1.PodConditionReadyToServe}}
+		}
+		if initialized {
+			pod.Status.Conditions = append(pod.Status.Conditions,
+				corev1.PodCondition{Type: corev1.PodInitialized, Status: corev1.ConditionTrue})
+		}
+		if revision != "" {
+			pod.Labels[k8sappsv1.ControllerRevisionHashLabelKey] = revision
+		}
+		return pod
+	}
+
+	ms := newDependentMyStatefulset()
+	ms.Spec.Replicas = pointer.Int32(3)
+	ms.Spec.UpdateStrategy = appsv1.UpdateStrategy{
+		Type:          appsv1.RollingUpdateStatefulSetStrategyType,
+		RollingUpdate: &appsv1.RollingUpdateStatefulSetStrategy{Partition: pointer.Int32(2)},
+	}
+	template, err := ms.PodTemplateForOrdinal(0)
+	require.NoError(t, err)
+	current := podRevisionHash(template)
+
+	tests := []struct {
+		name       string
+		drain      *int32
+		pods       []*corev1.Pod
+		wantStatus map[string]corev1.ConditionStatus
+		wantReason map[string]string
+	}{
+		{
+			name:       "initialized pod is admitted",
+			pods:       []*corev1.Pod{newPod("test-statefulset-0", true, true, current)},
+			wantStatus: map[string]corev1.ConditionStatus{"test-statefulset-0": corev1.ConditionTrue},
+			wantReason: map[string]string{"test-statefulset-0": "Admitted"},
+		},
+		{
+			name:       "init containers not completed",
+			pods:       []*corev1.Pod{newPod("test-statefulset-0", true, false, current)},
+			wantStatus: map[string]corev1.ConditionStatus{"test-statefulset-0": corev1.ConditionFalse},
+			wantReason: map[string]string{"test-statefulset-0": "Initializing"},
+		},
+		{
+			name:       "ordinal beyond replicas",
+			pods:       []*corev1.Pod{newPod("test-statefulset-3", true, true, current)},
+			wantStatus: map[string]corev1.ConditionStatus{"test-statefulset-3": corev1.ConditionFalse},
+			wantReason: map[string]string{"test-statefulset-3": "ScalingDown"},
+		},
+		{
+			name:       "surplus pod keeps serving until its drain starts",
+			drain:      pointer.Int32(60),
+			pods:       []*corev1.Pod{newPod("test-statefulset-3", true, true, current)},
+			wantStatus: map[string]corev1.ConditionStatus{"test-statefulset-3": corev1.ConditionTrue},
+			wantReason: map[string]string{"test-statefulset-3": "Admitted"},
+		},
+		{
+			name:  "draining pod is removed from service",
+			drain: pointer.Int32(60),
+			pods: []*corev1.Pod{func() *corev1.Pod {
+				pod := newPod("test-statefulset-3", true, true, current)
+				pod.Annotations = map[string]string{appsv1.DrainingSinceAnnotation: time.Now().UTC().Format(time.RFC3339)}
+				return pod
+			}()},
+			wantStatus: map[string]corev1.ConditionStatus{"test-statefulset-3": corev1.ConditionFalse},
+			wantReason: map[string]string{"test-statefulset-3": "ScalingDown"},
+		},
+		{
+			name: "update revision below partition during rollout",
+			pods: []*corev1.Pod{
+				newPod("test-statefulset-0", true, true, current),
+				newPod("test-statefulset-1", true, true, "old"),
+				newPod("test-statefulset-2", true, true, current),
+			},
+			wantStatus: map[string]corev1.ConditionStatus{
+				"test-statefulset-0": corev1.ConditionFalse,
+				"test-statefulset-1": corev1.ConditionTrue,
+				"test-statefulset-2": corev1.ConditionTrue,
+			},
+			wantReason: map[string]string{
+				"test-statefulset-0": "OutsidePartition",
+				"test-statefulset-1": "Admitted",
+				"test-statefulset-2": "Admitted",
+			},
+		},
+		{
+			name:       "pod without readiness gate is left alone",
+			pods:       []*corev1.Pod{newPod("test-statefulset-0", false, true, current)},
+			wantStatus: map[string]corev1.ConditionStatus{"test-statefulset-0": ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms := ms.DeepCopy()
+			ms.Spec.ScaleDownDrainSeconds = tt.drain
+			objects := []client.Object{ms}
+			for _, pod := range tt.pods {
+				objects = append(objects, pod)
+			}
+			c := fake.NewClientBuilder().WithScheme(s).WithObjects(objects...).Build()
+			r := &MyStatefulsetReconciler{Client: c, Scheme: s, Recorder: record.NewFakeRecorder(10)}
+
+			require.NoError(t, r.admitPods(context.Background(), ms))
+
+			for name, want := range tt.wantStatus {
+				pod := &corev1.Pod{}
+				require.NoError(t, c.Get(context.Background(), types.NamespacedName{Name: name, Namespace: "default"}, pod))
+				var got *corev1.PodCondition
+				for i := range pod.Status.Conditions {
+					if pod.Status.Conditions[i].Type == appsv1.PodConditionReadyToServe {
+						got = &pod.Status.Conditions[i]
+					}
+				}
+				if want == "" {
+					assert.Nil(t, got, name)
+					continue
+				}
+				require.NotNil(t, got, name)
+				assert.Equal(t, want, got.Status, name)
+				assert.Equal(t, tt.wantReason[name], got.Reason, name)
+				// kubelet 写入的其他条件应保留
+				assert.True(t, podInitialized(pod) || tt.wantReason[name] == "Initializing", name)
+			}
+		})
+	}
+}
+
+func TestMyStatefulsetReconciler_createPodInjectsReadinessGate(t *testing.T) {
+	s := runtime.NewScheme()
+	_ = appsv1.AddToScheme(s)
+	_ = corev1.AddToScheme(s)
+
+	ms := newDependentMyStatefulset()
+	c := fake.NewClientBuilder().WithScheme(s).WithObjects(ms).Build()
+	r := &MyStatefulsetReconciler{Client: c, Scheme: s, Recorder: record.NewFakeRecorder(10)}
+
+	require.NoError(t, r.createPod(context.Background(), ms, 0))
+
+	pod := &corev1.Pod{}
+	require.NoError(t, c.Get(context.Background(), types.NamespacedName{Name: "test-statefulset-0", Namespace: "default"}, pod))
+	assert.Equal(t, []corev1.PodReadinessGate{{ConditionType: appsv1.PodConditionReadyToServe}}, pod.Spec.ReadinessGates)
+}
+
+func TestMyStatefulsetReconciler_admitPodsWhileWaitingForDependencies(t *testing.T) {
+	s := runtime.NewScheme()
+	_ = appsv1.AddToScheme(s)
+	_ = corev1.AddToScheme(s)
+	ctx := context.Background()
+
+	ms := newDependentMyStatefulset(appsv1.Dependency{Name: "db"})
+	ms.Spec.Replicas = pointer.Int32(1)
+	template, err := ms.PodTemplateForOrdinal(0)
+	require.NoError(t, err)
+	pod := createPodWithOwner("test-statefulset-0", "test-uid")
+	pod.Labels[k8sappsv1.ControllerRevisionHashLabelKey] = podRevisionHash(template)
+	pod.Spec.ReadinessGates = []corev1.PodReadinessGate{{ConditionType: appsv1.PodConditionReadyToServe}}
+	pod.Status.Conditions = append(pod.Status.Conditions,
+		corev1.PodCondition{Type: corev1.PodInitialized, Status: corev1.ConditionTrue})
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-service", Namespace: "default"},
+		Spec:       corev1.ServiceSpec{ClusterIP: "None"},
+	}
+	c := fake.NewClientBuilder().WithScheme(s).WithObjects(ms, service, pod).Build()
+	r := &MyStatefulsetReconciler{Client: c, Scheme: s, Recorder: record.NewFakeRecorder(10),
+		PodInformer: &fakePodInformer{}, PVCInformer: &fakePVCInformer{}}
+
+	// 依赖不可用时不创建 Pod，但已有 Pod 的 ready-to-serve 条件照常设置
+	_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: "test-statefulset", Namespace: "default"}})
+	require.NoError(t, err)
+
+	got := &corev1.Pod{}
+	require.NoError(t, c.Get(ctx, types.NamespacedName{Name: "test-statefulset-0", Namespace: "default"}, got))
+	var condition *corev1.PodCondition
+	for i := range got.Status.Conditions {
+		if got.Status.Conditions[i].Type == appsv1.PodConditionReadyToServe {
+			condition = &got.Status.Conditions[i]
+		}
+	}
+	require.NotNil(t, condition)
+	assert.Equal(t, corev1.ConditionTrue, condition.Status)
+}
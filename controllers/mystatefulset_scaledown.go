@@ -0,0 +1,152 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	appsv1 "github.com/bryant-rh/my-statefulset/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// scaleDown 按序号从大到小逐个删除 spec.replicas 之外的 Pod，上一个 Pod 删除完成后才处理下一个。
+// 设置了 spec.scaleDownDrainSeconds 时先为 Pod 添加 draining-since 注解，排空时间结束或 Pod 上报
+// drained 条件后再删除。返回值为当前 Pod 排空结束的剩余时间，0 表示无需提前调谐
+func (r *MyStatefulsetReconciler) scaleDown(ctx context.Context, mystatefulset *appsv1.MyStatefulset) (time.Duration, error) {
+	log := log.FromContext(ctx)
+
+	selector, err := podSelector(mystatefulset)
+	if err != nil {
+		return 0, err
+	}
+	podList := &corev1.PodList{}
+	if err := r.List(ctx, podList, client.InNamespace(mystatefulset.Namespace),
+		client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return 0, err
+	}
+
+	// 缩容过程中重新扩容时，撤销留在范围内的 Pod 上的排空注解
+	replicas := int(mystatefulset.GetReplicas())
+	for _, pod := range ownedPods(podList.Items, mystatefulset) {
+		if _, ok := pod.Annotations[appsv1.DrainingSinceAnnotation]; !ok || getOrdinal(pod.Name) >= replicas {
+			continue
+		}
+		patch := client.MergeFrom(pod.DeepCopy())
+		delete(pod.Annotations, appsv1.DrainingSinceAnnotation)
+		if err := r.Patch(ctx, &pod, patch); err != nil && !errors.IsNotFound(err) {
+			return 0, err
+		}
+		r.Recorder.Event(mystatefulset, corev1.EventTypeNormal, "DrainCancelled",
+			fmt.Sprintf("Pod %s is within spec.replicas again", pod.Name))
+	}
+
+	surplus := surplusPods(mystatefulset, podList.Items)
+	drain := mystatefulset.GetScaleDownDrain()
+	for i := range surplus {
+		pod := &surplus[i]
+		// 上一个 Pod 仍在终止中
+		if pod.DeletionTimestamp != nil {
+			return 0, nil
+		}
+
+		if drain > 0 {
+			// 未 Ready 的 Pod 不承载流量，无需排空；已开始排空的 Pod 会因 ready-to-serve 条件变为 False
+			// 而不再 Ready，仍需等待排空结束
+			since, ok := drainingSince(pod)
+			if !ok && isPodReady(pod) {
+				ok = true
+				since = time.Now()
+				patch := client.MergeFrom(pod.DeepCopy())
+				if pod.Annotations == nil {
+					pod.Annotations = map[string]string{}
+				}
+				pod.Annotations[appsv1.DrainingSinceAnnotation] = since.UTC().Format(time.RFC3339)
+				if err := r.Patch(ctx, pod, patch); err != nil {
+					return 0, err
+				}
+				r.Recorder.Event(mystatefulset, corev1.EventTypeNormal, "DrainingPod",
+					fmt.Sprintf("Draining pod %s for up to %s before scale-down", pod.Name, drain))
+			}
+			if remaining := time.Until(since.Add(drain)); ok && remaining > 0 && !podDrained(pod) {
+				log.Info("Waiting for pod to drain", "pod", pod.Name, "remaining", remaining)
+				return remaining, nil
+			}
+		}
+
+		log.Info("Deleting pod for scale-down", "pod", pod.Name)
+		if err := r.Delete(ctx, pod); err != nil && !errors.IsNotFound(err) {
+			return 0, err
+		}
+		r.Recorder.Event(mystatefulset, corev1.EventTypeNormal, "ScaledDownPod",
+			fmt.Sprintf("Deleted pod %s", pod.Name))
+
+		// Pod 还在终止时等待下一次调谐，Pod 删除事件会触发调谐
+		if err := r.Get(ctx, types.NamespacedName{Name: pod.Name, Namespace: pod.Namespace}, &corev1.Pod{}); err == nil {
+			return 0, nil
+		} else if !errors.IsNotFound(err) {
+			return 0, err
+		}
+	}
+	return 0, nil
+}
+
+// surplusPods 返回序号不小于 spec.replicas 的 Pod，按序号从大到小排序
+func surplusPods(mystatefulset *appsv1.MyStatefulset, pods []corev1.Pod) []corev1.Pod {
+	replicas := int(mystatefulset.GetReplicas())
+	var surplus []corev1.Pod
+	for _, pod := range ownedPods(pods, mystatefulset) {
+		if getOrdinal(pod.Name) >= replicas {
+			surplus = append(surplus, pod)
+		}
+	}
+	sort.Slice(surplus, func(i, j int) bool {
+		return getOrdinal(surplus[i].Name) > getOrdinal(surplus[j].Name)
+	})
+	return surplus
+}
+
+// drainingSince 返回 Pod 开始排空的时间
+func drainingSince(pod *corev1.Pod) (time.Time, bool) {
+	value, ok := pod.Annotations[appsv1.DrainingSinceAnnotation]
+	if !ok {
+		return time.Time{}, false
+	}
+	since, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return since, true
+}
+
+// podDrained 判断 Pod 是否已上报排空完成
+func podDrained(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == appsv1.PodConditionDrained {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// scaleDownStatus 汇总尚未删除的多余 Pod，没有进行中的缩容时返回 nil
+func scaleDownStatus(mystatefulset *appsv1.MyStatefulset, pods []corev1.Pod) *appsv1.ScaleDownStatus {
+	surplus := surplusPods(mystatefulset, pods)
+	if len(surplus) == 0 {
+		return nil
+	}
+	status := &appsv1.ScaleDownStatus{PendingReplicas: int32(len(surplus))}
+	for i := range surplus {
+		if since, ok := drainingSince(&surplus[i]); ok && surplus[i].DeletionTimestamp == nil {
+			status.DrainingPod = surplus[i].Name
+			status.DrainStartTime = &metav1.Time{Time: since}
+			break
+		}
+	}
+	return status
+}
@@ -0,0 +1,229 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	appsv1 "github.com/bryant-rh/my-statefulset/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestMyStatefulsetReconciler_scaleDown(t *testing.T) {
+	s := runtime.NewScheme()
+	_ = appsv1.AddToScheme(s)
+	_ = corev1.AddToScheme(s)
+
+	tests := []struct {
+		name         string
+		drainSeconds *int32
+		wantPods     []string
+		wantEvents   []string
+		wantDraining string
+		wantPending  int32
+	}{
+		{
+			name:       "without drain deletes highest ordinal first",
+			wantPods:   []string{"test-statefulset-0"},
+			wantEvents: []string{"ScaledDownPod Deleted pod test-statefulset-2", "ScaledDownPod Deleted pod test-statefulset-1"},
+		},
+		{
+			name:         "with drain annotates highest ordinal only",
+			drainSeconds: pointer.Int32(60),
+			wantPods:     []string{"test-statefulset-0", "test-statefulset-1", "test-statefulset-2"},
+			wantEvents:   []string{"DrainingPod Draining pod test-statefulset-2"},
+			wantDraining: "test-statefulset-2",
+			wantPending:  2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms := newDependentMyStatefulset()
+			ms.Spec.Replicas = pointer.Int32(1)
+			ms.Spec.ScaleDownDrainSeconds = tt.drainSeconds
+			objects := []client.Object{ms}
+			for i := 0; i < 3; i++ {
+				objects = append(objects, createPodWithOwner(fmt.Sprintf("test-statefulset-%d", i), "test-uid"))
+			}
+			c := fake.NewClientBuilder().WithScheme(s).WithObjects(objects...).Build()
+			recorder := record.NewFakeRecorder(10)
+			r := &MyStatefulsetReconciler{Client: c, Scheme: s, Recorder: recorder}
+
+			requeueAfter, err := r.scaleDown(context.Background(), ms)
+			require.NoError(t, err)
+			if tt.drainSeconds != nil {
+				assert.InDelta(t, time.Minute, requeueAfter, float64(2*time.Second))
+			} else {
+				assert.Zero(t, requeueAfter)
+			}
+
+			pods := &corev1.PodList{}
+			require.NoError(t, c.List(context.Background(), pods, client.InNamespace("default")))
+			var names []string
+			for _, pod := range pods.Items {
+				names = append(names, pod.Name)
+			}
+			assert.ElementsMatch(t, tt.wantPods, names)
+			for _, want := range tt.wantEvents {
+				assert.Contains(t, <-recorder.Events, want)
+			}
+
+			status := scaleDownStatus(ms, pods.Items)
+			if tt.wantPending == 0 {
+				assert.Nil(t, status)
+				return
+			}
+			require.NotNil(t, status)
+			assert.Equal(t, tt.wantPending, status.PendingReplicas)
+			assert.Equal(t, tt.wantDraining, status.DrainingPod)
+			assert.NotNil(t, status.DrainStartTime)
+		})
+	}
+}
+
+func TestMyStatefulsetReconciler_scaleDownDrain(t *testing.T) {
+	s := runtime.NewScheme()
+	_ = appsv1.AddToScheme(s)
+	_ = corev1.AddToScheme(s)
+
+	ms := newDependentMyStatefulset()
+	ms.Spec.Replicas = pointer.Int32(1)
+	ms.Spec.ScaleDownDrainSeconds = pointer.Int32(600)
+	objects := []client.Object{ms}
+	for i := 0; i < 3; i++ {
+		objects = append(objects, createPodWithOwner(fmt.Sprintf("test-statefulset-%d", i), "test-uid"))
+	}
+	c := fake.NewClientBuilder().WithScheme(s).WithObjects(objects...).Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &MyStatefulsetReconciler{Client: c, Scheme: s, Recorder: recorder}
+	ctx := context.Background()
+	getPod := func(name string) (*corev1.Pod, error) {
+		pod := &corev1.Pod{}
+		return pod, c.Get(ctx, types.NamespacedName{Name: name, Namespace: "default"}, pod)
+	}
+
+	_, err := r.scaleDown(ctx, ms)
+	require.NoError(t, err)
+	assert.Contains(t, <-recorder.Events, "DrainingPod ")
+
+	// ready-to-serve 条件变为 False 后 Pod 不再 Ready，仍需等待排空结束
+	pod, err := getPod("test-statefulset-2")
+	require.NoError(t, err)
+	assert.Contains(t, pod.Annotations, appsv1.DrainingSinceAnnotation)
+	pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionFalse}}
+	require.NoError(t, c.Status().Update(ctx, pod))
+	requeueAfter, err := r.scaleDown(ctx, ms)
+	require.NoError(t, err)
+	assert.NotZero(t, requeueAfter)
+	_, err = getPod("test-statefulset-2")
+	require.NoError(t, err)
+
+	// Pod 上报 drained 条件后不等排空时间结束即删除，并开始排空下一个序号
+	pod, err = getPod("test-statefulset-2")
+	require.NoError(t, err)
+	pod.Status.Conditions = append(pod.Status.Conditions, corev1.PodCondition{
+		Type: appsv1.PodConditionDrained, Status: corev1.ConditionTrue, LastTransitionTime: metav1.Now()})
+	require.NoError(t, c.Status().Update(ctx, pod))
+
+	_, err = r.scaleDown(ctx, ms)
+	require.NoError(t, err)
+	assert.Contains(t, <-recorder.Events, "ScaledDownPod Deleted pod test-statefulset-2")
+	assert.Contains(t, <-recorder.Events, "DrainingPod Draining pod test-statefulset-1")
+	_, err = getPod("test-statefulset-2")
+	assert.True(t, errors.IsNotFound(err))
+
+	// 重新扩容后撤销排空
+	ms.Spec.Replicas = pointer.Int32(2)
+	_, err = r.scaleDown(ctx, ms)
+	require.NoError(t, err)
+	assert.Contains(t, <-recorder.Events, "DrainCancelled ")
+	pod, err = getPod("test-statefulset-1")
+	require.NoError(t, err)
+	assert.NotContains(t, pod.Annotations, appsv1.DrainingSinceAnnotation)
+}
+
+func TestMyStatefulsetReconciler_scaleDownDrainEachPod(t *testing.T) {
+	s := runtime.NewScheme()
+	_ = appsv1.AddToScheme(s)
+	_ = corev1.AddToScheme(s)
+
+	ms := newDependentMyStatefulset()
+	ms.Spec.Replicas = pointer.Int32(3)
+	ms.Spec.ScaleDownDrainSeconds = pointer.Int32(600)
+	objects := []client.Object{ms}
+	for i := 0; i < 5; i++ {
+		pod := createPodWithOwner(fmt.Sprintf("test-statefulset-%d", i), "test-uid")
+		pod.Spec.ReadinessGates = []corev1.PodReadinessGate{{ConditionType: appsv1.PodConditionReadyToServe}}
+		pod.Status.Conditions = append(pod.Status.Conditions,
+			corev1.PodCondition{Type: corev1.PodInitialized, Status: corev1.ConditionTrue})
+		objects = append(objects, pod)
+	}
+	c := fake.NewClientBuilder().WithScheme(s).WithObjects(objects...).Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &MyStatefulsetReconciler{Client: c, Scheme: s, Recorder: recorder}
+	ctx := context.Background()
+
+	// reconcile 依次缩容和设置 ready-to-serve 条件，并像 kubelet 一样按该条件更新 Ready
+	reconcile := func() {
+		_, err := r.scaleDown(ctx, ms)
+		require.NoError(t, err)
+		require.NoError(t, r.admitPods(ctx, ms))
+		pods := &corev1.PodList{}
+		require.NoError(t, c.List(ctx, pods, client.InNamespace("default")))
+		for i := range pods.Items {
+			pod := &pods.Items[i]
+			ready := corev1.ConditionTrue
+			for _, condition := range pod.Status.Conditions {
+				if condition.Type == appsv1.PodConditionReadyToServe {
+					ready = condition.Status
+				}
+			}
+			setPodCondition(pod, corev1.PodCondition{Type: corev1.PodReady, Status: ready})
+			require.NoError(t, c.Status().Update(ctx, pod))
+		}
+	}
+	drained := func(name string) {
+		pod := &corev1.Pod{}
+		require.NoError(t, c.Get(ctx, types.NamespacedName{Name: name, Namespace: "default"}, pod))
+		pod.Status.Conditions = append(pod.Status.Conditions, corev1.PodCondition{
+			Type: appsv1.PodConditionDrained, Status: corev1.ConditionTrue, LastTransitionTime: metav1.Now()})
+		require.NoError(t, c.Status().Update(ctx, pod))
+	}
+
+	// 只有正在排空的 Pod 摘除流量，下一个多余的 Pod 仍然 Ready
+	reconcile()
+	assert.Contains(t, <-recorder.Events, "DrainingPod Draining pod test-statefulset-4")
+	reconcile()
+	pod := &corev1.Pod{}
+	require.NoError(t, c.Get(ctx, types.NamespacedName{Name: "test-statefulset-3", Namespace: "default"}, pod))
+	assert.True(t, isPodReady(pod))
+
+	// 每个多余的 Pod 都会排空后再删除
+	drained("test-statefulset-4")
+	reconcile()
+	assert.Contains(t, <-recorder.Events, "ScaledDownPod Deleted pod test-statefulset-4")
+	assert.Contains(t, <-recorder.Events, "DrainingPod Draining pod test-statefulset-3")
+	reconcile()
+	require.NoError(t, c.Get(ctx, types.NamespacedName{Name: "test-statefulset-3", Namespace: "default"}, pod))
+	assert.Contains(t, pod.Annotations, appsv1.DrainingSinceAnnotation)
+
+	drained("test-statefulset-3")
+	reconcile()
+	assert.Contains(t, <-recorder.Events, "ScaledDownPod Deleted pod test-statefulset-3")
+
+	pods := &corev1.PodList{}
+	require.NoError(t, c.List(ctx, pods, client.InNamespace("default")))
+	assert.Len(t, pods.Items, 3)
+}
@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	appsv1 "github.com/bryant-rh/my-statefulset/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// applyScalingSchedule 按 spec.scalingSchedule 设置副本数，返回距下一个边界的时间。
+// 只有跨过新的边界时才修改 spec.replicas，两个边界之间的手动扩缩容保留到下一个边界
+func (r *MyStatefulsetReconciler) applyScalingSchedule(ctx context.Context, mystatefulset *appsv1.MyStatefulset) (time.Duration, error) {
+	log := log.FromContext(ctx)
+	previous := mystatefulset.Status.ScalingSchedule
+
+	if len(mystatefulset.Spec.ScalingSchedule) == 0 {
+		if previous == nil {
+			return 0, nil
+		}
+		patch := client.MergeFrom(mystatefulset.DeepCopy())
+		mystatefulset.Status.ScalingSchedule = nil
+		return 0, r.Status().Patch(ctx, mystatefulset, patch)
+	}
+
+	now := time.Now()
+	last, next, err := mystatefulset.ScalingScheduleBoundaries(now)
+	if err != nil {
+		// 非法的表达式已被 webhook 拒绝，这里只记录事件，不影响其余的调谐
+		r.Recorder.Event(mystatefulset, corev1.EventTypeWarning, "InvalidScalingSchedule", err.Error())
+		return 0, nil
+	}
+
+	status := &appsv1.ScalingScheduleStatus{}
+	var requeueAfter time.Duration
+	if last != nil {
+		status.ActiveEntry = last.Entry
+		status.ScheduledReplicas = last.Replicas
+		status.LastScheduleTime = &metav1.Time{Time: last.Time}
+
+		crossed := previous == nil || previous.LastScheduleTime == nil ||
+			previous.LastScheduleTime.Time.Before(last.Time)
+		if crossed && mystatefulset.GetReplicas() != last.Replicas {
+			from := mystatefulset.GetReplicas()
+			original := mystatefulset.Spec.Replicas
+			replicas := last.Replicas
+			mystatefulset.Spec.Replicas = &replicas
+			err := r.Update(ctx, mystatefulset)
+			switch {
+			case errors.IsInvalid(err) || errors.IsForbidden(err):
+				// 被准入策略拒绝（例如副本数增幅超过限制）时重试也不会成功，记录事件并跳过该边界，
+				// 副本数保持不变，直到下一个边界或手动修改
+				mystatefulset.Spec.Replicas = original
+				log.Info("Scaling schedule rejected", "entry", last.Entry, "from", from, "to", replicas, "reason", err.Error())
+				r.Recorder.Event(mystatefulset, corev1.EventTypeWarning, "ScheduledScalingRejected",
+					fmt.Sprintf("Schedule %s could not scale from %d to %d replicas: %v", last.Entry, from, replicas, err))
+			case err != nil:
+				return 0, fmt.Errorf("failed to apply scaling schedule %s: %w", last.Entry, err)
+			default:
+				log.Info("Applied scaling schedule", "entry", last.Entry, "from", from, "to", replicas)
+				r.Recorder.Event(mystatefulset, corev1.EventTypeNormal, "ScheduledScaling",
+					fmt.Sprintf("Scaled from %d to %d replicas by schedule %s", from, replicas, last.Entry))
+			}
+		}
+		status.Overridden = mystatefulset.GetReplicas() != last.Replicas
+	}
+	if next != nil {
+		status.NextEntry = next.Entry
+		status.NextReplicas = next.Replicas
+		status.NextScheduleTime = &metav1.Time{Time: next.Time}
+		requeueAfter = next.Time.Sub(now)
+	}
+
+	if !equality.Semantic.DeepEqual(previous, status) {
+		patch := client.MergeFrom(mystatefulset.DeepCopy())
+		mystatefulset.Status.ScalingSchedule = status
+		if err := r.Status().Patch(ctx, mystatefulset, patch); err != nil {
+			return 0, fmt.Errorf("failed to record scaling schedule: %w", err)
+		}
+	}
+	return requeueAfter, nil
+}
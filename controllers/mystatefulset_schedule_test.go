@@ -0,0 +1,142 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	appsv1 "github.com/bryant-rh/my-statefulset/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestMyStatefulsetReconciler_applyScalingSchedule(t *testing.T) {
+	s := runtime.NewScheme()
+	_ = appsv1.AddToScheme(s)
+	_ = corev1.AddToScheme(s)
+
+	ms := &appsv1.MyStatefulset{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-statefulset", Namespace: "default", UID: "test-uid"},
+		Spec: appsv1.MyStatefulsetSpec{
+			Replicas: pointer.Int32(5),
+			// 每年 1 月 1 日触发，最后一个边界总是在过去一年内
+			ScalingSchedule: []appsv1.ScheduledScaling{{Name: "new-year", Schedule: "@yearly", Replicas: 2}},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(s).WithObjects(ms).Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &MyStatefulsetReconciler{Client: c, Scheme: s, Recorder: recorder}
+	ctx := context.Background()
+	key := types.NamespacedName{Name: "test-statefulset", Namespace: "default"}
+
+	get := func() *appsv1.MyStatefulset {
+		got := &appsv1.MyStatefulset{}
+		require.NoError(t, c.Get(ctx, key, got))
+		return got
+	}
+
+	// 第一次调谐立即应用当前生效的条目
+	after, err := r.applyScalingSchedule(ctx, get())
+	require.NoError(t, err)
+	assert.True(t, after > 0 && after <= 366*24*time.Hour, "requeue after %v", after)
+	assert.Contains(t, <-recorder.Events, "ScheduledScaling ")
+
+	got := get()
+	assert.Equal(t, int32(2), got.GetReplicas())
+	require.NotNil(t, got.Status.ScalingSchedule)
+	assert.Equal(t, "new-year", got.Status.ScalingSchedule.ActiveEntry)
+	assert.Equal(t, int32(2), got.Status.ScalingSchedule.ScheduledReplicas)
+	assert.Equal(t, "new-year", got.Status.ScalingSchedule.NextEntry)
+	assert.False(t, got.Status.ScalingSchedule.Overridden)
+	lastScheduleTime := got.Status.ScalingSchedule.LastScheduleTime
+
+	// 两个边界之间手动扩容，保留到下一个边界
+	got.Spec.Replicas = pointer.Int32(4)
+	require.NoError(t, c.Update(ctx, got))
+	_, err = r.applyScalingSchedule(ctx, get())
+	require.NoError(t, err)
+	got = get()
+	assert.Equal(t, int32(4), got.GetReplicas())
+	assert.True(t, got.Status.ScalingSchedule.Overridden)
+	assert.Empty(t, recorder.Events)
+
+	// 跨过新的边界后重新应用
+	got.Status.ScalingSchedule.LastScheduleTime = &metav1.Time{Time: lastScheduleTime.AddDate(-1, 0, 0)}
+	require.NoError(t, c.Status().Update(ctx, got))
+	_, err = r.applyScalingSchedule(ctx, get())
+	require.NoError(t, err)
+	got = get()
+	assert.Equal(t, int32(2), got.GetReplicas())
+	assert.False(t, got.Status.ScalingSchedule.Overridden)
+	assert.True(t, got.Status.ScalingSchedule.LastScheduleTime.Equal(lastScheduleTime))
+
+	// 删除计划后清除状态
+	got.Spec.ScalingSchedule = nil
+	require.NoError(t, c.Update(ctx, got))
+	after, err = r.applyScalingSchedule(ctx, get())
+	require.NoError(t, err)
+	assert.Zero(t, after)
+	assert.Nil(t, get().Status.ScalingSchedule)
+}
+
+// rejectingClient 模拟准入 webhook 拒绝对 MyStatefulset 的更新
+type rejectingClient struct {
+	client.Client
+}
+
+func (c rejectingClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	if _, ok := obj.(*appsv1.MyStatefulset); ok {
+		return apierrors.NewInvalid(appsv1.GroupVersion.WithKind("MyStatefulset").GroupKind(), obj.GetName(),
+			field.ErrorList{field.Invalid(field.NewPath("spec").Child("replicas"), nil, "cannot increase replicas by more than 100% in a single update")})
+	}
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+func TestMyStatefulsetReconciler_applyScalingScheduleRejected(t *testing.T) {
+	s := runtime.NewScheme()
+	_ = appsv1.AddToScheme(s)
+	_ = corev1.AddToScheme(s)
+
+	ms := &appsv1.MyStatefulset{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-statefulset", Namespace: "default", UID: "test-uid"},
+		Spec: appsv1.MyStatefulsetSpec{
+			Replicas:        pointer.Int32(2),
+			ScalingSchedule: []appsv1.ScheduledScaling{{Name: "new-year", Schedule: "@yearly", Replicas: 10}},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(s).WithObjects(ms).Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &MyStatefulsetReconciler{Client: rejectingClient{c}, Scheme: s, Recorder: recorder}
+	ctx := context.Background()
+	key := types.NamespacedName{Name: "test-statefulset", Namespace: "default"}
+
+	// 被拒绝时不中断调谐，记录事件并跳过该边界
+	got := &appsv1.MyStatefulset{}
+	require.NoError(t, c.Get(ctx, key, got))
+	after, err := r.applyScalingSchedule(ctx, got)
+	require.NoError(t, err)
+	assert.True(t, after > 0)
+	assert.Equal(t, int32(2), got.GetReplicas())
+	assert.Contains(t, <-recorder.Events, "ScheduledScalingRejected")
+
+	require.NoError(t, c.Get(ctx, key, got))
+	assert.Equal(t, int32(2), got.GetReplicas())
+	require.NotNil(t, got.Status.ScalingSchedule)
+	assert.NotNil(t, got.Status.ScalingSchedule.LastScheduleTime)
+	assert.True(t, got.Status.ScalingSchedule.Overridden)
+
+	// 同一个边界不会重复尝试
+	_, err = r.applyScalingSchedule(ctx, got)
+	require.NoError(t, err)
+	assert.Empty(t, recorder.Events)
+}
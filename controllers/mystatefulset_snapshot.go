@@ -0,0 +1,221 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	appsv1 "github.com/bryant-rh/my-statefulset/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// volumeSnapshotGVK 是 CSI external-snapshotter 的 VolumeSnapshot，
+// 使用 unstructured 访问，集群中没有安装快照 CRD 时不影响控制器的其他功能
+var volumeSnapshotGVK = schema.GroupVersionKind{
+	Group:   "snapshot.storage.k8s.io",
+	Version: "v1",
+	Kind:    "VolumeSnapshot",
+}
+
+// 快照上的标签，用于按副本集、序号、PVC 和被替换的 Pod 查找快照
+const (
+	snapshotSetLabel      = "apps.mystatefulset.com/set"
+	snapshotOrdinalLabel  = "apps.mystatefulset.com/ordinal"
+	snapshotClaimLabel    = "apps.mystatefulset.com/claim"
+	snapshotRevisionLabel = "apps.mystatefulset.com/revision"
+	snapshotPodUIDLabel   = "apps.mystatefulset.com/pod-uid"
+)
+
+// ensurePreUpdateSnapshots 在更新 Pod 之前为其序号的每个 PVC 创建 VolumeSnapshot，
+// 全部 readyToUse 时返回 true，并按 retain 清理旧快照。
+// 每次更新的快照按被替换 Pod 的 UID 标记和查找，重复调谐不会重复创建，
+// 回滚后再次更新到同一修订时也会重新创建快照
+func (r *MyStatefulsetReconciler) ensurePreUpdateSnapshots(ctx context.Context, mystatefulset *appsv1.MyStatefulset, pod *corev1.Pod) (bool, error) {
+	policy := mystatefulset.Spec.SnapshotPolicy
+	if policy == nil || !policy.Enabled {
+		return true, nil
+	}
+	log := log.FromContext(ctx)
+
+	ordinal := getOrdinal(pod.Name)
+	template, err := mystatefulset.PodTemplateForOrdinal(int32(ordinal))
+	if err != nil {
+		return false, err
+	}
+	revision := podRevisionHash(template)
+
+	ready := true
+	for _, claimName := range claimNamesForOrdinal(mystatefulset, ordinal) {
+		// PVC 不存在时没有需要备份的数据
+		pvc := &corev1.PersistentVolumeClaim{}
+		if err := r.Get(ctx, types.NamespacedName{Name: claimName, Namespace: mystatefulset.Namespace}, pvc); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return false, err
+		}
+
+		snapshots, err := r.listSnapshots(ctx, mystatefulset, client.MatchingLabels{
+			snapshotSetLabel:    mystatefulset.Name,
+			snapshotClaimLabel:  claimName,
+			snapshotPodUIDLabel: string(pod.UID),
+		})
+		if err != nil {
+			return false, err
+		}
+		if len(snapshots) == 0 {
+			name := snapshotName(claimName, revision, pod.UID)
+			snapshot := newVolumeSnapshot(mystatefulset, ordinal, claimName, name, revision, pod.UID)
+			if err := r.Create(ctx, snapshot); err != nil {
+				r.Recorder.Event(mystatefulset, corev1.EventTypeWarning, "SnapshotFailed",
+					fmt.Sprintf("Failed to create VolumeSnapshot %s of PVC %s: %v", name, claimName, err))
+				return false, fmt.Errorf("failed to create VolumeSnapshot %s: %w", name, err)
+			}
+			log.Info("Created pre-update snapshot", "snapshot", name, "pvc", claimName)
+			r.Recorder.Event(mystatefulset, corev1.EventTypeNormal, "SnapshotCreated",
+				fmt.Sprintf("Created VolumeSnapshot %s of PVC %s before updating ordinal %d", name, claimName, ordinal))
+			ready = false
+			continue
+		}
+
+		snapshot := &snapshots[0]
+		name := snapshot.GetName()
+		if readyToUse, _, _ := unstructured.NestedBool(snapshot.Object, "status", "readyToUse"); !readyToUse {
+			if message, found, _ := unstructured.NestedString(snapshot.Object, "status", "error", "message"); found {
+				r.Recorder.Event(mystatefulset, corev1.EventTypeWarning, "SnapshotFailed",
+					fmt.Sprintf("VolumeSnapshot %s of PVC %s failed: %s", name, claimName, message))
+			}
+			log.Info("Waiting for pre-update snapshot to become ready", "snapshot", name)
+			ready = false
+			continue
+		}
+
+		if err := r.pruneSnapshots(ctx, mystatefulset, claimName, policy.GetRetain()); err != nil {
+			return false, err
+		}
+	}
+	return ready, nil
+}
+
+// snapshotName 返回快照名称 <claim>-<目标修订>-<Pod UID 前 8 位>
+func snapshotName(claimName, revision string, podUID types.UID) string {
+	uid := string(podUID)
+	if len(uid) > 8 {
+		uid = uid[:8]
+	}
+	return fmt.Sprintf("%s-%s-%s", claimName, revision, uid)
+}
+
+// newVolumeSnapshot 构造 PVC 的 VolumeSnapshot。
+// 快照不设置 ownerReference，删除 MyStatefulset 时不会级联删除备份
+func newVolumeSnapshot(mystatefulset *appsv1.MyStatefulset, ordinal int, claimName, name, revision string,
+	podUID types.UID) *unstructured.Unstructured {
+	snapshot := &unstructured.Unstructured{}
+	snapshot.SetGroupVersionKind(volumeSnapshotGVK)
+	snapshot.SetName(name)
+	snapshot.SetNamespace(mystatefulset.Namespace)
+	snapshot.SetLabels(map[string]string{
+		snapshotSetLabel:      mystatefulset.Name,
+		snapshotOrdinalLabel:  strconv.Itoa(ordinal),
+		snapshotClaimLabel:    claimName,
+		snapshotRevisionLabel: revision,
+		snapshotPodUIDLabel:   string(podUID),
+	})
+	_ = unstructured.SetNestedField(snapshot.Object, claimName, "spec", "source", "persistentVolumeClaimName")
+	if className := mystatefulset.Spec.SnapshotPolicy.VolumeSnapshotClassName; className != nil {
+		_ = unstructured.SetNestedField(snapshot.Object, *className, "spec", "volumeSnapshotClassName")
+	}
+	return snapshot
+}
+
+// pruneSnapshots 只保留 PVC 最新的 retain 个快照
+func (r *MyStatefulsetReconciler) pruneSnapshots(ctx context.Context, mystatefulset *appsv1.MyStatefulset, claimName string, retain int32) error {
+	snapshots, err := r.listSnapshots(ctx, mystatefulset, client.MatchingLabels{
+		snapshotSetLabel:   mystatefulset.Name,
+		snapshotClaimLabel: claimName,
+	})
+	if err != nil {
+		return err
+	}
+	if len(snapshots) <= int(retain) {
+		return nil
+	}
+
+	sortSnapshotsNewestFirst(snapshots)
+	for i := int(retain); i < len(snapshots); i++ {
+		if err := r.Delete(ctx, &snapshots[i]); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete VolumeSnapshot %s: %w", snapshots[i].GetName(), err)
+		}
+		log.FromContext(ctx).Info("Pruned old snapshot", "snapshot", snapshots[i].GetName(), "pvc", claimName)
+	}
+	return nil
+}
+
+// snapshotReferences 返回副本集保留的快照，按序号、PVC 和创建时间排序；未安装快照 CRD 时返回 nil
+func (r *MyStatefulsetReconciler) snapshotReferences(ctx context.Context, mystatefulset *appsv1.MyStatefulset) ([]appsv1.VolumeSnapshotReference, error) {
+	if mystatefulset.Spec.SnapshotPolicy == nil || !mystatefulset.Spec.SnapshotPolicy.Enabled {
+		return nil, nil
+	}
+	snapshots, err := r.listSnapshots(ctx, mystatefulset, client.MatchingLabels{snapshotSetLabel: mystatefulset.Name})
+	if err != nil {
+		if meta.IsNoMatchError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	refs := make([]appsv1.VolumeSnapshotReference, 0, len(snapshots))
+	for i := range snapshots {
+		labels := snapshots[i].GetLabels()
+		ordinal, err := strconv.Atoi(labels[snapshotOrdinalLabel])
+		if err != nil {
+			continue
+		}
+		readyToUse, _, _ := unstructured.NestedBool(snapshots[i].Object, "status", "readyToUse")
+		refs = append(refs, appsv1.VolumeSnapshotReference{
+			Ordinal:      int32(ordinal),
+			ClaimName:    labels[snapshotClaimLabel],
+			Name:         snapshots[i].GetName(),
+			Revision:     labels[snapshotRevisionLabel],
+			ReadyToUse:   readyToUse,
+			CreationTime: snapshots[i].GetCreationTimestamp(),
+		})
+	}
+	sort.Slice(refs, func(i, j int) bool {
+		if refs[i].Ordinal != refs[j].Ordinal {
+			return refs[i].Ordinal < refs[j].Ordinal
+		}
+		if refs[i].ClaimName != refs[j].ClaimName {
+			return refs[i].ClaimName < refs[j].ClaimName
+		}
+		return refs[i].CreationTime.Before(&refs[j].CreationTime)
+	})
+	return refs, nil
+}
+
+// listSnapshots 列出副本集命名空间中匹配标签的 VolumeSnapshot
+func (r *MyStatefulsetReconciler) listSnapshots(ctx context.Context, mystatefulset *appsv1.MyStatefulset,
+	selector client.MatchingLabels) ([]unstructured.Unstructured, error) {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(volumeSnapshotGVK.GroupVersion().WithKind(volumeSnapshotGVK.Kind + "List"))
+	if err := r.List(ctx, list, client.InNamespace(mystatefulset.Namespace), selector); err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// sortSnapshotsNewestFirst 按创建时间从新到旧排序
+func sortSnapshotsNewestFirst(snapshots []unstructured.Unstructured) {
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		ti, tj := snapshots[i].GetCreationTimestamp(), snapshots[j].GetCreationTimestamp()
+		return tj.Before(&ti)
+	})
+}
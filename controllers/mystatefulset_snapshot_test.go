@@ -0,0 +1,139 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	appsv1 "github.com/bryant-rh/my-statefulset/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestMyStatefulsetReconciler_ensurePreUpdateSnapshots(t *testing.T) {
+	s := runtime.NewScheme()
+	_ = appsv1.AddToScheme(s)
+	_ = corev1.AddToScheme(s)
+	s.AddKnownTypeWithName(volumeSnapshotGVK, &unstructured.Unstructured{})
+	s.AddKnownTypeWithName(volumeSnapshotGVK.GroupVersion().WithKind("VolumeSnapshotList"), &unstructured.UnstructuredList{})
+
+	ms := &appsv1.MyStatefulset{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-statefulset", Namespace: "default", UID: "test-uid"},
+		Spec: appsv1.MyStatefulsetSpec{
+			Replicas: pointer.Int32(2),
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "test"}},
+			Template: appsv1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "test"}},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "test-container", Image: "nginx:1.25"}},
+				},
+			},
+			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{
+				{ObjectMeta: metav1.ObjectMeta{Name: "www"}},
+			},
+			SnapshotPolicy: &appsv1.SnapshotPolicy{
+				Enabled:                 true,
+				VolumeSnapshotClassName: pointer.String("csi-snapclass"),
+				Retain:                  pointer.Int32(2),
+			},
+		},
+	}
+
+	// 两个之前的快照，保留 2 个时最旧的一个会被清理
+	oldSnapshot := func(name string, age time.Duration) *unstructured.Unstructured {
+		snapshot := newVolumeSnapshot(ms, 1, "www-test-statefulset-1", name, "old", "old-pod-uid")
+		snapshot.SetCreationTimestamp(metav1.NewTime(time.Now().Add(-age)))
+		_ = unstructured.SetNestedField(snapshot.Object, true, "status", "readyToUse")
+		return snapshot
+	}
+	c := fake.NewClientBuilder().WithScheme(s).WithObjects(
+		&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "www-test-statefulset-1", Namespace: "default"}},
+		oldSnapshot("www-test-statefulset-1-oldest", 2*time.Hour),
+		oldSnapshot("www-test-statefulset-1-older", time.Hour),
+	).Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &MyStatefulsetReconciler{Client: c, Scheme: s, Recorder: recorder}
+	ctx := context.Background()
+	newPod := func(ordinal int, uid types.UID) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name: fmt.Sprintf("test-statefulset-%d", ordinal), Namespace: "default", UID: uid}}
+	}
+	pod := newPod(1, "0f4b2c1e-5a6d-4e7f-8a9b-0c1d2e3f4a5b")
+
+	// 没有 PVC 的序号无需快照
+	ready, err := r.ensurePreUpdateSnapshots(ctx, ms, newPod(0, "pod-0-uid"))
+	require.NoError(t, err)
+	assert.True(t, ready)
+
+	// 第一次调谐创建快照并等待
+	ready, err = r.ensurePreUpdateSnapshots(ctx, ms, pod)
+	require.NoError(t, err)
+	assert.False(t, ready)
+	assert.Contains(t, <-recorder.Events, "SnapshotCreated")
+
+	template, err := ms.PodTemplateForOrdinal(1)
+	require.NoError(t, err)
+	name := "www-test-statefulset-1-" + podRevisionHash(template) + "-0f4b2c1e"
+	snapshot := &unstructured.Unstructured{}
+	snapshot.SetGroupVersionKind(volumeSnapshotGVK)
+	require.NoError(t, c.Get(ctx, types.NamespacedName{Name: name, Namespace: "default"}, snapshot))
+	source, _, _ := unstructured.NestedString(snapshot.Object, "spec", "source", "persistentVolumeClaimName")
+	assert.Equal(t, "www-test-statefulset-1", source)
+	className, _, _ := unstructured.NestedString(snapshot.Object, "spec", "volumeSnapshotClassName")
+	assert.Equal(t, "csi-snapclass", className)
+	assert.Equal(t, "1", snapshot.GetLabels()[snapshotOrdinalLabel])
+	assert.Equal(t, string(pod.UID), snapshot.GetLabels()[snapshotPodUIDLabel])
+
+	// 快照未就绪时继续等待，不会重复创建
+	ready, err = r.ensurePreUpdateSnapshots(ctx, ms, pod)
+	require.NoError(t, err)
+	assert.False(t, ready)
+
+	// 快照就绪后允许更新，并清理超出 retain 的旧快照
+	snapshot.SetCreationTimestamp(metav1.Now())
+	require.NoError(t, unstructured.SetNestedField(snapshot.Object, true, "status", "readyToUse"))
+	require.NoError(t, c.Update(ctx, snapshot))
+	ready, err = r.ensurePreUpdateSnapshots(ctx, ms, pod)
+	require.NoError(t, err)
+	assert.True(t, ready)
+
+	oldest := &unstructured.Unstructured{}
+	oldest.SetGroupVersionKind(volumeSnapshotGVK)
+	err = c.Get(ctx, types.NamespacedName{Name: "www-test-statefulset-1-oldest", Namespace: "default"}, oldest)
+	assert.True(t, errors.IsNotFound(err))
+
+	refs, err := r.snapshotReferences(ctx, ms)
+	require.NoError(t, err)
+	require.Len(t, refs, 2)
+	assert.Equal(t, "www-test-statefulset-1-older", refs[0].Name)
+	assert.Equal(t, name, refs[1].Name)
+	assert.Equal(t, int32(1), refs[1].Ordinal)
+	assert.Equal(t, "www-test-statefulset-1", refs[1].ClaimName)
+	assert.True(t, refs[1].ReadyToUse)
+
+	// 回滚后再次更新到同一修订时替换的是另一个 Pod，需要重新创建快照
+	ready, err = r.ensurePreUpdateSnapshots(ctx, ms, newPod(1, "7c8d9e0f-1a2b-4c3d-9e8f-7a6b5c4d3e2f"))
+	require.NoError(t, err)
+	assert.False(t, ready)
+	assert.Contains(t, <-recorder.Events, "SnapshotCreated")
+	again := &unstructured.Unstructured{}
+	again.SetGroupVersionKind(volumeSnapshotGVK)
+	require.NoError(t, c.Get(ctx, types.NamespacedName{
+		Name: "www-test-statefulset-1-" + podRevisionHash(template) + "-7c8d9e0f", Namespace: "default"}, again))
+
+	// 关闭策略时直接允许更新
+	ms.Spec.SnapshotPolicy.Enabled = false
+	ready, err = r.ensurePreUpdateSnapshots(ctx, ms, pod)
+	require.NoError(t, err)
+	assert.True(t, ready)
+}
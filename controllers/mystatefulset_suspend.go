@@ -0,0 +1,111 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	appsv1 "github.com/bryant-rh/my-statefulset/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// suspend 按序号逆序逐个删除 Pod，保留 PVC，不修改 spec.replicas
+func (r *MyStatefulsetReconciler) suspend(ctx context.Context, mystatefulset *appsv1.MyStatefulset) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+
+	selector, err := podSelector(mystatefulset)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	podList := &corev1.PodList{}
+	if err := r.List(ctx, podList, client.InNamespace(mystatefulset.Namespace),
+		client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return ctrl.Result{}, err
+	}
+	pods := ownedPods(podList.Items, mystatefulset)
+	sort.Slice(pods, func(i, j int) bool {
+		return getOrdinal(pods[i].Name) > getOrdinal(pods[j].Name)
+	})
+
+	// 记录暂停时的副本数
+	if mystatefulset.Status.SuspendedReplicas == nil {
+		replicas := mystatefulset.GetReplicas()
+		mystatefulset.Status.SuspendedReplicas = &replicas
+	}
+
+	// 等上一个序号的 Pod 删除完成后再删除下一个
+	if len(pods) > 0 && pods[0].DeletionTimestamp == nil {
+		log.Info("Deleting pod for suspend", "pod", pods[0].Name)
+		if err := r.Delete(ctx, &pods[0]); err != nil && !errors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+	}
+
+	condition := metav1.Condition{
+		Type:               appsv1.ConditionSuspended,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: mystatefulset.Generation,
+		Reason:             "Suspending",
+		Message:            fmt.Sprintf("%d pods left to delete", len(pods)),
+	}
+	if len(pods) == 0 {
+		condition.Reason = "Suspended"
+		condition.Message = "All pods are deleted; PersistentVolumeClaims are retained"
+		if previous := meta.FindStatusCondition(mystatefulset.Status.Conditions, appsv1.ConditionSuspended); previous == nil ||
+			previous.Reason != condition.Reason {
+			r.Recorder.Event(mystatefulset, corev1.EventTypeNormal, "Suspended",
+				fmt.Sprintf("Deleted all pods of %d replicas", *mystatefulset.Status.SuspendedReplicas))
+		}
+	}
+	meta.SetStatusCondition(&mystatefulset.Status.Conditions, condition)
+
+	if err := r.updateStatus(ctx, mystatefulset); err != nil {
+		return ctrl.Result{}, err
+	}
+	if len(pods) > 0 {
+		return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+	}
+	return ctrl.Result{}, nil
+}
+
+// resume 在 spec.suspend 被取消后将 Suspended 条件置为 False 并立即保存，
+// reconcilePods 在该条件为 False 时按序号逐个创建 Pod，等前一个 Ready 后再创建下一个
+func (r *MyStatefulsetReconciler) resume(ctx context.Context, mystatefulset *appsv1.MyStatefulset) error {
+	if !meta.IsStatusConditionTrue(mystatefulset.Status.Conditions, appsv1.ConditionSuspended) {
+		return nil
+	}
+	patch := client.MergeFrom(mystatefulset.DeepCopy())
+	mystatefulset.Status.SuspendedReplicas = nil
+	meta.SetStatusCondition(&mystatefulset.Status.Conditions, metav1.Condition{
+		Type:               appsv1.ConditionSuspended,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: mystatefulset.Generation,
+		Reason:             "Resuming",
+		Message:            "Recreating pods in ordinal order",
+	})
+	if err := r.Status().Patch(ctx, mystatefulset, patch); err != nil {
+		return fmt.Errorf("failed to record resume: %w", err)
+	}
+	r.Recorder.Event(mystatefulset, corev1.EventTypeNormal, "Resumed",
+		fmt.Sprintf("Recreating %d pods in ordinal order", mystatefulset.GetReplicas()))
+	return nil
+}
+
+// isResuming 判断是否正在从暂停中恢复
+func isResuming(mystatefulset *appsv1.MyStatefulset) bool {
+	return meta.IsStatusConditionFalse(mystatefulset.Status.Conditions, appsv1.ConditionSuspended)
+}
+
+// finishResume 在所有副本 Ready 后删除 Suspended 条件
+func finishResume(status *appsv1.MyStatefulsetStatus, replicas int32) {
+	if meta.IsStatusConditionFalse(status.Conditions, appsv1.ConditionSuspended) && status.ReadyReplicas >= replicas {
+		meta.RemoveStatusCondition(&status.Conditions, appsv1.ConditionSuspended)
+	}
+}
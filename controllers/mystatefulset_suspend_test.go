@@ -0,0 +1,108 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	appsv1 "github.com/bryant-rh/my-statefulset/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestMyStatefulsetReconciler_suspendAndResume(t *testing.T) {
+	s := runtime.NewScheme()
+	_ = appsv1.AddToScheme(s)
+	_ = corev1.AddToScheme(s)
+
+	ms := newDependentMyStatefulset()
+	ms.Spec.Replicas = pointer.Int32(3)
+	ms.Spec.Suspend = true
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-service", Namespace: "default"},
+		Spec:       corev1.ServiceSpec{ClusterIP: "None"},
+	}
+	pvc := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "www-test-statefulset-0", Namespace: "default"}}
+	objects := []client.Object{ms, service, pvc}
+	for i := 0; i < 3; i++ {
+		objects = append(objects, createPodWithOwner(fmt.Sprintf("test-statefulset-%d", i), "test-uid"))
+	}
+	c := fake.NewClientBuilder().WithScheme(s).WithObjects(objects...).Build()
+	recorder := record.NewFakeRecorder(20)
+	r := &MyStatefulsetReconciler{Client: c, Scheme: s, Recorder: recorder,
+		PodInformer: &fakePodInformer{}, PVCInformer: &fakePVCInformer{}}
+	ctx := context.Background()
+	key := types.NamespacedName{Name: "test-statefulset", Namespace: "default"}
+
+	reconcile := func() (ctrl.Result, *appsv1.MyStatefulset) {
+		result, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		require.NoError(t, err)
+		got := &appsv1.MyStatefulset{}
+		require.NoError(t, c.Get(ctx, key, got))
+		return result, got
+	}
+	podNames := func() []string {
+		pods := &corev1.PodList{}
+		require.NoError(t, c.List(ctx, pods, client.InNamespace("default")))
+		var names []string
+		for _, pod := range pods.Items {
+			names = append(names, pod.Name)
+		}
+		return names
+	}
+
+	// 按序号逆序逐个删除
+	result, got := reconcile()
+	assert.NotZero(t, result.RequeueAfter)
+	assert.ElementsMatch(t, []string{"test-statefulset-0", "test-statefulset-1"}, podNames())
+	assert.Equal(t, int32(3), got.GetReplicas())
+	require.NotNil(t, got.Status.SuspendedReplicas)
+	assert.Equal(t, int32(3), *got.Status.SuspendedReplicas)
+	condition := meta.FindStatusCondition(got.Status.Conditions, appsv1.ConditionSuspended)
+	require.NotNil(t, condition)
+	assert.Equal(t, "Suspending", condition.Reason)
+
+	reconcile()
+	assert.Equal(t, []string{"test-statefulset-0"}, podNames())
+	reconcile()
+	result, got = reconcile()
+	assert.Zero(t, result.RequeueAfter)
+	assert.Empty(t, podNames())
+	assert.Contains(t, <-recorder.Events, "Suspended ")
+	assert.Equal(t, "Suspended", meta.FindStatusCondition(got.Status.Conditions, appsv1.ConditionSuspended).Reason)
+	assert.Equal(t, int32(3), got.GetReplicas())
+	require.NoError(t, c.Get(ctx, types.NamespacedName{Name: pvc.Name, Namespace: "default"}, &corev1.PersistentVolumeClaim{}))
+
+	// 恢复时按序号逐个创建，前一个 Ready 后才创建下一个
+	got.Spec.Suspend = false
+	require.NoError(t, c.Update(ctx, got))
+	_, got = reconcile()
+	assert.Contains(t, <-recorder.Events, "Resumed ")
+	assert.Equal(t, []string{"test-statefulset-0"}, podNames())
+	assert.True(t, isResuming(got))
+	assert.Nil(t, got.Status.SuspendedReplicas)
+
+	reconcile()
+	assert.Equal(t, []string{"test-statefulset-0"}, podNames())
+
+	for i := 0; i < 3; i++ {
+		pod := &corev1.Pod{}
+		require.NoError(t, c.Get(ctx, types.NamespacedName{Name: fmt.Sprintf("test-statefulset-%d", i), Namespace: "default"}, pod))
+		pod.Status.Phase = corev1.PodRunning
+		pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+		require.NoError(t, c.Status().Update(ctx, pod))
+		_, got = reconcile()
+	}
+	assert.Len(t, podNames(), 3)
+	assert.Nil(t, meta.FindStatusCondition(got.Status.Conditions, appsv1.ConditionSuspended))
+}
@@ -0,0 +1,251 @@
+/*
+Copyright 2024 bryant-rh.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	appsv1 "github.com/bryant-rh/my-statefulset/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	cloneControllerName = "mystatefulsetclone-controller"
+
+	// clonePollInterval 克隆进行中时重新检查 PVC 状态的间隔
+	clonePollInterval = 10 * time.Second
+)
+
+//+kubebuilder:rbac:groups=apps.mystatefulset.com,resources=mystatefulsetclones,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=apps.mystatefulset.com,resources=mystatefulsetclones/status,verbs=get;update;patch
+
+// MyStatefulsetCloneReconciler reconciles a MyStatefulsetClone object
+type MyStatefulsetCloneReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+}
+
+// Reconcile 创建克隆出的 MyStatefulset 并汇报每个序号的 PVC 克隆进度
+func (r *MyStatefulsetCloneReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+
+	var clone appsv1.MyStatefulsetClone
+	if err := r.Get(ctx, req.NamespacedName, &clone); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	// 完成或失败后不再处理
+	if clone.Status.Phase == appsv1.ClonePhaseCompleted || clone.Status.Phase == appsv1.ClonePhaseFailed {
+		return ctrl.Result{}, nil
+	}
+
+	status, result, err := r.reconcileClone(ctx, &clone)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if !reflect.DeepEqual(status, clone.Status) {
+		if status.Phase == appsv1.ClonePhaseFailed {
+			r.Recorder.Event(&clone, corev1.EventTypeWarning, "CloneFailed", status.Message)
+		}
+		clone.Status = status
+		if err := r.Status().Update(ctx, &clone); err != nil {
+			log.Error(err, "Failed to update MyStatefulsetClone status")
+			return ctrl.Result{}, err
+		}
+	}
+	return result, nil
+}
+
+// reconcileClone 返回克隆的新状态
+func (r *MyStatefulsetCloneReconciler) reconcileClone(ctx context.Context, clone *appsv1.MyStatefulsetClone) (appsv1.MyStatefulsetCloneStatus, ctrl.Result, error) {
+	sourceNamespace := clone.GetSourceNamespace()
+	failed := func(format string, args ...interface{}) appsv1.MyStatefulsetCloneStatus {
+		return appsv1.MyStatefulsetCloneStatus{Phase: appsv1.ClonePhaseFailed, Message: fmt.Sprintf(format, args...)}
+	}
+
+	if sourceNamespace == clone.Namespace && clone.Spec.Source.Name == clone.Spec.TargetName {
+		return failed("spec.targetName must differ from the source name"), ctrl.Result{}, nil
+	}
+	// PVC 的 dataSource 只能引用同一命名空间中的对象
+	if sourceNamespace != clone.Namespace && !clone.Spec.SkipData {
+		return failed("CSI volume cloning only works within a namespace; set spec.skipData to copy only the spec from namespace %s", sourceNamespace), ctrl.Result{}, nil
+	}
+
+	target := &appsv1.MyStatefulset{}
+	err := r.Get(ctx, types.NamespacedName{Name: clone.Spec.TargetName, Namespace: clone.Namespace}, target)
+	switch {
+	case err == nil:
+		owner := clone.Namespace + "/" + clone.Name
+		if target.Annotations[appsv1.CloneSourceAnnotation] != owner {
+			return failed("MyStatefulset %s already exists and was not created by this clone", clone.Spec.TargetName), ctrl.Result{}, nil
+		}
+	case errors.IsNotFound(err):
+		source := &appsv1.MyStatefulset{}
+		if err := r.Get(ctx, types.NamespacedName{Name: clone.Spec.Source.Name, Namespace: sourceNamespace}, source); err != nil {
+			if !errors.IsNotFound(err) {
+				return clone.Status, ctrl.Result{}, err
+			}
+			return appsv1.MyStatefulsetCloneStatus{
+				Phase:   appsv1.ClonePhasePending,
+				Message: fmt.Sprintf("source MyStatefulset %s/%s not found", sourceNamespace, clone.Spec.Source.Name),
+			}, ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+		}
+
+		target = clone.BuildTarget(source)
+		if !clone.Spec.SkipData {
+			missing, err := r.missingSourceClaims(ctx, source, target.GetReplicas())
+			if err != nil {
+				return clone.Status, ctrl.Result{}, err
+			}
+			if len(missing) > 0 {
+				return failed("source PersistentVolumeClaims not found: %s", strings.Join(missing, ", ")), ctrl.Result{}, nil
+			}
+		}
+		if err := r.ensureCloneService(ctx, clone, source, target); err != nil {
+			return clone.Status, ctrl.Result{}, err
+		}
+		if err := r.Create(ctx, target); err != nil {
+			return clone.Status, ctrl.Result{}, err
+		}
+		r.Recorder.Event(clone, corev1.EventTypeNormal, "CloneCreated",
+			fmt.Sprintf("Created MyStatefulset %s from %s/%s", target.Name, sourceNamespace, source.Name))
+	default:
+		return clone.Status, ctrl.Result{}, err
+	}
+
+	if clone.Spec.SkipData {
+		return appsv1.MyStatefulsetCloneStatus{
+			Phase:   appsv1.ClonePhaseCompleted,
+			Message: fmt.Sprintf("created MyStatefulset %s without cloning data", target.Name),
+		}, ctrl.Result{}, nil
+	}
+
+	ordinals, err := r.cloneProgress(ctx, target)
+	if err != nil {
+		return clone.Status, ctrl.Result{}, err
+	}
+	status := appsv1.MyStatefulsetCloneStatus{
+		Phase:    appsv1.ClonePhaseCompleted,
+		Message:  fmt.Sprintf("cloned %d ordinals into MyStatefulset %s", len(ordinals), target.Name),
+		Ordinals: ordinals,
+	}
+	cloned := 0
+	for _, ordinal := range ordinals {
+		if ordinal.Cloned {
+			cloned++
+		}
+	}
+	if cloned < len(ordinals) {
+		status.Phase = appsv1.ClonePhaseCloning
+		status.Message = fmt.Sprintf("%d/%d ordinals cloned into MyStatefulset %s", cloned, len(ordinals), target.Name)
+		return status, ctrl.Result{RequeueAfter: clonePollInterval}, nil
+	}
+	return status, ctrl.Result{}, nil
+}
+
+// missingSourceClaims 返回源对象中缺失的 PVC 名称
+func (r *MyStatefulsetCloneReconciler) missingSourceClaims(ctx context.Context, source *appsv1.MyStatefulset, replicas int32) ([]string, error) {
+	var missing []string
+	for ordinal := 0; ordinal < int(replicas); ordinal++ {
+		for _, name := range claimNamesForOrdinal(source, ordinal) {
+			err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: source.Namespace}, &corev1.PersistentVolumeClaim{})
+			if errors.IsNotFound(err) {
+				missing = append(missing, name)
+			} else if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return missing, nil
+}
+
+// ensureCloneService 创建新对象的 headless service，端口从源对象的 service 复制
+func (r *MyStatefulsetCloneReconciler) ensureCloneService(ctx context.Context, clone *appsv1.MyStatefulsetClone,
+	source, target *appsv1.MyStatefulset) error {
+	key := types.NamespacedName{Name: target.Spec.ServiceName, Namespace: target.Namespace}
+	if err := r.Get(ctx, key, &corev1.Service{}); err == nil || !errors.IsNotFound(err) {
+		return err
+	}
+
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        key.Name,
+			Namespace:   key.Namespace,
+			Labels:      target.Labels,
+			Annotations: map[string]string{appsv1.CloneSourceAnnotation: clone.Namespace + "/" + clone.Name},
+		},
+		Spec: corev1.ServiceSpec{
+			ClusterIP: corev1.ClusterIPNone,
+			Selector:  target.Spec.Selector.MatchLabels,
+		},
+	}
+	sourceService := &corev1.Service{}
+	err := r.Get(ctx, types.NamespacedName{Name: source.Spec.ServiceName, Namespace: source.Namespace}, sourceService)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	for _, port := range sourceService.Spec.Ports {
+		port.NodePort = 0
+		service.Spec.Ports = append(service.Spec.Ports, port)
+	}
+	return r.Create(ctx, service)
+}
+
+// cloneProgress 统计新对象每个序号已经 Bound 的 PVC
+func (r *MyStatefulsetCloneReconciler) cloneProgress(ctx context.Context, target *appsv1.MyStatefulset) ([]appsv1.CloneOrdinalStatus, error) {
+	var ordinals []appsv1.CloneOrdinalStatus
+	for ordinal := 0; ordinal < int(target.GetReplicas()); ordinal++ {
+		names := claimNamesForOrdinal(target, ordinal)
+		progress := appsv1.CloneOrdinalStatus{Ordinal: int32(ordinal), Claims: int32(len(names))}
+		for _, name := range names {
+			pvc := &corev1.PersistentVolumeClaim{}
+			err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: target.Namespace}, pvc)
+			if err != nil && !errors.IsNotFound(err) {
+				return nil, err
+			}
+			if err == nil && pvc.Status.Phase == corev1.ClaimBound {
+				progress.BoundClaims++
+			}
+		}
+		progress.Cloned = progress.BoundClaims == progress.Claims
+		ordinals = append(ordinals, progress)
+	}
+	return ordinals, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *MyStatefulsetCloneReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.Recorder = mgr.GetEventRecorderFor(cloneControllerName)
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&appsv1.MyStatefulsetClone{}).
+		Complete(r)
+}
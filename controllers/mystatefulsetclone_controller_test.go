@@ -0,0 +1,254 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "github.com/bryant-rh/my-statefulset/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newCloneSource() *appsv1.MyStatefulset {
+	return &appsv1.MyStatefulset{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default", UID: "source-uid", Labels: map[string]string{"app": "db"}},
+		Spec: appsv1.MyStatefulsetSpec{
+			Replicas:    pointer.Int32(2),
+			ServiceName: "db-svc",
+			Selector:    &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+			Template: appsv1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "db"}},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "db", Image: "postgres:15"}},
+				},
+			},
+			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{
+				{ObjectMeta: metav1.ObjectMeta{Name: "data"}},
+			},
+			VolumeClaimDataSources: []appsv1.VolumeClaimDataSource{
+				{ClaimTemplate: "data", VolumeSnapshotName: "db-snap-{{ordinal}}"},
+			},
+		},
+	}
+}
+
+func TestMyStatefulsetCloneReconciler_Reconcile(t *testing.T) {
+	s := runtime.NewScheme()
+	_ = appsv1.AddToScheme(s)
+	_ = corev1.AddToScheme(s)
+
+	clone := &appsv1.MyStatefulsetClone{
+		ObjectMeta: metav1.ObjectMeta{Name: "db-staging", Namespace: "default"},
+		Spec: appsv1.MyStatefulsetCloneSpec{
+			Source:     appsv1.CloneSource{Name: "db"},
+			TargetName: "db-staging",
+		},
+	}
+	sourceService := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "db-svc", Namespace: "default"},
+		Spec: corev1.ServiceSpec{
+			ClusterIP: corev1.ClusterIPNone,
+			Ports:     []corev1.ServicePort{{Name: "pg", Port: 5432}},
+		},
+	}
+	pvc := func(name string) *corev1.PersistentVolumeClaim {
+		return &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}}
+	}
+
+	c := fake.NewClientBuilder().WithScheme(s).WithObjects(
+		clone, newCloneSource(), sourceService, pvc("data-db-0"), pvc("data-db-1"),
+	).Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &MyStatefulsetCloneReconciler{Client: c, Scheme: s, Recorder: recorder}
+	ctx := context.Background()
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "db-staging", Namespace: "default"}}
+
+	result, err := r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, clonePollInterval, result.RequeueAfter)
+	assert.Contains(t, <-recorder.Events, "CloneCreated ")
+
+	// 新对象复制模板和 PVC 模板，改写 serviceName，并从源对象的 PVC 克隆数据
+	target := &appsv1.MyStatefulset{}
+	require.NoError(t, c.Get(ctx, types.NamespacedName{Name: "db-staging", Namespace: "default"}, target))
+	assert.Equal(t, "db-staging", target.Spec.ServiceName)
+	assert.Equal(t, int32(2), target.GetReplicas())
+	assert.Equal(t, "default/db-staging", target.Annotations[appsv1.CloneSourceAnnotation])
+	assert.Equal(t, "db-staging", target.Spec.Selector.MatchLabels[appsv1.CloneLabel])
+	assert.Equal(t, "db-staging", target.Spec.Template.Labels[appsv1.CloneLabel])
+
+	// 源对象的选择器（以及按同样标签选择的 Service）不会选中新 Pod，新对象也不会选中源对象的 Pod
+	sourceSelector, err := metav1.LabelSelectorAsSelector(newCloneSource().Spec.Selector)
+	require.NoError(t, err)
+	assert.False(t, sourceSelector.Matches(labels.Set(target.Spec.Template.Labels)))
+	targetSelector, err := metav1.LabelSelectorAsSelector(target.Spec.Selector)
+	require.NoError(t, err)
+	assert.True(t, targetSelector.Matches(labels.Set(target.Spec.Template.Labels)))
+	assert.False(t, targetSelector.Matches(labels.Set(newCloneSource().Spec.Template.Labels)))
+	assert.Equal(t, []appsv1.VolumeClaimDataSource{{ClaimTemplate: "data", SourceSet: "db"}}, target.Spec.VolumeClaimDataSources)
+
+	service := &corev1.Service{}
+	require.NoError(t, c.Get(ctx, types.NamespacedName{Name: "db-staging", Namespace: "default"}, service))
+	assert.Equal(t, corev1.ClusterIPNone, service.Spec.ClusterIP)
+	assert.Equal(t, target.Spec.Selector.MatchLabels, service.Spec.Selector)
+	require.Len(t, service.Spec.Ports, 1)
+	assert.Equal(t, int32(5432), service.Spec.Ports[0].Port)
+
+	got := &appsv1.MyStatefulsetClone{}
+	require.NoError(t, c.Get(ctx, req.NamespacedName, got))
+	assert.Equal(t, appsv1.ClonePhaseCloning, got.Status.Phase)
+	assert.Equal(t, []appsv1.CloneOrdinalStatus{
+		{Ordinal: 0, Claims: 1},
+		{Ordinal: 1, Claims: 1},
+	}, got.Status.Ordinals)
+
+	// 序号 0 的 PVC 已 Bound
+	bound := pvc("data-db-staging-0")
+	bound.Status.Phase = corev1.ClaimBound
+	require.NoError(t, c.Create(ctx, bound))
+	_, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	require.NoError(t, c.Get(ctx, req.NamespacedName, got))
+	assert.Equal(t, appsv1.ClonePhaseCloning, got.Status.Phase)
+	assert.Equal(t, "1/2 ordinals cloned into MyStatefulset db-staging", got.Status.Message)
+	assert.True(t, got.Status.Ordinals[0].Cloned)
+	assert.False(t, got.Status.Ordinals[1].Cloned)
+
+	// 全部 Bound 后完成
+	bound = pvc("data-db-staging-1")
+	bound.Status.Phase = corev1.ClaimBound
+	require.NoError(t, c.Create(ctx, bound))
+	result, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.Zero(t, result.RequeueAfter)
+	require.NoError(t, c.Get(ctx, req.NamespacedName, got))
+	assert.Equal(t, appsv1.ClonePhaseCompleted, got.Status.Phase)
+}
+
+func TestMyStatefulsetCloneReconciler_Failures(t *testing.T) {
+	s := runtime.NewScheme()
+	_ = appsv1.AddToScheme(s)
+	_ = corev1.AddToScheme(s)
+
+	tests := []struct {
+		name    string
+		spec    appsv1.MyStatefulsetCloneSpec
+		objects []client.Object
+		phase   appsv1.ClonePhase
+		message string
+	}{
+		{
+			name:    "cross namespace requires skipData",
+			spec:    appsv1.MyStatefulsetCloneSpec{Source: appsv1.CloneSource{Namespace: "prod", Name: "db"}, TargetName: "db"},
+			phase:   appsv1.ClonePhaseFailed,
+			message: "CSI volume cloning only works within a namespace; set spec.skipData to copy only the spec from namespace prod",
+		},
+		{
+			name:    "target name equals source",
+			spec:    appsv1.MyStatefulsetCloneSpec{Source: appsv1.CloneSource{Name: "db"}, TargetName: "db"},
+			phase:   appsv1.ClonePhaseFailed,
+			message: "spec.targetName must differ from the source name",
+		},
+		{
+			name:    "source not found",
+			spec:    appsv1.MyStatefulsetCloneSpec{Source: appsv1.CloneSource{Name: "db"}, TargetName: "db-staging"},
+			phase:   appsv1.ClonePhasePending,
+			message: "source MyStatefulset default/db not found",
+		},
+		{
+			name:    "missing source claims",
+			spec:    appsv1.MyStatefulsetCloneSpec{Source: appsv1.CloneSource{Name: "db"}, TargetName: "db-staging"},
+			objects: []client.Object{newCloneSource()},
+			phase:   appsv1.ClonePhaseFailed,
+			message: "source PersistentVolumeClaims not found: data-db-0, data-db-1",
+		},
+		{
+			name: "target exists",
+			spec: appsv1.MyStatefulsetCloneSpec{Source: appsv1.CloneSource{Name: "db"}, TargetName: "db-staging"},
+			objects: []client.Object{&appsv1.MyStatefulset{
+				ObjectMeta: metav1.ObjectMeta{Name: "db-staging", Namespace: "default"},
+			}},
+			phase:   appsv1.ClonePhaseFailed,
+			message: "MyStatefulset db-staging already exists and was not created by this clone",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clone := &appsv1.MyStatefulsetClone{
+				ObjectMeta: metav1.ObjectMeta{Name: "db-staging", Namespace: "default"},
+				Spec:       tt.spec,
+			}
+			c := fake.NewClientBuilder().WithScheme(s).WithObjects(append(tt.objects, clone)...).Build()
+			r := &MyStatefulsetCloneReconciler{Client: c, Scheme: s, Recorder: record.NewFakeRecorder(10)}
+			req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "db-staging", Namespace: "default"}}
+
+			_, err := r.Reconcile(context.Background(), req)
+			require.NoError(t, err)
+
+			got := &appsv1.MyStatefulsetClone{}
+			require.NoError(t, c.Get(context.Background(), req.NamespacedName, got))
+			assert.Equal(t, tt.phase, got.Status.Phase)
+			assert.Equal(t, tt.message, got.Status.Message)
+		})
+	}
+}
+
+func TestMyStatefulsetCloneReconciler_SkipData(t *testing.T) {
+	s := runtime.NewScheme()
+	_ = appsv1.AddToScheme(s)
+	_ = corev1.AddToScheme(s)
+
+	source := newCloneSource()
+	source.Namespace = "prod"
+	clone := &appsv1.MyStatefulsetClone{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "staging"},
+		Spec: appsv1.MyStatefulsetCloneSpec{
+			Source:     appsv1.CloneSource{Namespace: "prod", Name: "db"},
+			TargetName: "db",
+			Replicas:   pointer.Int32(1),
+			SkipData:   true,
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(s).WithObjects(source, clone).Build()
+	r := &MyStatefulsetCloneReconciler{Client: c, Scheme: s, Recorder: record.NewFakeRecorder(10)}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "db", Namespace: "staging"}}
+
+	_, err := r.Reconcile(context.Background(), req)
+	require.NoError(t, err)
+
+	target := &appsv1.MyStatefulset{}
+	require.NoError(t, c.Get(context.Background(), types.NamespacedName{Name: "db", Namespace: "staging"}, target))
+	assert.Equal(t, int32(1), target.GetReplicas())
+	assert.Empty(t, target.Spec.VolumeClaimDataSources)
+
+	got := &appsv1.MyStatefulsetClone{}
+	require.NoError(t, c.Get(context.Background(), req.NamespacedName, got))
+	assert.Equal(t, appsv1.ClonePhaseCompleted, got.Status.Phase)
+	assert.Empty(t, got.Status.Ordinals)
+}
+
+func TestOwnedPods(t *testing.T) {
+	ms := &appsv1.MyStatefulset{ObjectMeta: metav1.ObjectMeta{Name: "test-statefulset", UID: "test-uid"}}
+	other := createPodWithOwner("test-statefulset-clone-0", "other-uid")
+	pods := []corev1.Pod{
+		*createPodWithOwner("test-statefulset-0", "test-uid"),
+		*other,
+		{ObjectMeta: metav1.ObjectMeta{Name: "test-statefulset-1"}},
+	}
+
+	owned := ownedPods(pods, ms)
+	require.Len(t, owned, 2)
+	assert.Equal(t, "test-statefulset-0", owned[0].Name)
+	assert.Equal(t, "test-statefulset-1", owned[1].Name)
+}
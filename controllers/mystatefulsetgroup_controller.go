@@ -0,0 +1,379 @@
+/*
+Copyright 2024 bryant-rh.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"reflect"
+	"sort"
+	"time"
+
+	appsv1 "github.com/bryant-rh/my-statefulset/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	groupControllerName = "mystatefulsetgroup-controller"
+
+	// groupPollInterval 滚动进行中时重新检查成员状态的间隔，成员变化时会通过 watch 立即调谐
+	groupPollInterval = 10 * time.Second
+)
+
+//+kubebuilder:rbac:groups=apps.mystatefulset.com,resources=mystatefulsetgroups,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=apps.mystatefulset.com,resources=mystatefulsetgroups/status,verbs=get;update;patch
+
+// MyStatefulsetGroupReconciler reconciles a MyStatefulsetGroup object
+type MyStatefulsetGroupReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+}
+
+// Reconcile 将 templatePatch 逐个应用到成员，并汇总每个成员的滚动状态
+func (r *MyStatefulsetGroupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+
+	var group appsv1.MyStatefulsetGroup
+	if err := r.Get(ctx, req.NamespacedName, &group); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	status, err := r.reconcileGroup(ctx, &group)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if !reflect.DeepEqual(status, group.Status) {
+		if status.Phase != group.Status.Phase {
+			switch status.Phase {
+			case appsv1.GroupPhaseCompleted:
+				r.Recorder.Event(&group, corev1.EventTypeNormal, "RolloutCompleted", status.Message)
+			case appsv1.GroupPhaseAborted:
+				r.Recorder.Event(&group, corev1.EventTypeNormal, "RolloutAborted", status.Message)
+			case appsv1.GroupPhaseFailed:
+				r.Recorder.Event(&group, corev1.EventTypeWarning, "RolloutFailed", status.Message)
+			}
+		}
+		group.Status = status
+		if err := r.Status().Update(ctx, &group); err != nil {
+			log.Error(err, "Failed to update MyStatefulsetGroup status")
+			return ctrl.Result{}, err
+		}
+	}
+
+	if status.Phase == appsv1.GroupPhaseProgressing || status.Phase == appsv1.GroupPhaseAborting {
+		return ctrl.Result{RequeueAfter: groupPollInterval}, nil
+	}
+	return ctrl.Result{}, nil
+}
+
+// reconcileGroup 推进滚动并返回组的新状态
+func (r *MyStatefulsetGroupReconciler) reconcileGroup(ctx context.Context, group *appsv1.MyStatefulsetGroup) (appsv1.MyStatefulsetGroupStatus, error) {
+	status := appsv1.MyStatefulsetGroupStatus{
+		ObservedGeneration: group.Generation,
+		Revision:           groupRevision(group.Spec.TemplatePatch.Raw),
+	}
+	failed := func(format string, args ...interface{}) appsv1.MyStatefulsetGroupStatus {
+		status.Phase = appsv1.GroupPhaseFailed
+		status.Message = fmt.Sprintf(format, args...)
+		return status
+	}
+
+	if len(group.Spec.TemplatePatch.Raw) == 0 {
+		return failed("spec.templatePatch is required"), nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(group.Spec.Selector)
+	if err != nil {
+		return failed("invalid selector: %v", err), nil
+	}
+	if selector.Empty() {
+		return failed("spec.selector must not be empty"), nil
+	}
+
+	memberList := &appsv1.MyStatefulsetList{}
+	if err := r.List(ctx, memberList, client.InNamespace(group.Namespace),
+		client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return group.Status, err
+	}
+	members := memberList.Items
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].Name < members[j].Name
+	})
+
+	states := make([]appsv1.MemberRolloutState, len(members))
+	inFlight := 0
+	for i := range members {
+		states[i] = memberState(&members[i], status.Revision)
+		if states[i] == appsv1.MemberUpdating || states[i] == appsv1.MemberReverting {
+			inFlight++
+		}
+	}
+
+	maxConcurrent := group.GetMaxConcurrentMembers()
+	switch {
+	case group.Spec.Abort:
+		// 按更新的相反顺序恢复已经应用了补丁的成员
+		for i := len(members) - 1; i >= 0 && inFlight < maxConcurrent; i-- {
+			if states[i] != appsv1.MemberUpdating && states[i] != appsv1.MemberUpdated {
+				continue
+			}
+			if err := r.revertMember(ctx, group, &members[i], status.Revision); err != nil {
+				return group.Status, err
+			}
+			states[i] = appsv1.MemberReverting
+			inFlight++
+		}
+	case group.Spec.Paused:
+	default:
+		for i := 0; i < len(members) && inFlight < maxConcurrent; i++ {
+			if states[i] != appsv1.MemberPending && states[i] != appsv1.MemberReverted {
+				continue
+			}
+			if err := r.patchMember(ctx, group, &members[i], status.Revision); err != nil {
+				if isPatchError(err) {
+					return failed("failed to apply templatePatch to %s: %v", members[i].Name, err), nil
+				}
+				return group.Status, err
+			}
+			states[i] = appsv1.MemberUpdating
+			inFlight++
+		}
+	}
+
+	var updated, remaining int32
+	for i := range members {
+		member := &members[i]
+		status.Members = append(status.Members, appsv1.GroupMemberStatus{
+			Name:              member.Name,
+			State:             states[i],
+			Replicas:          member.GetReplicas(),
+			UpdatedReplicas:   member.Status.UpdatedReplicas,
+			AvailableReplicas: member.Status.AvailableReplicas,
+		})
+		switch states[i] {
+		case appsv1.MemberUpdated:
+			updated++
+			remaining++
+		case appsv1.MemberUpdating, appsv1.MemberReverting:
+			remaining++
+		}
+	}
+	status.TotalMembers = int32(len(members))
+	status.UpdatedMembers = updated
+
+	switch {
+	case group.Spec.Abort && remaining > 0:
+		status.Phase = appsv1.GroupPhaseAborting
+		status.Message = fmt.Sprintf("%d members left to revert", remaining)
+	case group.Spec.Abort:
+		status.Phase = appsv1.GroupPhaseAborted
+		status.Message = "rollout aborted, patched members reverted"
+	case updated == status.TotalMembers:
+		status.Phase = appsv1.GroupPhaseCompleted
+		status.Message = fmt.Sprintf("%d/%d members updated", updated, status.TotalMembers)
+	case group.Spec.Paused:
+		status.Phase = appsv1.GroupPhasePaused
+		status.Message = fmt.Sprintf("paused with %d/%d members updated", updated, status.TotalMembers)
+	default:
+		status.Phase = appsv1.GroupPhaseProgressing
+		status.Message = fmt.Sprintf("%d/%d members updated", updated, status.TotalMembers)
+	}
+	return status, nil
+}
+
+// patchError 表示补丁本身无法应用，重试不会成功
+type patchError struct {
+	err error
+}
+
+func (e *patchError) Error() string {
+	return e.err.Error()
+}
+
+func isPatchError(err error) bool {
+	_, ok := err.(*patchError)
+	return ok
+}
+
+// patchMember 将补丁应用到成员的 Pod 模板，并在注解中记录修订和原来的模板。
+// 滚动尚未完成时修改 templatePatch，成员上保留的仍是第一次打补丁前的模板，中止时恢复到该模板
+func (r *MyStatefulsetGroupReconciler) patchMember(ctx context.Context, group *appsv1.MyStatefulsetGroup,
+	member *appsv1.MyStatefulset, revision string) error {
+	previous, err := json.Marshal(member.Spec.Template)
+	if err != nil {
+		return err
+	}
+	template, err := patchTemplate(&member.Spec.Template, group.Spec.TemplatePatch.Raw)
+	if err != nil {
+		return &patchError{err: err}
+	}
+
+	member.Spec.Template = *template
+	if member.Annotations == nil {
+		member.Annotations = map[string]string{}
+	}
+	member.Annotations[appsv1.GroupRevisionAnnotation] = revision
+	if _, ok := member.Annotations[appsv1.GroupPreviousTemplateAnnotation]; !ok ||
+		group.Status.Phase == appsv1.GroupPhaseCompleted {
+		member.Annotations[appsv1.GroupPreviousTemplateAnnotation] = string(previous)
+	}
+	delete(member.Annotations, appsv1.GroupRevertedAnnotation)
+	if err := r.Update(ctx, member); err != nil {
+		return fmt.Errorf("failed to patch member %s: %w", member.Name, err)
+	}
+	r.Recorder.Event(group, corev1.EventTypeNormal, "MemberPatched",
+		fmt.Sprintf("Applied templatePatch revision %s to %s", revision, member.Name))
+	return nil
+}
+
+// revertMember 恢复成员应用补丁前的 Pod 模板
+func (r *MyStatefulsetGroupReconciler) revertMember(ctx context.Context, group *appsv1.MyStatefulsetGroup,
+	member *appsv1.MyStatefulset, revision string) error {
+	if previous, ok := member.Annotations[appsv1.GroupPreviousTemplateAnnotation]; ok {
+		var template appsv1.PodTemplateSpec
+		if err := json.Unmarshal([]byte(previous), &template); err != nil {
+			return fmt.Errorf("failed to decode previous template of %s: %w", member.Name, err)
+		}
+		member.Spec.Template = template
+	} else {
+		// 注解被删除时无法恢复，只标记为已恢复，避免中止一直无法完成
+		r.Recorder.Event(group, corev1.EventTypeWarning, "RevertSkipped",
+			fmt.Sprintf("%s has no previous template to restore", member.Name))
+	}
+
+	delete(member.Annotations, appsv1.GroupRevisionAnnotation)
+	delete(member.Annotations, appsv1.GroupPreviousTemplateAnnotation)
+	member.Annotations[appsv1.GroupRevertedAnnotation] = revision
+	if err := r.Update(ctx, member); err != nil {
+		return fmt.Errorf("failed to revert member %s: %w", member.Name, err)
+	}
+	r.Recorder.Event(group, corev1.EventTypeNormal, "MemberReverted",
+		fmt.Sprintf("Restored the pod template of %s", member.Name))
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *MyStatefulsetGroupReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.Recorder = mgr.GetEventRecorderFor(groupControllerName)
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&appsv1.MyStatefulsetGroup{}).
+		// 成员变化（例如变为 Available）时调谐选中它的组
+		Watches(&source.Kind{Type: &appsv1.MyStatefulset{}},
+			handler.EnqueueRequestsFromMapFunc(r.groupsForMember)).
+		Complete(r)
+}
+
+// groupsForMember 返回选中该 MyStatefulset 的组
+func (r *MyStatefulsetGroupReconciler) groupsForMember(obj client.Object) []reconcile.Request {
+	groups := &appsv1.MyStatefulsetGroupList{}
+	if err := r.List(context.Background(), groups, client.InNamespace(obj.GetNamespace())); err != nil {
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, group := range groups.Items {
+		selector, err := metav1.LabelSelectorAsSelector(group.Spec.Selector)
+		if err != nil || selector.Empty() || !selector.Matches(labels.Set(obj.GetLabels())) {
+			continue
+		}
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: group.Name, Namespace: group.Namespace},
+		})
+	}
+	return requests
+}
+
+// memberState 根据成员上的注解和状态判断其滚动状态
+func memberState(member *appsv1.MyStatefulset, revision string) appsv1.MemberRolloutState {
+	switch {
+	case member.Annotations[appsv1.GroupRevisionAnnotation] == revision:
+		if memberRolledOut(member) {
+			return appsv1.MemberUpdated
+		}
+		return appsv1.MemberUpdating
+	case member.Annotations[appsv1.GroupRevertedAnnotation] == revision:
+		if memberRolledOut(member) {
+			return appsv1.MemberReverted
+		}
+		return appsv1.MemberReverting
+	default:
+		return appsv1.MemberPending
+	}
+}
+
+// memberRolledOut 判断成员当前的 generation 已经被观察到，partition 及以上的副本都已更新并且 Available
+func memberRolledOut(member *appsv1.MyStatefulset) bool {
+	condition := meta.FindStatusCondition(member.Status.Conditions, appsv1.ConditionAvailable)
+	return condition != nil && condition.Status == metav1.ConditionTrue &&
+		condition.ObservedGeneration == member.Generation &&
+		member.Status.ObservedGeneration == member.Generation &&
+		member.Status.UpdatedReplicas >= partitionedReplicas(member)
+}
+
+// partitionedReplicas 返回滚动更新会更新的副本数，partition 以下的副本保持旧版本
+func partitionedReplicas(member *appsv1.MyStatefulset) int32 {
+	replicas := member.GetReplicas()
+	if partition := rollingUpdatePartition(member); partition > 0 {
+		if partition >= replicas {
+			return 0
+		}
+		return replicas - partition
+	}
+	return replicas
+}
+
+// patchTemplate 以 strategic merge patch 的方式将补丁应用到 Pod 模板，容器等列表按名称合并
+func patchTemplate(template *appsv1.PodTemplateSpec, patch []byte) (*appsv1.PodTemplateSpec, error) {
+	original, err := json.Marshal(template)
+	if err != nil {
+		return nil, err
+	}
+	patched, err := strategicpatch.StrategicMergePatch(original, patch, corev1.PodTemplateSpec{})
+	if err != nil {
+		return nil, err
+	}
+	result := &appsv1.PodTemplateSpec{}
+	if err := json.Unmarshal(patched, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// groupRevision 返回补丁的哈希，用于识别一次滚动
+func groupRevision(patch []byte) string {
+	hasher := fnv.New32a()
+	hasher.Write(patch)
+	return rand.SafeEncodeString(fmt.Sprint(hasher.Sum32()))
+}
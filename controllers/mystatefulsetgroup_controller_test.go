@@ -0,0 +1,341 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	appsv1 "github.com/bryant-rh/my-statefulset/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const shardImagePatch = `{"spec":{"containers":[{"name":"db","image":"postgres:16"}]}}`
+
+func newShard(name string, groupLabel string) *appsv1.MyStatefulset {
+	return &appsv1.MyStatefulset{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{"shards": groupLabel}},
+		Spec: appsv1.MyStatefulsetSpec{
+			Replicas: pointer.Int32(1),
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": name}},
+			Template: appsv1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": name}},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{Name: "db", Image: "postgres:15"},
+						{Name: "exporter", Image: "exporter:1"},
+					},
+				},
+			},
+		},
+	}
+}
+
+func newShardGroup(patch string, maxConcurrent int32) *appsv1.MyStatefulsetGroup {
+	return &appsv1.MyStatefulsetGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "shards", Namespace: "default"},
+		Spec: appsv1.MyStatefulsetGroupSpec{
+			Selector:             &metav1.LabelSelector{MatchLabels: map[string]string{"shards": "db"}},
+			TemplatePatch:        runtime.RawExtension{Raw: []byte(patch)},
+			MaxConcurrentMembers: pointer.Int32(maxConcurrent),
+		},
+	}
+}
+
+// markRolledOut 模拟成员控制器完成滚动：当前 generation 的所有副本都已更新并且 Available
+func markRolledOut(t *testing.T, c client.Client, name string) {
+	ms := &appsv1.MyStatefulset{}
+	require.NoError(t, c.Get(context.Background(), types.NamespacedName{Name: name, Namespace: "default"}, ms))
+	ms.Status.ObservedGeneration = ms.Generation
+	ms.Status.UpdatedReplicas = ms.GetReplicas()
+	ms.Status.AvailableReplicas = ms.GetReplicas()
+	meta.SetStatusCondition(&ms.Status.Conditions, metav1.Condition{
+		Type: appsv1.ConditionAvailable, Status: metav1.ConditionTrue, Reason: "MinimumReplicasAvailable",
+		ObservedGeneration: ms.Generation,
+	})
+	require.NoError(t, c.Status().Update(context.Background(), ms))
+}
+
+func memberStates(group *appsv1.MyStatefulsetGroup) []appsv1.MemberRolloutState {
+	var states []appsv1.MemberRolloutState
+	for _, member := range group.Status.Members {
+		states = append(states, member.State)
+	}
+	return states
+}
+
+func TestMyStatefulsetGroupReconciler_Reconcile(t *testing.T) {
+	s := runtime.NewScheme()
+	_ = appsv1.AddToScheme(s)
+
+	c := fake.NewClientBuilder().WithScheme(s).WithObjects(
+		newShardGroup(shardImagePatch, 1),
+		newShard("shard-0", "db"), newShard("shard-1", "db"), newShard("shard-2", "db"), newShard("other", "cache"),
+	).Build()
+	recorder := record.NewFakeRecorder(20)
+	r := &MyStatefulsetGroupReconciler{Client: c, Scheme: s, Recorder: recorder}
+	ctx := context.Background()
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "shards", Namespace: "default"}}
+
+	reconcile := func() *appsv1.MyStatefulsetGroup {
+		_, err := r.Reconcile(ctx, req)
+		require.NoError(t, err)
+		group := &appsv1.MyStatefulsetGroup{}
+		require.NoError(t, c.Get(ctx, req.NamespacedName, group))
+		return group
+	}
+	image := func(name string) string {
+		ms := &appsv1.MyStatefulset{}
+		require.NoError(t, c.Get(ctx, types.NamespacedName{Name: name, Namespace: "default"}, ms))
+		return ms.Spec.Template.Spec.Containers[0].Image
+	}
+
+	// 每次只更新一个成员
+	group := reconcile()
+	assert.Contains(t, <-recorder.Events, "MemberPatched ")
+	assert.Equal(t, appsv1.GroupPhaseProgressing, group.Status.Phase)
+	assert.Equal(t, int32(3), group.Status.TotalMembers)
+	assert.Equal(t, []appsv1.MemberRolloutState{appsv1.MemberUpdating, appsv1.MemberPending, appsv1.MemberPending},
+		memberStates(group))
+	assert.Equal(t, "postgres:16", image("shard-0"))
+	assert.Equal(t, "postgres:15", image("shard-1"))
+	assert.Equal(t, "postgres:15", image("other"))
+
+	// 成员 Available 之前不更新下一个
+	group = reconcile()
+	assert.Equal(t, "postgres:15", image("shard-1"))
+
+	markRolledOut(t, c, "shard-0")
+	group = reconcile()
+	assert.Contains(t, <-recorder.Events, "MemberPatched ")
+	assert.Equal(t, []appsv1.MemberRolloutState{appsv1.MemberUpdated, appsv1.MemberUpdating, appsv1.MemberPending},
+		memberStates(group))
+	assert.Equal(t, int32(1), group.Status.UpdatedMembers)
+
+	// 暂停后不再更新新的成员
+	group.Spec.Paused = true
+	require.NoError(t, c.Update(ctx, group))
+	markRolledOut(t, c, "shard-1")
+	group = reconcile()
+	assert.Equal(t, appsv1.GroupPhasePaused, group.Status.Phase)
+	assert.Equal(t, "postgres:15", image("shard-2"))
+
+	// 中止后按相反顺序恢复
+	group.Spec.Abort = true
+	require.NoError(t, c.Update(ctx, group))
+	group = reconcile()
+	assert.Contains(t, <-recorder.Events, "MemberReverted ")
+	assert.Equal(t, appsv1.GroupPhaseAborting, group.Status.Phase)
+	assert.Equal(t, []appsv1.MemberRolloutState{appsv1.MemberUpdated, appsv1.MemberReverting, appsv1.MemberPending},
+		memberStates(group))
+	assert.Equal(t, "postgres:15", image("shard-1"))
+	assert.Equal(t, "postgres:16", image("shard-0"))
+
+	markRolledOut(t, c, "shard-1")
+	group = reconcile()
+	assert.Contains(t, <-recorder.Events, "MemberReverted ")
+	assert.Equal(t, "postgres:15", image("shard-0"))
+
+	markRolledOut(t, c, "shard-0")
+	group = reconcile()
+	assert.Contains(t, <-recorder.Events, "RolloutAborted ")
+	assert.Equal(t, appsv1.GroupPhaseAborted, group.Status.Phase)
+	assert.Equal(t, []appsv1.MemberRolloutState{appsv1.MemberReverted, appsv1.MemberReverted, appsv1.MemberPending},
+		memberStates(group))
+
+	shard := &appsv1.MyStatefulset{}
+	require.NoError(t, c.Get(ctx, types.NamespacedName{Name: "shard-0", Namespace: "default"}, shard))
+	assert.NotContains(t, shard.Annotations, appsv1.GroupRevisionAnnotation)
+	assert.NotContains(t, shard.Annotations, appsv1.GroupPreviousTemplateAnnotation)
+	assert.Equal(t, "exporter:1", shard.Spec.Template.Spec.Containers[1].Image)
+}
+
+func TestMyStatefulsetGroupReconciler_Completed(t *testing.T) {
+	s := runtime.NewScheme()
+	_ = appsv1.AddToScheme(s)
+
+	objects := []client.Object{newShardGroup(shardImagePatch, 2)}
+	for i := 0; i < 3; i++ {
+		objects = append(objects, newShard(fmt.Sprintf("shard-%d", i), "db"))
+	}
+	c := fake.NewClientBuilder().WithScheme(s).WithObjects(objects...).Build()
+	recorder := record.NewFakeRecorder(20)
+	r := &MyStatefulsetGroupReconciler{Client: c, Scheme: s, Recorder: recorder}
+	ctx := context.Background()
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "shards", Namespace: "default"}}
+
+	result, err := r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, groupPollInterval, result.RequeueAfter)
+	group := &appsv1.MyStatefulsetGroup{}
+	require.NoError(t, c.Get(ctx, req.NamespacedName, group))
+	assert.Equal(t, []appsv1.MemberRolloutState{appsv1.MemberUpdating, appsv1.MemberUpdating, appsv1.MemberPending},
+		memberStates(group))
+
+	for i := 0; i < 3; i++ {
+		markRolledOut(t, c, fmt.Sprintf("shard-%d", i))
+		_, err = r.Reconcile(ctx, req)
+		require.NoError(t, err)
+	}
+	require.NoError(t, c.Get(ctx, req.NamespacedName, group))
+	assert.Equal(t, appsv1.GroupPhaseCompleted, group.Status.Phase)
+	assert.Equal(t, int32(3), group.Status.UpdatedMembers)
+	assert.Equal(t, "3/3 members updated", group.Status.Message)
+}
+
+func TestMyStatefulsetGroupReconciler_InvalidPatch(t *testing.T) {
+	s := runtime.NewScheme()
+	_ = appsv1.AddToScheme(s)
+
+	c := fake.NewClientBuilder().WithScheme(s).WithObjects(
+		newShardGroup(`{"spec":{"containers":"db"}}`, 1), newShard("shard-0", "db"),
+	).Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &MyStatefulsetGroupReconciler{Client: c, Scheme: s, Recorder: recorder}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "shards", Namespace: "default"}}
+
+	result, err := r.Reconcile(context.Background(), req)
+	require.NoError(t, err)
+	assert.Zero(t, result.RequeueAfter)
+	assert.Contains(t, <-recorder.Events, "RolloutFailed ")
+
+	group := &appsv1.MyStatefulsetGroup{}
+	require.NoError(t, c.Get(context.Background(), req.NamespacedName, group))
+	assert.Equal(t, appsv1.GroupPhaseFailed, group.Status.Phase)
+
+	shard := &appsv1.MyStatefulset{}
+	require.NoError(t, c.Get(context.Background(), types.NamespacedName{Name: "shard-0", Namespace: "default"}, shard))
+	assert.Equal(t, "postgres:15", shard.Spec.Template.Spec.Containers[0].Image)
+}
+
+func TestMyStatefulsetGroupReconciler_PatchChangedMidRollout(t *testing.T) {
+	s := runtime.NewScheme()
+	_ = appsv1.AddToScheme(s)
+
+	c := fake.NewClientBuilder().WithScheme(s).WithObjects(
+		newShardGroup(shardImagePatch, 1), newShard("shard-0", "db"),
+	).Build()
+	r := &MyStatefulsetGroupReconciler{Client: c, Scheme: s, Recorder: record.NewFakeRecorder(20)}
+	ctx := context.Background()
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "shards", Namespace: "default"}}
+	reconcile := func() *appsv1.MyStatefulsetGroup {
+		_, err := r.Reconcile(ctx, req)
+		require.NoError(t, err)
+		group := &appsv1.MyStatefulsetGroup{}
+		require.NoError(t, c.Get(ctx, req.NamespacedName, group))
+		return group
+	}
+	shard := func() *appsv1.MyStatefulset {
+		ms := &appsv1.MyStatefulset{}
+		require.NoError(t, c.Get(ctx, types.NamespacedName{Name: "shard-0", Namespace: "default"}, ms))
+		return ms
+	}
+
+	// 滚动过程中修改补丁，中止时仍恢复到第一次打补丁前的模板
+	group := reconcile()
+	assert.Equal(t, appsv1.GroupPhaseProgressing, group.Status.Phase)
+	group.Spec.TemplatePatch.Raw = []byte(`{"spec":{"containers":[{"name":"db","image":"postgres:17"}]}}`)
+	require.NoError(t, c.Update(ctx, group))
+	reconcile()
+	assert.Equal(t, "postgres:17", shard().Spec.Template.Spec.Containers[0].Image)
+
+	markRolledOut(t, c, "shard-0")
+	group = reconcile()
+	group.Spec.Abort = true
+	require.NoError(t, c.Update(ctx, group))
+	reconcile()
+	assert.Equal(t, "postgres:15", shard().Spec.Template.Spec.Containers[0].Image)
+
+	// 上一轮滚动完成后，新一轮滚动重新记录模板
+	group = reconcile()
+	group.Spec.Abort = false
+	group.Spec.TemplatePatch.Raw = []byte(shardImagePatch)
+	require.NoError(t, c.Update(ctx, group))
+	reconcile()
+	markRolledOut(t, c, "shard-0")
+	group = reconcile()
+	assert.Equal(t, appsv1.GroupPhaseCompleted, group.Status.Phase)
+	group.Spec.TemplatePatch.Raw = []byte(`{"spec":{"containers":[{"name":"db","image":"postgres:17"}]}}`)
+	require.NoError(t, c.Update(ctx, group))
+	reconcile()
+	assert.Equal(t, "postgres:17", shard().Spec.Template.Spec.Containers[0].Image)
+
+	markRolledOut(t, c, "shard-0")
+	group = reconcile()
+	group.Spec.Abort = true
+	require.NoError(t, c.Update(ctx, group))
+	reconcile()
+	assert.Equal(t, "postgres:16", shard().Spec.Template.Spec.Containers[0].Image)
+}
+
+func TestMemberRolledOut(t *testing.T) {
+	tests := []struct {
+		name      string
+		replicas  int32
+		partition *int32
+		updated   int32
+		want      bool
+	}{
+		{name: "all replicas updated", replicas: 3, updated: 3, want: true},
+		{name: "replicas left to update", replicas: 3, updated: 2, want: false},
+		{name: "replicas below partition stay on the old revision", replicas: 3, partition: pointer.Int32(2), updated: 1, want: true},
+		{name: "replicas above partition left to update", replicas: 3, partition: pointer.Int32(1), updated: 1, want: false},
+		{name: "partition covers all replicas", replicas: 3, partition: pointer.Int32(5), updated: 0, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			member := newShard("shard-0", "db")
+			member.Generation = 2
+			member.Spec.Replicas = pointer.Int32(tt.replicas)
+			member.Spec.UpdateStrategy = appsv1.UpdateStrategy{
+				Type:          appsv1.RollingUpdateStatefulSetStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateStatefulSetStrategy{Partition: tt.partition},
+			}
+			member.Status.ObservedGeneration = 2
+			member.Status.UpdatedReplicas = tt.updated
+			meta.SetStatusCondition(&member.Status.Conditions, metav1.Condition{
+				Type: appsv1.ConditionAvailable, Status: metav1.ConditionTrue, Reason: "MinimumReplicasAvailable",
+				ObservedGeneration: 2,
+			})
+			assert.Equal(t, tt.want, memberRolledOut(member))
+		})
+	}
+}
+
+func TestMyStatefulsetGroupReconciler_groupsForMember(t *testing.T) {
+	s := runtime.NewScheme()
+	_ = appsv1.AddToScheme(s)
+
+	c := fake.NewClientBuilder().WithScheme(s).WithObjects(newShardGroup(shardImagePatch, 1)).Build()
+	r := &MyStatefulsetGroupReconciler{Client: c, Scheme: s}
+
+	requests := r.groupsForMember(newShard("shard-0", "db"))
+	require.Len(t, requests, 1)
+	assert.Equal(t, "shards", requests[0].Name)
+	assert.Empty(t, r.groupsForMember(newShard("cache-0", "cache")))
+}
+
+func TestPatchTemplate(t *testing.T) {
+	template := &newShard("shard-0", "db").Spec.Template
+	patched, err := patchTemplate(template, []byte(
+		`{"metadata":{"annotations":{"rollout":"2"}},"spec":{"containers":[{"name":"db","image":"postgres:16"}]}}`))
+	require.NoError(t, err)
+
+	// 容器按名称合并，未出现在补丁中的容器和字段保持不变
+	require.Len(t, patched.Spec.Containers, 2)
+	assert.Equal(t, "postgres:16", patched.Spec.Containers[0].Image)
+	assert.Equal(t, "exporter:1", patched.Spec.Containers[1].Image)
+	assert.Equal(t, "2", patched.Annotations["rollout"])
+	assert.Equal(t, "shard-0", patched.Labels["app"])
+	assert.Equal(t, "postgres:15", template.Spec.Containers[0].Image)
+}
@@ -32,6 +32,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	appsv1 "github.com/bryant-rh/my-statefulset/api/v1"
+	appsv2 "github.com/bryant-rh/my-statefulset/api/v2"
 	"github.com/bryant-rh/my-statefulset/controllers"
 	//+kubebuilder:scaffold:imports
 )
@@ -45,6 +46,7 @@ func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
 	utilruntime.Must(appsv1.AddToScheme(scheme))
+	utilruntime.Must(appsv2.AddToScheme(scheme))
 	//+kubebuilder:scaffold:scheme
 }
 
@@ -90,10 +92,27 @@ func main() {
 	}
 
 	if err = (&controllers.MyStatefulsetReconciler{
+		Client:  mgr.GetClient(),
+		Scheme:  mgr.GetScheme(),
+		Metrics: controllers.NewPodMetricsClient(mgr.GetAPIReader()),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "MyStatefulset")
+		os.Exit(1)
+	}
+
+	if err = (&controllers.MyStatefulsetCloneReconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
 	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "MyStatefulset")
+		setupLog.Error(err, "unable to create controller", "controller", "MyStatefulsetClone")
+		os.Exit(1)
+	}
+
+	if err = (&controllers.MyStatefulsetGroupReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "MyStatefulsetGroup")
 		os.Exit(1)
 	}
 
@@ -102,6 +121,15 @@ func main() {
 			setupLog.Error(err, "unable to create webhook", "webhook", "MyStatefulset")
 			os.Exit(1)
 		}
+		if err = (&appsv1.MyStatefulsetPolicy{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "MyStatefulsetPolicy")
+			os.Exit(1)
+		}
+		// v2 是存储版本，这里注册 v1 <-> v2 的转换 webhook
+		if err = (&appsv2.MyStatefulset{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create conversion webhook", "webhook", "MyStatefulset")
+			os.Exit(1)
+		}
 	}
 	//+kubebuilder:scaffold:builder
 